@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -84,6 +85,7 @@ func main() {
 	// Start background services
 	log.Println("Starting background services...")
 	go container.NotificationQueue.Start()
+	go container.OutboxRelay.Start()
 	go container.BackgroundJobScheduler.Start()
 	log.Println("Background services started")
 
@@ -113,6 +115,7 @@ func main() {
 
 	// Graceful shutdown of background services
 	log.Println("Stopping background services...")
+	container.OutboxRelay.Stop() // Before the queue: handlers enqueue notifications
 	container.NotificationQueue.Stop()
 	container.BackgroundJobScheduler.Stop()
 	log.Println("Background services stopped")
@@ -158,9 +161,18 @@ func initDatabase() (*gorm.DB, error) {
 }
 
 func runMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&entities.User{},
 		&entities.RefreshToken{},
+		&entities.Session{},
+		&entities.PasswordResetToken{},
+		&entities.MFARecoveryCode{},
+		&entities.UserIdentity{},
+		&entities.AccountErasureRequest{},
+		&entities.SecurityEvent{},
+		&entities.AuditLog{},
+		&entities.APIKey{},
+		&entities.Operator{},
 		&entities.Bus{},
 		&entities.Route{},
 		&entities.Trip{},
@@ -171,24 +183,48 @@ func runMigrations(db *gorm.DB) error {
 		&entities.Passenger{},
 		&entities.SeatReservation{},
 		&entities.Ticket{},
+		&entities.BookingAccessCode{},
 		// Payment entities
 		&entities.Payment{},
 		&entities.PaymentWebhookLog{},
+		&entities.PaymentReconciliation{},
+		// Transactional outbox
+		&entities.OutboxEvent{},
 		// Notification entities
 		&entities.Notification{},
 		&entities.NotificationPreference{},
+		&entities.NotificationTemplate{},
 		// Analytics entities
 		&entities.BookingAnalytics{},
 		&entities.RouteAnalytics{},
+		&entities.TripAnalytics{},
+		&entities.FunnelEvent{},
+		&entities.AnalyticsBackfill{},
+		&entities.OperatorBookingAnalytics{},
 		// Review entity
 		&entities.Review{},
-	)
+	); err != nil {
+		return err
+	}
+
+	// At most one analytics backfill may be pending or running at a time
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_analytics_backfills_active
+		ON analytics_backfills ((true)) WHERE status IN ('pending', 'running')`).Error
 }
 
 type Container struct {
 	// Repositories
 	UserRepo              repositories.UserRepository
 	RefreshTokenRepo      repositories.RefreshTokenRepository
+	SessionRepo           repositories.SessionRepository
+	PasswordResetRepo     repositories.PasswordResetTokenRepository
+	MFARecoveryRepo       repositories.MFARecoveryCodeRepository
+	SecurityEventRepo     repositories.SecurityEventRepository
+	OperatorRepo          repositories.OperatorRepository
+	UserIdentityRepo      repositories.UserIdentityRepository
+	AccountErasureRepo    repositories.AccountErasureRepository
+	AuditLogRepo          repositories.AuditLogRepository
+	APIKeyRepo            repositories.APIKeyRepository
 	BusRepo               repositories.BusRepository
 	RouteRepo             repositories.RouteRepository
 	TripRepo              repositories.TripRepository
@@ -198,20 +234,30 @@ type Container struct {
 	PassengerRepo         repositories.PassengerRepository
 	SeatReservationRepo   repositories.SeatReservationRepository
 	TicketRepo            repositories.TicketRepository
+	BookingAccessCodeRepo repositories.BookingAccessCodeRepository
 	PaymentRepo           repositories.PaymentRepository
 	PaymentWebhookLogRepo repositories.PaymentWebhookLogRepository
+	ReconciliationRepo    repositories.PaymentReconciliationRepository
 	NotificationRepo      repositories.NotificationRepository
 	NotificationPrefRepo  repositories.NotificationPreferenceRepository
+	NotificationTmplRepo  repositories.NotificationTemplateRepository
 	BookingAnalyticsRepo  repositories.BookingAnalyticsRepository
 	RouteAnalyticsRepo    repositories.RouteAnalyticsRepository
+	TripAnalyticsRepo     repositories.TripAnalyticsRepository
+	FunnelEventRepo       repositories.FunnelEventRepository
+	AnalyticsBackfillRepo repositories.AnalyticsBackfillRepository
 	ReviewRepo            repositories.ReviewRepository
+	OutboxRepo            repositories.OutboxRepository
 
 	// Services
 	CacheService            *services.CacheService
+	LoginThrottle           *services.LoginThrottle
+	RateLimiter             *services.RateLimiter
 	PaymentProvider         services.PaymentProvider
 	EmailService            services.EmailProvider
 	NotificationTemplateEng *services.NotificationTemplateEngine
 	NotificationQueue       *services.NotificationQueue
+	OutboxRelay             *services.OutboxRelay
 	BackgroundJobScheduler  *services.BackgroundJobScheduler
 	ChatbotService          *services.ChatbotService
 
@@ -223,16 +269,31 @@ type Container struct {
 	BookingUsecase   *usecases.BookingUsecase
 	PaymentUsecase   *usecases.PaymentUsecase
 	AnalyticsUsecase *usecases.AnalyticsUsecase
+	BackfillUsecase  *usecases.AnalyticsBackfillUsecase
 	ReviewUsecase    *usecases.ReviewUsecase
+	TemplateUsecase  *usecases.NotificationTemplateUsecase
+	ReconcileUsecase *usecases.PaymentReconciliationUsecase
+	AuditUsecase     *usecases.AuditLogUsecase
+	APIKeyUsecase    *usecases.APIKeyUsecase
+	PrivacyUsecase   *usecases.PrivacyUsecase
 
 	// Configuration
-	JWTSecret string
+	JWTKeys *services.JWTKeySet
 }
 
 func initDependencies(db *gorm.DB) *Container {
 	// Repositories
 	userRepo := postgres.NewUserRepository(db)
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	passwordResetRepo := postgres.NewPasswordResetTokenRepository(db)
+	mfaRecoveryRepo := postgres.NewMFARecoveryCodeRepository(db)
+	securityEventRepo := postgres.NewSecurityEventRepository(db)
+	operatorRepo := postgres.NewOperatorRepository(db)
+	userIdentityRepo := postgres.NewUserIdentityRepository(db)
+	accountErasureRepo := postgres.NewAccountErasureRepository(db)
+	auditLogRepo := postgres.NewAuditLogRepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
 	busRepo := postgres.NewBusRepository(db)
 	routeRepo := postgres.NewRouteRepository(db)
 	tripRepo := postgres.NewTripRepository(db)
@@ -242,13 +303,20 @@ func initDependencies(db *gorm.DB) *Container {
 	passengerRepo := postgres.NewPassengerRepository(db)
 	seatReservationRepo := postgres.NewSeatReservationRepository(db)
 	ticketRepo := postgres.NewTicketRepository(db)
+	bookingAccessCodeRepo := postgres.NewBookingAccessCodeRepository(db)
 	paymentRepo := postgres.NewPaymentRepository(db)
 	paymentWebhookLogRepo := postgres.NewPaymentWebhookLogRepository(db)
+	reconciliationRepo := postgres.NewPaymentReconciliationRepository(db)
 	notificationRepo := postgres.NewNotificationRepository(db)
 	notificationPrefRepo := postgres.NewNotificationPreferenceRepository(db)
+	notificationTmplRepo := postgres.NewNotificationTemplateRepository(db)
 	bookingAnalyticsRepo := postgres.NewBookingAnalyticsRepository(db)
 	routeAnalyticsRepo := postgres.NewRouteAnalyticsRepository(db)
+	tripAnalyticsRepo := postgres.NewTripAnalyticsRepository(db)
+	funnelEventRepo := postgres.NewFunnelEventRepository(db)
+	analyticsBackfillRepo := postgres.NewAnalyticsBackfillRepository(db)
 	reviewRepo := postgres.NewReviewRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
 
 	// Initialize Cache Service
 	cacheService, err := services.NewCacheService()
@@ -260,7 +328,16 @@ func initDependencies(db *gorm.DB) *Container {
 	}
 
 	// Configuration
-	jwtSecret := getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production")
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		if getEnv("ENV", "development") == "production" {
+			log.Fatal("JWT_SECRET must be set in production")
+		}
+		log.Println("Warning: JWT_SECRET is not set, using an insecure development secret")
+		jwtSecret = "your-super-secret-jwt-key-change-this-in-production"
+	}
+	jwtKeys := loadJWTKeys(jwtSecret)
+	mfaKeys := loadMFAKeys()
 
 	accessTokenExpiry, err := time.ParseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m"))
 	if err != nil {
@@ -283,7 +360,7 @@ func initDependencies(db *gorm.DB) *Container {
 	emailService := getEmailProvider()
 
 	// Notification services
-	notificationTemplateEng := services.NewNotificationTemplateEngine(emailService)
+	notificationTemplateEng := services.NewNotificationTemplateEngine(emailService, notificationTmplRepo)
 	notificationQueue := services.NewNotificationQueue(
 		3,   // workers
 		100, // queue size
@@ -292,12 +369,18 @@ func initDependencies(db *gorm.DB) *Container {
 		notificationTemplateEng,
 	)
 
+	// Failed login counters (Redis when the cache is enabled)
+	loginThrottle := services.NewLoginThrottle(cacheService)
+	rateLimiter := services.NewRateLimiter(cacheService)
+
 	// Usecases
-	authUsecase := usecases.NewAuthUsecase(userRepo, refreshTokenRepo, jwtSecret, accessTokenExpiry, refreshTokenExpiry)
-	tripUsecase := usecases.NewTripUsecase(tripRepo, busRepo, routeRepo, cacheService)
+	authUsecase := usecases.NewAuthUsecase(userRepo, refreshTokenRepo, sessionRepo, passwordResetRepo, mfaRecoveryRepo, securityEventRepo, operatorRepo, userIdentityRepo, jwtSecret, jwtKeys, mfaKeys, accessTokenExpiry, refreshTokenExpiry, emailService, loginThrottle)
+	verificationPolicy := usecases.NewEmailVerificationPolicy(userRepo, os.Getenv("EMAIL_VERIFICATION_REQUIRED_FOR"))
+	funnelTracker := usecases.NewFunnelTracker(funnelEventRepo, tripRepo)
+	tripUsecase := usecases.NewTripUsecase(tripRepo, busRepo, routeRepo, cacheService, funnelTracker)
 	routeStopUsecase := usecases.NewRouteStopUsecase(routeStopRepo, routeRepo)
 	seatMapUsecase := usecases.NewSeatMapUsecase(seatMapRepo, busRepo, cacheService)
-	bookingUsecase := usecases.NewBookingUsecase(bookingRepo, passengerRepo, seatReservationRepo, ticketRepo, tripRepo, seatMapRepo, notificationRepo)
+	bookingUsecase := usecases.NewBookingUsecase(bookingRepo, passengerRepo, seatReservationRepo, ticketRepo, tripRepo, seatMapRepo, notificationRepo, userRepo, bookingAccessCodeRepo, emailService, notificationTemplateEng, verificationPolicy, jwtSecret, funnelTracker)
 	paymentUsecase := usecases.NewPaymentUsecase(
 		paymentRepo,
 		paymentWebhookLogRepo,
@@ -311,14 +394,19 @@ func initDependencies(db *gorm.DB) *Container {
 		tripRepo,
 		services.NewTicketService(),
 		emailService,
+		verificationPolicy,
+		funnelTracker,
 	)
 	analyticsUsecase := usecases.NewAnalyticsUsecase(
 		bookingRepo,
 		bookingAnalyticsRepo,
 		routeAnalyticsRepo,
+		tripAnalyticsRepo,
+		funnelEventRepo,
 		tripRepo,
 		routeRepo,
 		cacheService,
+		emailService,
 	)
 
 	reviewUsecase := usecases.NewReviewUsecase(
@@ -327,6 +415,27 @@ func initDependencies(db *gorm.DB) *Container {
 		tripRepo,
 	)
 
+	templateUsecase := usecases.NewNotificationTemplateUsecase(notificationTmplRepo, notificationTemplateEng)
+	reconcileUsecase := usecases.NewPaymentReconciliationUsecase(paymentRepo, bookingRepo, reconciliationRepo, paymentProvider, paymentUsecase)
+	auditUsecase := usecases.NewAuditLogUsecase(auditLogRepo)
+	apiKeyUsecase := usecases.NewAPIKeyUsecase(apiKeyRepo, rateLimiter)
+	privacyUsecase := usecases.NewPrivacyUsecase(
+		userRepo,
+		userIdentityRepo,
+		refreshTokenRepo,
+		accountErasureRepo,
+		bookingRepo,
+		paymentRepo,
+		reviewRepo,
+		notificationRepo,
+		notificationPrefRepo,
+		emailService,
+	)
+
+	// Outbox relay delivers booking and payment side effects committed with their state changes
+	outboxRelay := services.NewOutboxRelay(outboxRepo)
+	usecases.NewOutboxEventHandlers(bookingUsecase, paymentUsecase, analyticsUsecase).Register(outboxRelay)
+
 	// Chatbot service
 	chatbotService, err := services.NewChatbotService()
 	if err != nil {
@@ -342,15 +451,55 @@ func initDependencies(db *gorm.DB) *Container {
 		notificationPrefRepo,
 		bookingAnalyticsRepo,
 		routeAnalyticsRepo,
+		tripAnalyticsRepo,
 		tripRepo,
 		seatReservationRepo,
 		notificationQueue,
 		notificationTemplateEng,
 	)
+	backfillUsecase := usecases.NewAnalyticsBackfillUsecase(analyticsBackfillRepo, backgroundJobs, analyticsUsecase)
+	backgroundJobs.RegisterPeriodicJob("ResumeAnalyticsBackfills", 5*time.Minute, func() error {
+		return backfillUsecase.ResumeStalled(context.Background())
+	})
+	backgroundJobs.RegisterPeriodicJob("RetryPaymentWebhooks", 2*time.Minute, func() error {
+		return paymentUsecase.RetryPendingWebhooks(context.Background())
+	})
+	backgroundJobs.RegisterPeriodicJob("ReconcilePendingPayments", 15*time.Minute, func() error {
+		_, err := reconcileUsecase.ReconcilePendingPayments(context.Background())
+		return err
+	})
+	backgroundJobs.RegisterPeriodicJob("ProcessAccountErasures", 10*time.Minute, func() error {
+		return privacyUsecase.ProcessConfirmedErasures(context.Background())
+	})
+	backgroundJobs.RegisterDailyJob("CleanupPasswordResetTokens", 3, 30, func() error {
+		return passwordResetRepo.DeleteExpired(context.Background())
+	})
+	backgroundJobs.RegisterDailyJob("CleanupBookingAccessCodes", 3, 45, func() error {
+		return bookingAccessCodeRepo.DeleteExpired(context.Background())
+	})
+	backgroundJobs.RegisterDailyJob("CleanupFunnelEvents", 4, 0, func() error {
+		return funnelTracker.CleanupExpired(context.Background())
+	})
+	backgroundJobs.RegisterDailyJob("SendWeeklyAnalyticsReport", 7, 0, func() error {
+		// Runs after the 1 AM aggregation, so Sunday's numbers are in
+		if time.Now().Weekday() != time.Monday {
+			return nil
+		}
+		return analyticsUsecase.SendWeeklySummary(context.Background())
+	})
 
 	return &Container{
 		UserRepo:                userRepo,
 		RefreshTokenRepo:        refreshTokenRepo,
+		SessionRepo:             sessionRepo,
+		PasswordResetRepo:       passwordResetRepo,
+		MFARecoveryRepo:         mfaRecoveryRepo,
+		SecurityEventRepo:       securityEventRepo,
+		OperatorRepo:            operatorRepo,
+		UserIdentityRepo:        userIdentityRepo,
+		AccountErasureRepo:      accountErasureRepo,
+		AuditLogRepo:            auditLogRepo,
+		APIKeyRepo:              apiKeyRepo,
 		BusRepo:                 busRepo,
 		RouteRepo:               routeRepo,
 		TripRepo:                tripRepo,
@@ -360,18 +509,28 @@ func initDependencies(db *gorm.DB) *Container {
 		PassengerRepo:           passengerRepo,
 		SeatReservationRepo:     seatReservationRepo,
 		TicketRepo:              ticketRepo,
+		BookingAccessCodeRepo:   bookingAccessCodeRepo,
 		PaymentRepo:             paymentRepo,
 		PaymentWebhookLogRepo:   paymentWebhookLogRepo,
+		ReconciliationRepo:      reconciliationRepo,
 		NotificationRepo:        notificationRepo,
 		NotificationPrefRepo:    notificationPrefRepo,
+		NotificationTmplRepo:    notificationTmplRepo,
 		BookingAnalyticsRepo:    bookingAnalyticsRepo,
 		RouteAnalyticsRepo:      routeAnalyticsRepo,
+		TripAnalyticsRepo:       tripAnalyticsRepo,
+		FunnelEventRepo:         funnelEventRepo,
+		AnalyticsBackfillRepo:   analyticsBackfillRepo,
 		ReviewRepo:              reviewRepo,
+		OutboxRepo:              outboxRepo,
 		CacheService:            cacheService,
+		LoginThrottle:           loginThrottle,
+		RateLimiter:             rateLimiter,
 		PaymentProvider:         paymentProvider,
 		EmailService:            emailService,
 		NotificationTemplateEng: notificationTemplateEng,
 		NotificationQueue:       notificationQueue,
+		OutboxRelay:             outboxRelay,
 		BackgroundJobScheduler:  backgroundJobs,
 		ChatbotService:          chatbotService,
 		AuthUsecase:             authUsecase,
@@ -381,8 +540,14 @@ func initDependencies(db *gorm.DB) *Container {
 		BookingUsecase:          bookingUsecase,
 		PaymentUsecase:          paymentUsecase,
 		AnalyticsUsecase:        analyticsUsecase,
+		BackfillUsecase:         backfillUsecase,
 		ReviewUsecase:           reviewUsecase,
-		JWTSecret:               jwtSecret,
+		TemplateUsecase:         templateUsecase,
+		ReconcileUsecase:        reconcileUsecase,
+		AuditUsecase:            auditUsecase,
+		APIKeyUsecase:           apiKeyUsecase,
+		PrivacyUsecase:          privacyUsecase,
+		JWTKeys:                 jwtKeys,
 	}
 }
 
@@ -403,6 +568,48 @@ func getPaymentProvider() services.PaymentProvider {
 	)
 }
 
+// loadJWTKeys returns the keys that sign and verify access and refresh tokens
+// With JWT_KEYS_DIR set, tokens are signed with RS256 or EdDSA keys from that directory;
+// otherwise they fall back to HS256 with JWT_SECRET
+func loadJWTKeys(jwtSecret string) *services.JWTKeySet {
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		log.Println("JWT_KEYS_DIR is not set, signing tokens with HS256 (no public keys are published)")
+		return services.NewHMACKeySet(jwtSecret)
+	}
+
+	// Keep accepting HS256 tokens issued before the switch until they have expired
+	legacySecret := ""
+	if os.Getenv("JWT_ACCEPT_HS256") == "true" {
+		legacySecret = jwtSecret
+	}
+
+	keys, err := services.LoadJWTKeySet(keysDir, os.Getenv("JWT_SIGNING_KEY_ID"), legacySecret)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	return keys
+}
+
+// loadMFAKeys returns the keys that encrypt stored TOTP secrets
+// They are separate from JWT_SECRET so signing keys can rotate without users re-enrolling
+func loadMFAKeys() *services.MFAKeyring {
+	secret := os.Getenv("MFA_ENCRYPTION_KEY")
+	if secret == "" {
+		if getEnv("ENV", "development") == "production" {
+			log.Fatal("MFA_ENCRYPTION_KEY must be set in production")
+		}
+		log.Println("Warning: MFA_ENCRYPTION_KEY is not set, using an insecure development key")
+		secret = "insecure-development-mfa-encryption-key"
+	}
+
+	keys, err := services.NewMFAKeyring(getEnv("MFA_ENCRYPTION_KEY_ID", "1"), secret, os.Getenv("MFA_RETIRED_ENCRYPTION_KEYS"))
+	if err != nil {
+		log.Fatalf("Failed to load MFA encryption keys: %v", err)
+	}
+	return keys
+}
+
 // getEmailProvider returns the appropriate email provider based on environment
 func getEmailProvider() services.EmailProvider {
 	useSendGrid := os.Getenv("USE_SENDGRID") == "true"
@@ -427,116 +634,243 @@ func setupRouter(container *Container) *gin.Engine {
 	}
 
 	router := gin.New()
+	// Client IPs key the rate limiter and API key allowlists, so X-Forwarded-For is only
+	// honoured when the request comes from one of TRUSTED_PROXIES
+	if err := router.SetTrustedProxies(getTrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORS())
+	router.Use(middleware.FunnelSession())
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "service": "auth"})
 	})
 
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", handlers.NewJWKSHandler(container.JWTKeys).GetJWKS)
+
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Rate limit policies per route group (token buckets, overridable with RATE_LIMIT_<NAME>_BURST/_PER_MINUTE)
+	authLimit := middleware.RateLimit(container.RateLimiter, services.LoadRateLimitPolicy("auth", 10, 10))
+	searchLimit := middleware.RateLimit(container.RateLimiter, services.LoadRateLimitPolicy("search", 60, 60))
+	bookingLimit := middleware.RateLimit(container.RateLimiter, services.LoadRateLimitPolicy("booking", 20, 20))
+	chatbotLimit := middleware.RateLimit(container.RateLimiter, services.LoadRateLimitPolicy("chatbot", 5, 10))
+	userLimit := middleware.RateLimit(container.RateLimiter, services.LoadRateLimitPolicy("user", 120, 120))
+
 	// API v1
 	v1 := router.Group("/api/v1")
 	{
 		// Auth routes (public)
 		auth := v1.Group("/auth")
+		auth.Use(authLimit)
 		{
 			authHandler := handlers.NewAuthHandler(container.AuthUsecase)
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
+			auth.POST("/mfa/enroll", authHandler.StartMFAEnrollment)
+			auth.POST("/mfa/enroll/confirm", authHandler.ConfirmMFAEnrollment)
 			auth.GET("/google", authHandler.GoogleLogin)
 			auth.POST("/google/callback", authHandler.GoogleCallback)
 			auth.GET("/github", authHandler.GitHubLogin)
 			auth.GET("/github/callback", authHandler.GitHubCallback)
+			auth.POST("/erasure/confirm", handlers.NewPrivacyHandler(container.PrivacyUsecase).ConfirmErasure)
 		}
 
 		// Protected routes (require authentication)
 		authorized := v1.Group("")
-		authorized.Use(middleware.AuthMiddleware(container.JWTSecret))
+		authorized.Use(middleware.AuthMiddleware(container.JWTKeys, container.AuthUsecase), userLimit)
 		{
 			// User profile routes
 			profile := authorized.Group("/profile")
 			{
+				profileAuthHandler := handlers.NewAuthHandler(container.AuthUsecase)
+				profile.POST("/verify-email/resend", profileAuthHandler.ResendMyVerification)
+				profile.GET("/sessions", profileAuthHandler.ListSessions)
+				profile.DELETE("/sessions/:id", profileAuthHandler.RevokeSession)
+				profile.GET("/identities", profileAuthHandler.ListIdentities)
+				profile.POST("/identities/:provider", profileAuthHandler.LinkIdentity)
+				profile.DELETE("/identities/:provider", profileAuthHandler.UnlinkIdentity)
+				privacyHandler := handlers.NewPrivacyHandler(container.PrivacyUsecase)
+				profile.GET("/export", privacyHandler.ExportData)
+				profile.GET("/erasure", privacyHandler.GetErasureStatus)
+				profile.POST("/erasure", privacyHandler.RequestErasure)
+				profile.GET("/mfa", profileAuthHandler.GetMFAStatus)
+				profile.POST("/mfa/setup", profileAuthHandler.SetupMFA)
+				profile.POST("/mfa/enable", profileAuthHandler.EnableMFA)
+				profile.POST("/mfa/disable", profileAuthHandler.DisableMFA)
+				profile.POST("/mfa/recovery-codes", profileAuthHandler.RegenerateRecoveryCodes)
 				profile.GET("", func(c *gin.Context) {
 					userID, _ := c.Get("user_id")
 					email, _ := c.Get("user_email")
 					role, _ := c.Get("user_role")
+					roleName, _ := role.(string)
 					c.JSON(200, gin.H{
-						"user_id": userID,
-						"email":   email,
-						"role":    role,
+						"user_id":     userID,
+						"email":       email,
+						"role":        role,
+						"permissions": entities.Role(roleName).Permissions(),
 					})
 				})
 			}
 
-			// Admin routes (require admin role)
+			// Back-office routes, each guarded by the permission it needs
 			admin := authorized.Group("/admin")
-			admin.Use(middleware.RequireRole("admin"))
+			admin.Use(middleware.RequireStaff(), middleware.OperatorFilter())
 			{
-				adminHandler := handlers.NewAdminHandler(container.TripUsecase, container.BookingUsecase)
-				busHandler := handlers.NewBusHandler(container.BusRepo)
-				routeHandler := handlers.NewRouteHandler(container.RouteRepo)
-				routeStopHandler := handlers.NewRouteStopHandler(container.RouteStopUsecase)
-				seatMapHandler := handlers.NewSeatMapHandler(container.SeatMapUsecase)
+				adminHandler := handlers.NewAdminHandler(container.TripUsecase, container.BookingUsecase, container.AuditUsecase)
+				busHandler := handlers.NewBusHandler(container.BusRepo, container.AuditUsecase)
+				routeHandler := handlers.NewRouteHandler(container.RouteRepo, container.AuditUsecase)
+				routeStopHandler := handlers.NewRouteStopHandler(container.RouteStopUsecase, container.AuditUsecase)
+				seatMapHandler := handlers.NewSeatMapHandler(container.SeatMapUsecase, container.AuditUsecase)
+				operatorHandler := handlers.NewOperatorHandler(container.OperatorRepo, container.AuditUsecase)
+
+				canViewTrips := middleware.RequirePermission(entities.PermTripsView)
+				canManageTrips := middleware.RequirePermission(entities.PermTripsManage)
+				canManageFleet := middleware.RequirePermission(entities.PermFleetManage)
+				canManageRoutes := middleware.RequirePermission(entities.PermRoutesManage)
+				canViewPassengers := middleware.RequirePermission(entities.PermPassengersView)
+				canCheckIn := middleware.RequirePermission(entities.PermPassengersCheckIn)
+				canViewPayments := middleware.RequirePermission(entities.PermPaymentsView)
+				canManagePayments := middleware.RequirePermission(entities.PermPaymentsManage)
+				canManageNotifications := middleware.RequirePermission(entities.PermNotificationsManage)
+				canViewUsers := middleware.RequirePermission(entities.PermUsersView)
+				canManageUsers := middleware.RequirePermission(entities.PermUsersManage)
+				canManageRoles := middleware.RequirePermission(entities.PermRolesManage)
+				canManageOperators := middleware.RequirePermission(entities.PermOperatorsManage)
+				canViewAudit := middleware.RequirePermission(entities.PermAuditView)
+				canManageAPIKeys := middleware.RequirePermission(entities.PermAPIKeysManage)
+				canManageAnalytics := middleware.RequirePermission(entities.PermAnalyticsManage)
+				platformOnly := middleware.RequirePlatformStaff()
+
+				// Operator onboarding (platform staff only)
+				admin.GET("/operators", platformOnly, canManageOperators, operatorHandler.ListOperators)
+				admin.POST("/operators", platformOnly, canManageOperators, operatorHandler.CreateOperator)
+				admin.GET("/operators/:id", platformOnly, canManageOperators, operatorHandler.GetOperator)
+				admin.PUT("/operators/:id", platformOnly, canManageOperators, operatorHandler.UpdateOperator)
+				admin.DELETE("/operators/:id", platformOnly, canManageOperators, operatorHandler.DeleteOperator)
 
 				// Bus management
-				admin.GET("/buses", busHandler.GetAllBuses)
-				admin.POST("/buses", busHandler.CreateBus)
-				admin.GET("/buses/:id", busHandler.GetBusByID)
-				admin.PUT("/buses/:id", busHandler.UpdateBus)
-				admin.DELETE("/buses/:id", busHandler.DeleteBus)
-				admin.POST("/buses/assign-seat-map", seatMapHandler.AssignSeatMapToBus)
+				admin.GET("/buses", canManageFleet, busHandler.GetAllBuses)
+				admin.POST("/buses", canManageFleet, busHandler.CreateBus)
+				admin.GET("/buses/:id", canManageFleet, busHandler.GetBusByID)
+				admin.PUT("/buses/:id", canManageFleet, busHandler.UpdateBus)
+				admin.DELETE("/buses/:id", canManageFleet, busHandler.DeleteBus)
+				admin.POST("/buses/assign-seat-map", canManageFleet, seatMapHandler.AssignSeatMapToBus)
 
 				// Route management
-				admin.GET("/routes", routeHandler.GetAllRoutes)
-				admin.POST("/routes", routeHandler.CreateRoute)
-				admin.GET("/routes/:id", routeHandler.GetRouteByID)
-				admin.PUT("/routes/:id", routeHandler.UpdateRoute)
-				admin.DELETE("/routes/:id", routeHandler.DeleteRoute)
-				admin.POST("/routes/:id/stops", routeStopHandler.CreateStop)
-				admin.PUT("/routes/:id/stops/:stopId", routeStopHandler.UpdateStop)
-				admin.DELETE("/routes/:id/stops/:stopId", routeStopHandler.DeleteStop)
+				admin.GET("/routes", canManageRoutes, routeHandler.GetAllRoutes)
+				admin.POST("/routes", canManageRoutes, routeHandler.CreateRoute)
+				admin.GET("/routes/:id", canManageRoutes, routeHandler.GetRouteByID)
+				admin.PUT("/routes/:id", canManageRoutes, routeHandler.UpdateRoute)
+				admin.DELETE("/routes/:id", canManageRoutes, routeHandler.DeleteRoute)
+				admin.POST("/routes/:id/stops", canManageRoutes, routeStopHandler.CreateStop)
+				admin.PUT("/routes/:id/stops/:stopId", canManageRoutes, routeStopHandler.UpdateStop)
+				admin.DELETE("/routes/:id/stops/:stopId", canManageRoutes, routeStopHandler.DeleteStop)
 
 				// Trip management
-				admin.GET("/trips", adminHandler.GetAllTrips)
-				admin.POST("/trips", adminHandler.CreateTrip)
-				admin.PUT("/trips/:id", adminHandler.UpdateTrip)
-				admin.DELETE("/trips/:id", adminHandler.DeleteTrip)
-				admin.POST("/trips/assign-bus", adminHandler.AssignBus)
+				admin.GET("/trips", canViewTrips, adminHandler.GetAllTrips)
+				admin.POST("/trips", canManageTrips, adminHandler.CreateTrip)
+				admin.PUT("/trips/:id", canManageTrips, adminHandler.UpdateTrip)
+				admin.DELETE("/trips/:id", canManageTrips, adminHandler.DeleteTrip)
+				admin.POST("/trips/assign-bus", canManageTrips, adminHandler.AssignBus)
 
 				// Seat map management
-				admin.GET("/seat-maps", seatMapHandler.GetAllSeatMaps)
-				admin.GET("/seat-maps/configs", seatMapHandler.GetSeatTypeConfigs)
-				admin.POST("/seat-maps", seatMapHandler.CreateSeatMap)
-				admin.GET("/seat-maps/:id", seatMapHandler.GetSeatMap)
-				admin.PUT("/seat-maps/:id", seatMapHandler.UpdateSeatMap)
-				admin.DELETE("/seat-maps/:id", seatMapHandler.DeleteSeatMap)
-				admin.PUT("/seat-maps/:id/seats", seatMapHandler.BulkUpdateSeats)
-				admin.POST("/seat-maps/:id/regenerate", seatMapHandler.RegenerateSeatLayout)
-
-				// Analytics routes (admin only)
+				admin.GET("/seat-maps", canManageFleet, seatMapHandler.GetAllSeatMaps)
+				admin.GET("/seat-maps/configs", canManageFleet, seatMapHandler.GetSeatTypeConfigs)
+				admin.POST("/seat-maps", canManageFleet, seatMapHandler.CreateSeatMap)
+				admin.GET("/seat-maps/:id", canManageFleet, seatMapHandler.GetSeatMap)
+				admin.PUT("/seat-maps/:id", canManageFleet, seatMapHandler.UpdateSeatMap)
+				admin.DELETE("/seat-maps/:id", canManageFleet, seatMapHandler.DeleteSeatMap)
+				admin.PUT("/seat-maps/:id/seats", canManageFleet, seatMapHandler.BulkUpdateSeats)
+				admin.POST("/seat-maps/:id/regenerate", canManageFleet, seatMapHandler.RegenerateSeatLayout)
+
+				// Analytics routes
 				analyticsHandler := handlers.NewAnalyticsHandler(container.AnalyticsUsecase)
-				handlers.RegisterAnalyticsRoutes(admin, analyticsHandler, middleware.RequireRole("admin"))
-
-				// User management (admin only)
-				userMgmtHandler := handlers.NewUserManagementHandler(container.AuthUsecase)
-				admin.GET("/users", userMgmtHandler.ListAdmins)
-				admin.POST("/users", userMgmtHandler.CreateAdmin)
-				admin.GET("/users/:id", userMgmtHandler.GetUser)
-				admin.PUT("/users/:id", userMgmtHandler.UpdateUser)
-				admin.DELETE("/users/:id", userMgmtHandler.DeactivateUser)
-
-				// Trip operations (admin only)
-				tripOpHandler := handlers.NewTripHandler(container.TripUsecase)
-				admin.PUT("/trips/:id/status", tripOpHandler.UpdateTripStatus)
-				admin.GET("/trips/:id/passengers", adminHandler.GetTripPassengers)
-				admin.POST("/trips/:id/passengers/:passengerId/check-in", adminHandler.CheckInPassenger)
+				handlers.RegisterAnalyticsRoutes(admin, analyticsHandler, middleware.RequirePermission(entities.PermAnalyticsView))
+
+				// Analytics backfills (platform staff only)
+				backfillHandler := handlers.NewAnalyticsBackfillHandler(container.BackfillUsecase)
+				admin.POST("/analytics/backfills", platformOnly, canManageAnalytics, backfillHandler.StartBackfill)
+				admin.GET("/analytics/backfills", platformOnly, canManageAnalytics, backfillHandler.ListBackfills)
+				admin.GET("/analytics/backfills/:id", platformOnly, canManageAnalytics, backfillHandler.GetBackfill)
+				admin.POST("/analytics/backfills/:id/cancel", platformOnly, canManageAnalytics, backfillHandler.CancelBackfill)
+				admin.GET("/analytics/missing-dates", platformOnly, canManageAnalytics, backfillHandler.GetMissingDates)
+
+				// User management
+				userMgmtHandler := handlers.NewUserManagementHandler(container.AuthUsecase, container.AuditUsecase)
+				admin.GET("/users", canViewUsers, userMgmtHandler.ListAdmins)
+				admin.POST("/users", canManageUsers, canManageRoles, userMgmtHandler.CreateAdmin)
+				admin.GET("/users/:id", canViewUsers, userMgmtHandler.GetUser)
+				admin.PUT("/users/:id", canManageUsers, userMgmtHandler.UpdateUser)
+				admin.DELETE("/users/:id", canManageUsers, userMgmtHandler.DeactivateUser)
+				admin.GET("/users/:id/lockout", canViewUsers, userMgmtHandler.GetLockoutStatus)
+				admin.POST("/users/:id/unlock", canManageUsers, userMgmtHandler.UnlockUser)
+				admin.GET("/security-events", platformOnly, canViewUsers, userMgmtHandler.ListSecurityEvents)
+
+				// Back-office audit log (operator admins only see their own operator's entries)
+				auditLogHandler := handlers.NewAuditLogHandler(container.AuditUsecase)
+				admin.GET("/audit-logs", canViewAudit, auditLogHandler.ListAuditLogs)
+
+				// Partner API keys (platform staff only)
+				apiKeyHandler := handlers.NewAPIKeyHandler(container.APIKeyUsecase, container.AuditUsecase)
+				admin.GET("/api-keys", platformOnly, canManageAPIKeys, apiKeyHandler.ListKeys)
+				admin.POST("/api-keys", platformOnly, canManageAPIKeys, apiKeyHandler.IssueKey)
+				admin.GET("/api-keys/:id", platformOnly, canManageAPIKeys, apiKeyHandler.GetKey)
+				admin.POST("/api-keys/:id/rotate", platformOnly, canManageAPIKeys, apiKeyHandler.RotateKey)
+				admin.DELETE("/api-keys/:id", platformOnly, canManageAPIKeys, apiKeyHandler.RevokeKey)
+
+				// Role assignment
+				admin.GET("/roles", canViewUsers, userMgmtHandler.ListRoles)
+				admin.PUT("/users/:id/role", canManageRoles, userMgmtHandler.AssignRole)
+				admin.PUT("/users/:id/operator", platformOnly, canManageRoles, canManageOperators, userMgmtHandler.AssignOperator)
+
+				// Trip operations
+				tripOpHandler := handlers.NewTripHandler(container.TripUsecase, container.AuditUsecase)
+				admin.PUT("/trips/:id/status", canManageTrips, tripOpHandler.UpdateTripStatus)
+				admin.GET("/trips/:id/passengers", canViewPassengers, adminHandler.GetTripPassengers)
+				admin.POST("/trips/:id/passengers/:passengerId/check-in", canCheckIn, adminHandler.CheckInPassenger)
+
+				// Notification dead-letter queue
+				deadLetterHandler := handlers.NewDeadLetterHandler(container.NotificationQueue)
+				admin.GET("/notifications/dead-letter", platformOnly, canManageNotifications, deadLetterHandler.ListDeadLetters)
+				admin.GET("/notifications/dead-letter/:id", platformOnly, canManageNotifications, deadLetterHandler.GetDeadLetter)
+				admin.POST("/notifications/dead-letter/:id/retry", platformOnly, canManageNotifications, deadLetterHandler.RetryDeadLetter)
+				admin.DELETE("/notifications/dead-letter/:id", platformOnly, canManageNotifications, deadLetterHandler.DiscardDeadLetter)
+
+				// Payment webhook logs
+				webhookLogHandler := handlers.NewWebhookLogHandler(container.PaymentUsecase)
+				admin.GET("/payments/webhooks", platformOnly, canViewPayments, webhookLogHandler.ListWebhookLogs)
+				admin.GET("/payments/webhooks/:id", platformOnly, canViewPayments, webhookLogHandler.GetWebhookLog)
+				admin.POST("/payments/webhooks/:id/replay", platformOnly, canManagePayments, webhookLogHandler.ReplayWebhookLog)
+
+				// Payment reconciliation against the provider
+				reconciliationHandler := handlers.NewPaymentReconciliationHandler(container.ReconcileUsecase)
+				admin.GET("/payments/reconciliation", platformOnly, canViewPayments, reconciliationHandler.GetReport)
+				admin.POST("/payments/reconciliation/run", platformOnly, canManagePayments, reconciliationHandler.RunReconciliation)
+
+				// Notification templates
+				templateHandler := handlers.NewNotificationTemplateHandler(container.TemplateUsecase)
+				admin.GET("/notification-templates", platformOnly, canManageNotifications, templateHandler.ListTemplates)
+				admin.POST("/notification-templates", platformOnly, canManageNotifications, templateHandler.CreateTemplate)
+				admin.POST("/notification-templates/preview", platformOnly, canManageNotifications, templateHandler.PreviewTemplate)
+				admin.GET("/notification-templates/:id", platformOnly, canManageNotifications, templateHandler.GetTemplate)
+				admin.PUT("/notification-templates/:id", platformOnly, canManageNotifications, templateHandler.UpdateTemplate)
+				admin.DELETE("/notification-templates/:id", platformOnly, canManageNotifications, templateHandler.DeleteTemplate)
+				admin.GET("/notification-templates/:id/versions", platformOnly, canManageNotifications, templateHandler.GetTemplateVersions)
+				admin.POST("/notification-templates/:id/activate", platformOnly, canManageNotifications, templateHandler.ActivateTemplate)
+				admin.POST("/notification-templates/:id/preview", platformOnly, canManageNotifications, templateHandler.PreviewStoredTemplate)
 			}
 
 			// Protected review routes (authenticated users)
@@ -549,19 +883,21 @@ func setupRouter(container *Container) *gin.Engine {
 
 		// Payment routes (uses RegisterPaymentRoutes helper which handles auth internally)
 		paymentHandler := handlers.NewPaymentHandler(container.PaymentUsecase)
-		handlers.RegisterPaymentRoutes(v1, paymentHandler, middleware.AuthMiddleware(container.JWTSecret))
+		handlers.RegisterPaymentRoutes(v1, paymentHandler, middleware.AuthMiddleware(container.JWTKeys, container.AuthUsecase))
 
 		// Public route endpoints (no auth required)
 		routes := v1.Group("/routes")
+		routes.Use(searchLimit)
 		{
-			routeStopHandler := handlers.NewRouteStopHandler(container.RouteStopUsecase)
+			routeStopHandler := handlers.NewRouteStopHandler(container.RouteStopUsecase, container.AuditUsecase)
 			routes.GET("/:id", routeStopHandler.GetRouteWithStops)
 		}
 
 		// Public trip search
 		trips := v1.Group("/trips")
+		trips.Use(searchLimit)
 		{
-			tripHandler := handlers.NewTripHandler(container.TripUsecase)
+			tripHandler := handlers.NewTripHandler(container.TripUsecase, container.AuditUsecase)
 			reviewHandler := handlers.NewReviewHandler(container.ReviewUsecase)
 
 			trips.GET("/search", tripHandler.SearchTrips)
@@ -577,31 +913,55 @@ func setupRouter(container *Container) *gin.Engine {
 			trips.GET("/:id/seats/status", bookingHandler.GetSeatsWithStatus)
 		}
 
+		// Partner API for travel agencies and resellers, authenticated by X-API-Key
+		partner := v1.Group("/partner")
+		{
+			tripHandler := handlers.NewTripHandler(container.TripUsecase, container.AuditUsecase)
+			bookingHandler := handlers.NewBookingHandler(container.BookingUsecase)
+			canSearch := middleware.RequireAPIKey(container.APIKeyUsecase, entities.APIKeyScopeSearch)
+			canBook := middleware.RequireAPIKey(container.APIKeyUsecase, entities.APIKeyScopeBooking)
+
+			partner.GET("/trips/search", canSearch, tripHandler.SearchTrips)
+			partner.GET("/trips/:id", canSearch, tripHandler.GetTripByID)
+			partner.GET("/trips/:id/seats", canSearch, bookingHandler.GetSeatsWithStatus)
+			partner.POST("/bookings/reserve", canBook, bookingHandler.ReserveSeats)
+			partner.DELETE("/bookings/release", canBook, bookingHandler.ReleaseSeats)
+			partner.POST("/bookings", canBook, bookingHandler.CreateBooking)
+			partner.GET("/bookings/:reference", canBook, bookingHandler.GetPartnerBooking)
+		}
+
 		// Booking routes (public - supports guest checkout)
 		bookings := v1.Group("/bookings")
+		bookings.Use(bookingLimit)
 		{
 			bookingHandler := handlers.NewBookingHandler(container.BookingUsecase)
 			bookings.POST("/reserve", bookingHandler.ReserveSeats)
 			bookings.DELETE("/release", bookingHandler.ReleaseSeats)
 			bookings.POST("", bookingHandler.CreateBooking)
-			bookings.GET("/ref/:reference", bookingHandler.GetBookingByReference)
-			bookings.GET("/guest", bookingHandler.GetGuestBookings)
-			bookings.POST("/:id/confirm", bookingHandler.ConfirmBooking)
-			bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
-			bookings.GET("/:id/tickets/download", bookingHandler.DownloadBookingTickets)
-			bookings.POST("/:id/resend-tickets", bookingHandler.ResendTicketEmail)
-		}
+			bookings.POST("/guest/lookup", bookingHandler.RequestGuestAccess)
+			bookings.POST("/guest/verify", bookingHandler.VerifyGuestAccess)
 
-		// Ticket routes (public)
-		tickets := v1.Group("/tickets")
-		{
-			bookingHandler := handlers.NewBookingHandler(container.BookingUsecase)
-			tickets.GET("/:id/download", bookingHandler.DownloadTicket)
+			// Guest access (X-Booking-Token from /guest/verify or booking creation), each route needing its scope
+			guestBooking := bookings.Group("/guest/booking")
+			{
+				canView := middleware.RequireBookingAccess(container.BookingUsecase, usecases.BookingScopeView)
+				canDownload := middleware.RequireBookingAccess(container.BookingUsecase, usecases.BookingScopeDownload)
+				canEditPassengers := middleware.RequireBookingAccess(container.BookingUsecase, usecases.BookingScopePassengers)
+				canCancel := middleware.RequireBookingAccess(container.BookingUsecase, usecases.BookingScopeCancel)
+
+				guestBooking.GET("", canView, bookingHandler.GetGuestBooking)
+				guestBooking.GET("/tickets/download", canDownload, bookingHandler.DownloadGuestTickets)
+				guestBooking.GET("/tickets/:ticket_id/download", canDownload, bookingHandler.DownloadGuestTicket)
+				guestBooking.POST("/resend-tickets", canDownload, bookingHandler.ResendGuestTickets)
+				guestBooking.PUT("/passengers/:passenger_id", canEditPassengers, bookingHandler.UpdateGuestPassenger)
+				guestBooking.POST("/cancel", canCancel, bookingHandler.CancelGuestBooking)
+			}
 		}
 
 		// Chatbot routes (public)
 		if container.ChatbotService != nil && container.ChatbotService.IsEnabled() {
 			chatbot := v1.Group("/chatbot")
+			chatbot.Use(chatbotLimit)
 			{
 				chatbotHandler := handlers.NewChatbotHandler(container.ChatbotService)
 				chatbot.POST("/message", chatbotHandler.SendMessage)
@@ -614,16 +974,38 @@ func setupRouter(container *Container) *gin.Engine {
 		{
 			bookingHandler := handlers.NewBookingHandler(container.BookingUsecase)
 			authorizedBookings.GET("/my-bookings", bookingHandler.GetUserBookings)
+			authorizedBookings.GET("/claimable", bookingHandler.GetClaimableBookings)
+			authorizedBookings.POST("/claim", bookingLimit, bookingHandler.ClaimBookings)
+			authorizedBookings.POST("/claim/confirm", bookingLimit, bookingHandler.ConfirmClaim)
+
+			// Booking and ticket IDs only open the signed-in user's own bookings
+			authorizedBookings.POST("/:id/confirm", bookingLimit, bookingHandler.ConfirmBooking)
+			authorizedBookings.POST("/:id/cancel", bookingLimit, bookingHandler.CancelBooking)
+			authorizedBookings.GET("/:id/tickets/download", bookingLimit, bookingHandler.DownloadBookingTickets)
+			authorizedBookings.POST("/:id/resend-tickets", bookingLimit, bookingHandler.ResendTicketEmail)
+			authorized.GET("/tickets/:id/download", bookingLimit, bookingHandler.DownloadTicket)
 		}
 
 		// Notification routes (authenticated users)
 		notificationHandler := handlers.NewNotificationHandler(container.NotificationRepo)
-		handlers.RegisterNotificationRoutes(v1, notificationHandler, middleware.AuthMiddleware(container.JWTSecret))
+		handlers.RegisterNotificationRoutes(v1, notificationHandler, middleware.AuthMiddleware(container.JWTKeys, container.AuthUsecase))
 	}
 
 	return router
 }
 
+// getTrustedProxies reads the comma-separated IPs or CIDRs of the reverse proxies in front of the API
+// With none configured, the client IP is the connection's remote address
+func getTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
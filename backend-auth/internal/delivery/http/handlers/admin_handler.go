@@ -14,13 +14,15 @@ import (
 type AdminHandler struct {
 	tripUsecase    *usecases.TripUsecase
 	bookingUsecase *usecases.BookingUsecase
+	auditUsecase   *usecases.AuditLogUsecase
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(tripUsecase *usecases.TripUsecase, bookingUsecase *usecases.BookingUsecase) *AdminHandler {
+func NewAdminHandler(tripUsecase *usecases.TripUsecase, bookingUsecase *usecases.BookingUsecase, auditUsecase *usecases.AuditLogUsecase) *AdminHandler {
 	return &AdminHandler{
 		tripUsecase:    tripUsecase,
 		bookingUsecase: bookingUsecase,
+		auditUsecase:   auditUsecase,
 	}
 }
 
@@ -149,6 +151,8 @@ func (h *AdminHandler) AssignBus(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+
 	// Assign bus to trip (includes conflict checking)
 	if err := h.tripUsecase.AssignBusToTrip(c.Request.Context(), tripID, busID); err != nil {
 		// Check if it's a conflict error
@@ -169,6 +173,13 @@ func (h *AdminHandler) AssignBus(c *gin.Context) {
 
 	// Get updated trip details
 	trip, err := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionAssignBus,
+		EntityType: entities.AuditEntityTrip,
+		EntityID:   tripID.String(),
+		Before:     before,
+		After:      trip,
+	})
 	if err != nil {
 		// Assignment succeeded but couldn't fetch updated trip
 		c.JSON(http.StatusOK, gin.H{
@@ -354,6 +365,14 @@ func (h *AdminHandler) CheckInPassenger(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCheckIn,
+		EntityType: entities.AuditEntityPassenger,
+		EntityID:   passengerID.String(),
+		Before:     gin.H{"trip_id": tripID, "checked_in": false},
+		After:      gin.H{"trip_id": tripID, "checked_in": true},
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Passenger checked in successfully",
@@ -460,6 +479,13 @@ func (h *AdminHandler) CreateTrip(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityTrip,
+		EntityID:   trip.ID.String(),
+		After:      trip,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Trip created successfully",
@@ -575,6 +601,8 @@ func (h *AdminHandler) UpdateTrip(c *gin.Context) {
 		updates.Notes = req.Notes
 	}
 
+	before, _ := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+
 	// Update the trip
 	err = h.tripUsecase.UpdateTrip(c.Request.Context(), tripID, updates)
 	if err != nil {
@@ -593,6 +621,13 @@ func (h *AdminHandler) UpdateTrip(c *gin.Context) {
 
 	// Get updated trip
 	trip, err := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityTrip,
+		EntityID:   tripID.String(),
+		Before:     before,
+		After:      trip,
+	})
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
@@ -631,6 +666,8 @@ func (h *AdminHandler) DeleteTrip(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+
 	err = h.tripUsecase.DeleteTrip(c.Request.Context(), tripID)
 	if err != nil {
 		if contains(err.Error(), "not found") {
@@ -646,6 +683,13 @@ func (h *AdminHandler) DeleteTrip(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntityTrip,
+		EntityID:   tripID.String(),
+		Before:     before,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Trip deleted successfully",
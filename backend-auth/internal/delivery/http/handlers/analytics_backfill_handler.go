@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// AnalyticsBackfillHandler handles admin-triggered recomputation of historical analytics
+type AnalyticsBackfillHandler struct {
+	backfillUsecase *usecases.AnalyticsBackfillUsecase
+}
+
+// NewAnalyticsBackfillHandler creates a new analytics backfill handler
+func NewAnalyticsBackfillHandler(backfillUsecase *usecases.AnalyticsBackfillUsecase) *AnalyticsBackfillHandler {
+	return &AnalyticsBackfillHandler{
+		backfillUsecase: backfillUsecase,
+	}
+}
+
+// StartBackfillRequest is the date range to recompute, both days inclusive
+type StartBackfillRequest struct {
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date" binding:"required"`   // YYYY-MM-DD, before today
+}
+
+// StartBackfill godoc
+// @Summary Backfill analytics for a date range
+// @Description Recompute booking, route and trip analytics for every day in the range as a background task
+// @Tags admin-analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body StartBackfillRequest true "Date range"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/analytics/backfills [post]
+func (h *AnalyticsBackfillHandler) StartBackfill(c *gin.Context) {
+	var req StartBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format (use YYYY-MM-DD)"})
+		return
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format (use YYYY-MM-DD)"})
+		return
+	}
+
+	var requestedBy *uuid.UUID
+	if userID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		requestedBy = &userID
+	}
+
+	backfill, err := h.backfillUsecase.StartBackfill(c.Request.Context(), startDate, endDate, requestedBy)
+	if err != nil {
+		status := http.StatusBadRequest
+		if containsStr(err.Error(), "already running") {
+			status = http.StatusConflict
+		} else if containsStr(err.Error(), "failed to") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to start backfill",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    backfill,
+	})
+}
+
+// ListBackfills godoc
+// @Summary List analytics backfills
+// @Description Get paginated analytics backfills with their progress, newest first
+// @Tags admin-analytics
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/analytics/backfills [get]
+func (h *AnalyticsBackfillHandler) ListBackfills(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	backfills, total, err := h.backfillUsecase.ListBackfills(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get backfills",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        backfills,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// GetBackfill godoc
+// @Summary Get analytics backfill
+// @Description Get a backfill's status and progress
+// @Tags admin-analytics
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Backfill ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/analytics/backfills/{id} [get]
+func (h *AnalyticsBackfillHandler) GetBackfill(c *gin.Context) {
+	backfillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backfill ID"})
+		return
+	}
+
+	backfill, err := h.backfillUsecase.GetBackfill(c.Request.Context(), backfillID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Backfill not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    backfill,
+	})
+}
+
+// CancelBackfill godoc
+// @Summary Cancel analytics backfill
+// @Description Stop a pending or running backfill after the day it is processing
+// @Tags admin-analytics
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Backfill ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/analytics/backfills/{id}/cancel [post]
+func (h *AnalyticsBackfillHandler) CancelBackfill(c *gin.Context) {
+	backfillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backfill ID"})
+		return
+	}
+
+	backfill, err := h.backfillUsecase.CancelBackfill(c.Request.Context(), backfillID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "not found") {
+			status = http.StatusNotFound
+		} else if containsStr(err.Error(), "already") {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to cancel backfill",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    backfill,
+	})
+}
+
+// GetMissingDates godoc
+// @Summary List days missing analytics
+// @Description List the days whose booking, route or trip aggregates were never computed, up to yesterday
+// @Tags admin-analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to yesterday"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/analytics/missing-dates [get]
+func (h *AnalyticsBackfillHandler) GetMissingDates(c *gin.Context) {
+	endDate := time.Now().AddDate(0, 0, -1)
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDateStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format (use YYYY-MM-DD)"})
+			return
+		}
+		endDate = parsed
+	}
+	startDate := endDate.AddDate(0, 0, -30)
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDateStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format (use YYYY-MM-DD)"})
+			return
+		}
+		startDate = parsed
+	}
+
+	missing, err := h.backfillUsecase.ListMissingDates(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "at most") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to check analytics coverage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       missing,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// exportRowsFunc emits the rows of an export through writeRow
+type exportRowsFunc func(writeRow func(values ...interface{}) error) error
+
+// exportFormat reads ?format=; it returns "" for JSON and responds with 400 for unknown formats
+func exportFormat(c *gin.Context) (string, bool) {
+	switch format := c.Query("format"); format {
+	case "", "json":
+		return "", true
+	case services.ExportFormatCSV, services.ExportFormatXLSX:
+		return format, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'json', 'csv' or 'xlsx'"})
+		return "", false
+	}
+}
+
+// writeExport streams a table as a CSV or XLSX attachment named after the report
+func writeExport(c *gin.Context, format, name string, columns []services.Column, rows exportRowsFunc) {
+	c.Header("Content-Type", services.ExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", name, time.Now().Format("2006-01-02"), format))
+	c.Status(http.StatusOK)
+
+	table, err := services.NewTableWriter(c.Writer, format, name, columns)
+	if err == nil {
+		err = rows(table.WriteRow)
+		if closeErr := table.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		// Headers are already sent, so the truncated download is all the client gets
+		c.Error(err)
+	}
+}
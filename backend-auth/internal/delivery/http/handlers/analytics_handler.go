@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
 	"github.com/yourusername/bus-booking-auth/internal/usecases"
 )
 
@@ -26,7 +30,13 @@ func NewAnalyticsHandler(analyticsUsecase *usecases.AnalyticsUsecase) *Analytics
 
 // GetDashboardSummary handles GET /api/v1/admin/analytics/dashboard
 // Returns key metrics for admin dashboard homepage
+// Query params: format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetDashboardSummary(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	summary, err := h.analyticsUsecase.GetDashboardSummary(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -36,14 +46,37 @@ func (h *AnalyticsHandler) GetDashboardSummary(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "dashboard", []services.Column{
+			{Name: "period", Type: services.ColumnText},
+			{Name: "total_bookings", Type: services.ColumnInteger},
+			{Name: "confirmed_bookings", Type: services.ColumnInteger},
+			{Name: "total_revenue", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			if err := writeRow("today", summary.Today.TotalBookings, summary.Today.ConfirmedBookings, summary.Today.TotalRevenue); err != nil {
+				return err
+			}
+			if err := writeRow("this_week", summary.ThisWeek.TotalBookings, summary.ThisWeek.ConfirmedBookings, summary.ThisWeek.TotalRevenue); err != nil {
+				return err
+			}
+			return writeRow("this_month", summary.ThisMonth.TotalBookings, summary.ThisMonth.ConfirmedBookings, summary.ThisMonth.TotalRevenue)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"dashboard": summary,
 	})
 }
 
 // GetBookingTrends handles GET /api/v1/admin/analytics/bookings/trends
-// Query params: start_date (YYYY-MM-DD), end_date (YYYY-MM-DD)
+// Query params: start_date (YYYY-MM-DD), end_date (YYYY-MM-DD), format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetBookingTrends(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	startDate, endDate, err := h.parseDateRange(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -59,6 +92,24 @@ func (h *AnalyticsHandler) GetBookingTrends(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "booking-trends", []services.Column{
+			{Name: "date", Type: services.ColumnDate},
+			{Name: "total_bookings", Type: services.ColumnInteger},
+			{Name: "confirmed_bookings", Type: services.ColumnInteger},
+			{Name: "total_revenue", Type: services.ColumnDecimal},
+			{Name: "conversion_rate", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, t := range trends {
+				if err := writeRow(t.Date, t.TotalBookings, t.ConfirmedBookings, t.TotalRevenue, t.ConversionRate); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"trends":     trends,
 		"start_date": startDate.Format("2006-01-02"),
@@ -67,8 +118,13 @@ func (h *AnalyticsHandler) GetBookingTrends(c *gin.Context) {
 }
 
 // GetRevenueSummary handles GET /api/v1/admin/analytics/revenue
-// Query params: start_date, end_date
+// Query params: start_date, end_date, format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetRevenueSummary(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	startDate, endDate, err := h.parseDateRange(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -84,14 +140,33 @@ func (h *AnalyticsHandler) GetRevenueSummary(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "revenue", []services.Column{
+			{Name: "start_date", Type: services.ColumnDate},
+			{Name: "end_date", Type: services.ColumnDate},
+			{Name: "total_revenue", Type: services.ColumnDecimal},
+			{Name: "average_per_day", Type: services.ColumnDecimal},
+			{Name: "average_per_booking", Type: services.ColumnDecimal},
+			{Name: "total_bookings", Type: services.ColumnInteger},
+		}, func(writeRow func(values ...interface{}) error) error {
+			return writeRow(summary.StartDate, summary.EndDate, summary.TotalRevenue, summary.AveragePerDay, summary.AveragePerBooking, summary.TotalBookings)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"revenue": summary,
 	})
 }
 
 // GetConversionRate handles GET /api/v1/admin/analytics/conversion-rate
-// Query params: start_date, end_date
+// Query params: start_date, end_date, format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetConversionRate(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	startDate, endDate, err := h.parseDateRange(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -107,14 +182,32 @@ func (h *AnalyticsHandler) GetConversionRate(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "conversion-rate", []services.Column{
+			{Name: "start_date", Type: services.ColumnDate},
+			{Name: "end_date", Type: services.ColumnDate},
+			{Name: "total_attempts", Type: services.ColumnInteger},
+			{Name: "successful_bookings", Type: services.ColumnInteger},
+			{Name: "conversion_rate", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			return writeRow(startDate, endDate, conversionData.TotalAttempts, conversionData.SuccessfulBookings, conversionData.ConversionRate)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"conversion": conversionData,
 	})
 }
 
 // GetPopularRoutes handles GET /api/v1/admin/analytics/routes/popular
-// Query params: start_date, end_date, limit (default: 10), order_by (revenue|bookings, default: revenue)
+// Query params: start_date, end_date, limit (default: 10), order_by (revenue|bookings, default: revenue), format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetPopularRoutes(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	startDate, endDate, err := h.parseDateRange(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -145,6 +238,26 @@ func (h *AnalyticsHandler) GetPopularRoutes(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "popular-routes", []services.Column{
+			{Name: "route_id", Type: services.ColumnText},
+			{Name: "route_name", Type: services.ColumnText},
+			{Name: "origin", Type: services.ColumnText},
+			{Name: "destination", Type: services.ColumnText},
+			{Name: "total_bookings", Type: services.ColumnInteger},
+			{Name: "total_revenue", Type: services.ColumnDecimal},
+			{Name: "avg_occupancy", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, r := range routes {
+				if err := writeRow(r.RouteID, r.RouteName, r.Origin, r.Destination, r.TotalBookings, r.TotalRevenue, r.AvgOccupancy); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"routes":     routes,
 		"start_date": startDate.Format("2006-01-02"),
@@ -155,11 +268,18 @@ func (h *AnalyticsHandler) GetPopularRoutes(c *gin.Context) {
 }
 
 // GetRoutePerformance handles GET /api/v1/admin/analytics/routes/:id/performance
-// Path param: route_id
-// Query params: start_date, end_date
+// Path param: id (route UUID)
+// Query params: start_date, end_date, format (json|csv|xlsx)
+// Exports hold the daily trends, or the departure times with breakdown=departure_time
+// Returns daily trends plus a breakdown per departure time of day
 func (h *AnalyticsHandler) GetRoutePerformance(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	// Parse route ID
-	routeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	routeID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID"})
 		return
@@ -171,8 +291,12 @@ func (h *AnalyticsHandler) GetRoutePerformance(c *gin.Context) {
 		return
 	}
 
-	performance, err := h.analyticsUsecase.GetRoutePerformance(c.Request.Context(), uint(routeID), startDate, endDate)
+	performance, err := h.analyticsUsecase.GetRoutePerformance(c.Request.Context(), routeID, startDate, endDate)
 	if err != nil {
+		if containsStr(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to retrieve route performance",
 			"details": err.Error(),
@@ -180,14 +304,151 @@ func (h *AnalyticsHandler) GetRoutePerformance(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		if c.Query("breakdown") == "departure_time" {
+			writeExport(c, format, "route-departure-times", []services.Column{
+				{Name: "departure_time", Type: services.ColumnText},
+				{Name: "trips", Type: services.ColumnInteger},
+				{Name: "bookings", Type: services.ColumnInteger},
+				{Name: "revenue", Type: services.ColumnDecimal},
+				{Name: "revenue_per_trip", Type: services.ColumnDecimal},
+				{Name: "occupancy_rate", Type: services.ColumnDecimal},
+				{Name: "occupancy_vs_route", Type: services.ColumnDecimal},
+				{Name: "cancellation_rate", Type: services.ColumnDecimal},
+				{Name: "average_rating", Type: services.ColumnDecimal},
+				{Name: "rating_count", Type: services.ColumnInteger},
+			}, func(writeRow func(values ...interface{}) error) error {
+				for _, d := range performance.DepartureTimes {
+					if err := writeRow(d.DepartureTime, d.Trips, d.Bookings, d.Revenue, d.RevenuePerTrip, d.OccupancyRate, d.OccupancyVsRoute, d.CancellationRate, d.AverageRating, d.RatingCount); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return
+		}
+		writeExport(c, format, "route-performance", []services.Column{
+			{Name: "date", Type: services.ColumnDate},
+			{Name: "trips", Type: services.ColumnInteger},
+			{Name: "bookings", Type: services.ColumnInteger},
+			{Name: "revenue", Type: services.ColumnDecimal},
+			{Name: "occupancy_rate", Type: services.ColumnDecimal},
+			{Name: "cancellation_rate", Type: services.ColumnDecimal},
+			{Name: "average_rating", Type: services.ColumnDecimal},
+			{Name: "rating_count", Type: services.ColumnInteger},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, d := range performance.DailyTrends {
+				if err := writeRow(d.Date, d.Trips, d.Bookings, d.Revenue, d.OccupancyRate, d.CancellationRate, d.AverageRating, d.RatingCount); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"performance": performance,
 	})
 }
 
+// GetTripPerformance handles GET /api/v1/admin/analytics/trips
+// Query params: start_date, end_date (departure dates), route_id, bus_id, page, page_size, format (json|csv|xlsx)
+// Exports include every matching departure, not just one page
+// Returns seats sold against capacity, revenue per seat and per km, check-ins, no-shows and cancellations per departure
+func (h *AnalyticsHandler) GetTripPerformance(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repositories.TripAnalyticsFilter{From: &startDate, To: &endDate}
+	if routeIDStr := c.Query("route_id"); routeIDStr != "" {
+		routeID, err := uuid.Parse(routeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route_id"})
+			return
+		}
+		filter.RouteID = &routeID
+	}
+	if busIDStr := c.Query("bus_id"); busIDStr != "" {
+		busID, err := uuid.Parse(busIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bus_id"})
+			return
+		}
+		filter.BusID = &busID
+	}
+
+	if format != "" {
+		writeExport(c, format, "trip-performance", []services.Column{
+			{Name: "departure_time", Type: services.ColumnDateTime},
+			{Name: "trip_id", Type: services.ColumnText},
+			{Name: "route", Type: services.ColumnText},
+			{Name: "bus", Type: services.ColumnText},
+			{Name: "seat_capacity", Type: services.ColumnInteger},
+			{Name: "seats_sold", Type: services.ColumnInteger},
+			{Name: "load_factor", Type: services.ColumnDecimal},
+			{Name: "revenue", Type: services.ColumnDecimal},
+			{Name: "revenue_per_seat", Type: services.ColumnDecimal},
+			{Name: "distance_km", Type: services.ColumnDecimal},
+			{Name: "revenue_per_km", Type: services.ColumnDecimal},
+			{Name: "revenue_per_seat_km", Type: services.ColumnDecimal},
+			{Name: "checked_in", Type: services.ColumnInteger},
+			{Name: "no_shows", Type: services.ColumnInteger},
+			{Name: "cancelled_bookings", Type: services.ColumnInteger},
+			{Name: "cancelled_seats", Type: services.ColumnInteger},
+		}, func(writeRow func(values ...interface{}) error) error {
+			return h.analyticsUsecase.ExportTripPerformance(c.Request.Context(), filter, func(t *entities.TripAnalytics) error {
+				var route, bus interface{}
+				if t.Route != nil {
+					route = t.Route.Origin + " - " + t.Route.Destination
+				}
+				if t.Bus != nil {
+					bus = t.Bus.PlateNumber
+				}
+				return writeRow(t.DepartureTime, t.TripID, route, bus, t.SeatCapacity, t.SeatsSold, t.LoadFactor, t.Revenue,
+					t.RevenuePerSeat, t.DistanceKm, t.RevenuePerKm, t.RevenuePerSeatKm, t.CheckedIn, t.NoShows, t.CancelledBookings, t.CancelledSeats)
+			})
+		})
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+	trips, total, err := h.analyticsUsecase.ListTripPerformance(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve trip performance",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trips":       trips,
+		"start_date":  startDate.Format("2006-01-02"),
+		"end_date":    endDate.Format("2006-01-02"),
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
 // GetRevenueByTimeOfDay handles GET /api/v1/admin/analytics/revenue/time-of-day
-// Query params: start_date, end_date
+// Query params: start_date, end_date, format (json|csv|xlsx)
 func (h *AnalyticsHandler) GetRevenueByTimeOfDay(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	startDate, endDate, err := h.parseDateRange(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -203,6 +464,29 @@ func (h *AnalyticsHandler) GetRevenueByTimeOfDay(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "revenue-time-of-day", []services.Column{
+			{Name: "period", Type: services.ColumnText},
+			{Name: "revenue", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, row := range []struct {
+				period  string
+				revenue float64
+			}{
+				{"morning", distribution.Morning},
+				{"afternoon", distribution.Afternoon},
+				{"evening", distribution.Evening},
+				{"night", distribution.Night},
+			} {
+				if err := writeRow(row.period, row.revenue); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"distribution": distribution,
 		"start_date":   startDate.Format("2006-01-02"),
@@ -215,7 +499,13 @@ func (h *AnalyticsHandler) GetRevenueByTimeOfDay(c *gin.Context) {
 //
 //	current_start, current_end
 //	previous_start, previous_end
+//	format (json|csv|xlsx)
 func (h *AnalyticsHandler) ComparePeriods(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
 	// Parse current period
 	currentStart, err := time.Parse("2006-01-02", c.Query("current_start"))
 	if err != nil {
@@ -251,11 +541,148 @@ func (h *AnalyticsHandler) ComparePeriods(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		writeExport(c, format, "period-comparison", []services.Column{
+			{Name: "period", Type: services.ColumnText},
+			{Name: "start_date", Type: services.ColumnDate},
+			{Name: "end_date", Type: services.ColumnDate},
+			{Name: "total_bookings", Type: services.ColumnDecimal},
+			{Name: "confirmed_bookings", Type: services.ColumnDecimal},
+			{Name: "total_revenue", Type: services.ColumnDecimal},
+			{Name: "conversion_rate", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			current, previous, changes := comparison.Current, comparison.Previous, comparison.Changes
+			if err := writeRow("current", current.StartDate, current.EndDate, current.TotalBookings, current.ConfirmedBookings, current.TotalRevenue, current.ConversionRate); err != nil {
+				return err
+			}
+			if err := writeRow("previous", previous.StartDate, previous.EndDate, previous.TotalBookings, previous.ConfirmedBookings, previous.TotalRevenue, previous.ConversionRate); err != nil {
+				return err
+			}
+			// Percent change between the periods, in the same columns
+			return writeRow("change_percent", nil, nil, changes.BookingsChange, nil, changes.RevenueChange, changes.ConversionChange)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"comparison": comparison,
 	})
 }
 
+// GetRouteFunnels handles GET /api/v1/admin/analytics/funnel
+// Query params: start_date, end_date, format (json|csv|xlsx)
+// Returns, per route, the visitor sessions reaching each step from search to completed payment
+func (h *AnalyticsHandler) GetRouteFunnels(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	funnels, err := h.analyticsUsecase.GetRouteFunnels(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve booking funnel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if format != "" {
+		writeExport(c, format, "booking-funnel", []services.Column{
+			{Name: "route_id", Type: services.ColumnText},
+			{Name: "origin", Type: services.ColumnText},
+			{Name: "destination", Type: services.ColumnText},
+			{Name: "step", Type: services.ColumnText},
+			{Name: "sessions", Type: services.ColumnInteger},
+			{Name: "drop_off", Type: services.ColumnDecimal},
+			{Name: "conversion", Type: services.ColumnDecimal},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, f := range funnels {
+				for _, step := range f.Steps {
+					if err := writeRow(f.RouteID, f.Origin, f.Destination, string(step.Step), step.Sessions, step.DropOff, step.Conversion); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routes":     funnels,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	})
+}
+
+// GetUnmetDemand handles GET /api/v1/admin/analytics/unmet-demand
+// Query params: start_date, end_date, limit (default: 20, max 100), format (json|csv|xlsx)
+// Returns searches that found no trips, grouped by origin, destination and travel date
+func (h *AnalyticsHandler) GetUnmetDemand(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	demand, err := h.analyticsUsecase.GetUnmetDemand(c.Request.Context(), startDate, endDate, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve unmet demand",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if format != "" {
+		writeExport(c, format, "unmet-demand", []services.Column{
+			{Name: "origin", Type: services.ColumnText},
+			{Name: "destination", Type: services.ColumnText},
+			{Name: "travel_date", Type: services.ColumnDate},
+			{Name: "searches", Type: services.ColumnInteger},
+			{Name: "sessions", Type: services.ColumnInteger},
+			{Name: "last_searched_at", Type: services.ColumnDateTime},
+		}, func(writeRow func(values ...interface{}) error) error {
+			for _, d := range demand {
+				if err := writeRow(d.Origin, d.Destination, d.TravelDate, d.Searches, d.Sessions, d.LastSearch); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"searches":   demand,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+		"limit":      limit,
+	})
+}
+
 // Helper function to parse date range from query parameters
 func (h *AnalyticsHandler) parseDateRange(c *gin.Context) (startDate, endDate time.Time, err error) {
 	// Get start_date from query, default to 30 days ago
@@ -292,10 +719,10 @@ func (h *AnalyticsHandler) parseDateRange(c *gin.Context) (startDate, endDate ti
 }
 
 // RegisterAnalyticsRoutes registers all analytics-related routes
-// All routes require admin authentication
-func RegisterAnalyticsRoutes(router *gin.RouterGroup, handler *AnalyticsHandler, adminMiddleware gin.HandlerFunc) {
+// All routes are guarded by the given permission middleware
+func RegisterAnalyticsRoutes(router *gin.RouterGroup, handler *AnalyticsHandler, permissionMiddleware gin.HandlerFunc) {
 	analytics := router.Group("/analytics")
-	analytics.Use(adminMiddleware)
+	analytics.Use(permissionMiddleware)
 	{
 		// Dashboard overview
 		analytics.GET("/dashboard", handler.GetDashboardSummary)
@@ -312,6 +739,13 @@ func RegisterAnalyticsRoutes(router *gin.RouterGroup, handler *AnalyticsHandler,
 		analytics.GET("/routes/popular", handler.GetPopularRoutes)
 		analytics.GET("/routes/:id/performance", handler.GetRoutePerformance)
 
+		// Trip analytics
+		analytics.GET("/trips", handler.GetTripPerformance)
+
+		// Search-to-booking funnel
+		analytics.GET("/funnel", handler.GetRouteFunnels)
+		analytics.GET("/unmet-demand", handler.GetUnmetDemand)
+
 		// Comparison
 		analytics.GET("/compare", handler.ComparePeriods)
 	}
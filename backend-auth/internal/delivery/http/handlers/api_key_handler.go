@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// APIKeyHandler handles admin management of partner API keys
+type APIKeyHandler struct {
+	apiKeyUsecase *usecases.APIKeyUsecase
+	auditUsecase  *usecases.AuditLogUsecase
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyUsecase *usecases.APIKeyUsecase, auditUsecase *usecases.AuditLogUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+		auditUsecase:  auditUsecase,
+	}
+}
+
+// IssueAPIKeyRequest represents the request for issuing a partner API key
+type IssueAPIKeyRequest struct {
+	Name               string   `json:"name" binding:"required"`
+	PartnerName        string   `json:"partner_name" binding:"required"`
+	Scopes             []string `json:"scopes" binding:"required,min=1"`                 // search, booking
+	RateLimitPerMinute int      `json:"rate_limit_per_minute" binding:"omitempty,min=1"` // Defaults to 60
+	AllowedIPs         []string `json:"allowed_ips"`                                     // IPs or CIDR ranges; empty allows any address
+}
+
+// RotateAPIKeyRequest represents the request for rotating a partner API key
+type RotateAPIKeyRequest struct {
+	GraceMinutes int `json:"grace_minutes" binding:"min=0"` // How long the old key keeps working, 0 revokes it immediately
+}
+
+// IssueKey godoc
+// @Summary Issue partner API key
+// @Description Create an API key for a travel agency or reseller. The key is only shown in this response; store it securely.
+// @Tags admin-api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body IssueAPIKeyRequest true "Key details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) IssueKey(c *gin.Context) {
+	var req IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	scopes := make([]entities.APIKeyScope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = entities.APIKeyScope(scope)
+	}
+
+	issued, err := h.apiKeyUsecase.IssueKey(c.Request.Context(), usecases.IssueAPIKeyInput{
+		Name:               req.Name,
+		PartnerName:        req.PartnerName,
+		Scopes:             scopes,
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		AllowedIPs:         req.AllowedIPs,
+	}, currentUserID(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to issue API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityAPIKey,
+		EntityID:   issued.Key.ID.String(),
+		After:      issued.Key,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    issued,
+		"message": "API key issued. Copy the secret now, it will not be shown again",
+	})
+}
+
+// ListKeys godoc
+// @Summary List partner API keys
+// @Description List partner API keys without their secrets
+// @Tags admin-api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param partner query string false "Partner name"
+// @Param include_revoked query bool false "Include revoked keys"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	includeRevoked, _ := strconv.ParseBool(c.Query("include_revoked"))
+
+	keys, err := h.apiKeyUsecase.ListKeys(c.Request.Context(), c.Query("partner"), includeRevoked)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// GetKey godoc
+// @Summary Get partner API key
+// @Description Get a partner API key's settings and last use, without its secret
+// @Tags admin-api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/api-keys/{id} [get]
+func (h *APIKeyHandler) GetKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	key, err := h.apiKeyUsecase.GetKey(c.Request.Context(), keyID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// RotateKey godoc
+// @Summary Rotate partner API key
+// @Description Issue a replacement key with the same partner, scopes and limits. The old key keeps working for the grace period.
+// @Tags admin-api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Param request body RotateAPIKeyRequest false "Grace period"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	before, _ := h.apiKeyUsecase.GetKey(c.Request.Context(), keyID)
+
+	issued, err := h.apiKeyUsecase.RotateKey(c.Request.Context(), keyID, time.Duration(req.GraceMinutes)*time.Minute, currentUserID(c))
+	if err != nil {
+		c.JSON(apiKeyErrorStatus(err), gin.H{
+			"error":   "Failed to rotate API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	after, _ := h.apiKeyUsecase.GetKey(c.Request.Context(), keyID)
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionRotate,
+		EntityType: entities.AuditEntityAPIKey,
+		EntityID:   keyID.String(),
+		Before:     before,
+		After:      after,
+	})
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityAPIKey,
+		EntityID:   issued.Key.ID.String(),
+		After:      issued.Key,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    issued,
+		"message": "API key rotated. Copy the new secret now, it will not be shown again",
+	})
+}
+
+// RevokeKey godoc
+// @Summary Revoke partner API key
+// @Description Permanently disable a partner API key
+// @Tags admin-api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	before, _ := h.apiKeyUsecase.GetKey(c.Request.Context(), keyID)
+
+	key, err := h.apiKeyUsecase.RevokeKey(c.Request.Context(), keyID, currentUserID(c))
+	if err != nil {
+		c.JSON(apiKeyErrorStatus(err), gin.H{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionRevoke,
+		EntityType: entities.AuditEntityAPIKey,
+		EntityID:   keyID.String(),
+		Before:     before,
+		After:      key,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    key,
+		"message": "API key revoked successfully",
+	})
+}
+
+// apiKeyErrorStatus maps API key management errors to HTTP status codes
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case containsStr(err.Error(), "not found"):
+		return http.StatusNotFound
+	case containsStr(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	case containsStr(err.Error(), "cannot"):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/usecases"
 )
 
@@ -72,6 +74,23 @@ type GoogleAuthRequest struct {
 	IDToken string `json:"id_token" binding:"required"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
 type AuthResponse struct {
 	AccessToken string      `json:"access_token"`
 	User        interface{} `json:"user"`
@@ -94,7 +113,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authUsecase.Register(c.Request.Context(), usecases.RegisterInput{
+	tokens, err := h.authUsecase.Register(clientContext(c), usecases.RegisterInput{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
@@ -117,13 +136,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT tokens
+// @Description Authenticate user and return JWT tokens. Accounts with two-factor authentication get an MFAChallengeResponse instead.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
 // @Success 200 {object} AuthResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -132,15 +152,23 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authUsecase.Login(c.Request.Context(), usecases.LoginInput{
+	tokens, err := h.authUsecase.Login(clientContext(c), usecases.LoginInput{
 		Email:    req.Email,
 		Password: req.Password,
 	})
 	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	// Accounts with two-factor authentication continue at /auth/mfa/verify
+	if h.respondMFAChallenge(c, tokens) {
+		return
+	}
+
 	// Set refresh token as HttpOnly cookie with cross-site support
 	h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
 
@@ -179,14 +207,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		}
 	}
 
-	tokens, err := h.authUsecase.RefreshAccessToken(c.Request.Context(), refreshToken)
+	tokens, err := h.authUsecase.RefreshAccessToken(clientContext(c), refreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Set refresh token as HttpOnly cookie (update it) with cross-site support
-	h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
+	// A parallel refresh inside the reuse grace window only gets an access token; the cookie set by
+	// the request that rotated the token stays in place
+	if tokens.RefreshToken != "" {
+		h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
+	}
 
 	// Return access token in response (don't include refresh token)
 	c.JSON(http.StatusOK, AuthResponse{
@@ -195,6 +227,155 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm account ownership of the email address using the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.authUsecase.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Email verified successfully",
+		Data:    user,
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link if the address belongs to an unverified account. The response is the same either way.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Account email"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.authUsecase.ResendVerificationEmail(c.Request.Context(), req.Email); err != nil {
+		log.Printf("[Auth] Resend verification failed: %v", err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "If the address belongs to an unverified account, a verification email has been sent",
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link if the address belongs to an account. The response is the same either way.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.authUsecase.RequestPasswordReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		log.Printf("[Auth] Password reset request failed: %v", err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "If the address belongs to an account, a password reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using the token from the reset email. All existing sessions are signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.authUsecase.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		status := http.StatusBadRequest
+		if containsStr(err.Error(), "failed to") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Any refresh cookie on this device belongs to a revoked session
+	h.clearCrossSiteCookie(c, "refresh_token")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Password has been reset, please log in with your new password",
+	})
+}
+
+// ResendMyVerification godoc
+// @Summary Resend verification email for the current user
+// @Description Send a new verification link to the signed-in user's email address
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /profile/verify-email/resend [post]
+func (h *AuthHandler) ResendMyVerification(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	if err := h.authUsecase.SendVerificationEmail(c.Request.Context(), *userID); err != nil {
+		status := http.StatusBadRequest
+		if containsStr(err.Error(), "sent recently") {
+			status = http.StatusTooManyRequests
+		} else if containsStr(err.Error(), "failed to send") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Verification email sent",
+	})
+}
+
 // Logout godoc
 // @Summary Logout user
 // @Description Revoke refresh token and logout
@@ -283,39 +464,23 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	// Check if user exists
-	email := googleUser.Email
-	existingUser, err := h.authUsecase.GetUserByEmail(c.Request.Context(), email)
-	
-	var user interface{}
-	if err != nil || existingUser == nil {
-		// Create new user
-		tokens, createErr := h.authUsecase.RegisterOAuth(c.Request.Context(), usecases.OAuthUserInput{
-			Email:    email,
-			Name:     googleUser.Name,
-			Provider: "google",
-			Avatar:   googleUser.Picture,
-		})
-		if createErr != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: createErr.Error()})
-			return
-		}
-		user = tokens.User
-		
-		// Set refresh token cookie with cross-site support
-		h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
-		
-		c.JSON(http.StatusOK, AuthResponse{
-			AccessToken: tokens.AccessToken,
-			User:        user,
-		})
+	tokens, err := h.authUsecase.LoginWithOAuth(clientContext(c), usecases.OAuthUserInput{
+		Email:          googleUser.Email,
+		Name:           googleUser.Name,
+		Provider:       string(entities.OAuthProviderGoogle),
+		ProviderUserID: googleUser.ID,
+		Avatar:         googleUser.Picture,
+	})
+	h.respondOAuthLogin(c, tokens, err)
+}
+
+// respondOAuthLogin completes an OAuth sign-in with tokens or an MFA challenge
+func (h *AuthHandler) respondOAuthLogin(c *gin.Context, tokens *usecases.AuthTokens, err error) {
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
-
-	// User exists, login
-	tokens, loginErr := h.authUsecase.LoginOAuth(c.Request.Context(), existingUser.ID)
-	if loginErr != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loginErr.Error()})
+	if h.respondMFAChallenge(c, tokens) {
 		return
 	}
 
@@ -335,15 +500,37 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 // @Success 302
 // @Router /auth/github [get]
 func (h *AuthHandler) GitHubLogin(c *gin.Context) {
-	c.Redirect(http.StatusTemporaryRedirect, "https://github.com/login/oauth/authorize")
+	c.Redirect(http.StatusTemporaryRedirect, GitHubAuthorizeURL(c.Query("state")))
 }
 
 // GitHubCallback godoc
 // @Summary GitHub OAuth callback
-// @Description Handle GitHub OAuth callback
+// @Description Exchange the GitHub authorization code and sign in with the linked account
 // @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
 // @Success 200 {object} AuthResponse
+// @Failure 400 {object} ErrorResponse
 // @Router /auth/github/callback [get]
 func (h *AuthHandler) GitHubCallback(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "GitHub auth successful"})
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Authorization code is required"})
+		return
+	}
+
+	githubUser, err := h.ExchangeGitHubCode(c.Request.Context(), code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid GitHub authorization: %v", err)})
+		return
+	}
+
+	tokens, err := h.authUsecase.LoginWithOAuth(clientContext(c), usecases.OAuthUserInput{
+		Email:          githubUser.Email,
+		Name:           githubUser.Name,
+		Provider:       string(entities.OAuthProviderGitHub),
+		ProviderUserID: githubUser.ID,
+		Avatar:         githubUser.AvatarURL,
+	})
+	h.respondOAuthLogin(c, tokens, err)
 }
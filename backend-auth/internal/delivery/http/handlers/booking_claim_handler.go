@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ClaimBookingsRequest represents the request for claiming guest bookings
+type ClaimBookingsRequest struct {
+	BookingIDs []uuid.UUID `json:"booking_ids"` // Empty claims every guest booking made with the account's email
+}
+
+// ConfirmClaimRequest represents the request for confirming a booking claim
+type ConfirmClaimRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GetClaimableBookings lists guest bookings that can be added to the account
+// @Summary List claimable guest bookings
+// @Description List bookings made as a guest with the current user's verified email address that are not attached to any account yet
+// @Tags Booking
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /bookings/claimable [get]
+func (h *BookingHandler) GetClaimableBookings(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	bookings, err := h.bookingUsecase.GetClaimableBookings(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(claimErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Claimable bookings retrieved successfully",
+		Data:    bookings,
+	})
+}
+
+// ClaimBookings sends a code for attaching guest bookings to the account
+// @Summary Claim guest bookings
+// @Description Send a one-time code to the bookings' contact email. Confirm it with /bookings/claim/confirm to add the bookings to the account.
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param request body ClaimBookingsRequest false "Bookings to claim"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /bookings/claim [post]
+func (h *BookingHandler) ClaimBookings(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req ClaimBookingsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	challenge, err := h.bookingUsecase.RequestBookingClaim(c.Request.Context(), *userID, req.BookingIDs)
+	if err != nil {
+		c.JSON(claimErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: fmt.Sprintf("A code was sent to %s", challenge.Destination),
+		Data:    challenge,
+	})
+}
+
+// ConfirmClaim attaches the guest bookings once the emailed code is entered
+// @Summary Confirm guest booking claim
+// @Description Add the guest bookings to the account with the code from the email. They then appear in my-bookings and can be reviewed.
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param request body ConfirmClaimRequest true "One-time code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /bookings/claim/confirm [post]
+func (h *BookingHandler) ConfirmClaim(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req ConfirmClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	bookings, err := h.bookingUsecase.ConfirmBookingClaim(c.Request.Context(), *userID, req.Code)
+	if err != nil {
+		c.JSON(claimErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: fmt.Sprintf("%d booking(s) added to your account", len(bookings)),
+		Data:    bookings,
+	})
+}
+
+func claimErrorStatus(err error) int {
+	switch {
+	case containsStr(err.Error(), "not verified"):
+		return http.StatusForbidden
+	case containsStr(err.Error(), "sent recently"):
+		return http.StatusTooManyRequests
+	case containsStr(err.Error(), "not found"):
+		return http.StatusNotFound
+	case containsStr(err.Error(), "invalid"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
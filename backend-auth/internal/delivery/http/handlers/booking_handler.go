@@ -21,14 +21,16 @@ func NewBookingHandler(bookingUsecase *usecases.BookingUsecase) *BookingHandler
 
 // DownloadTicket downloads a ticket PDF
 // @Summary Download ticket
-// @Description Download e-ticket PDF by ticket ID
+// @Description Download e-ticket PDF by ticket ID; the ticket must be on one of the user's bookings
 // @Tags Booking
 // @Accept json
 // @Produce application/pdf
 // @Param id path string true "Ticket ID"
 // @Success 200 {file} application/pdf
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /tickets/{id}/download [get]
 func (h *BookingHandler) DownloadTicket(c *gin.Context) {
 	idStr := c.Param("id")
@@ -38,6 +40,12 @@ func (h *BookingHandler) DownloadTicket(c *gin.Context) {
 		return
 	}
 
+	bookingID, err := h.bookingUsecase.TicketBookingID(c.Request.Context(), ticketID)
+	if err != nil || !h.authorizeOwnBooking(c, bookingID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "ticket not found"})
+		return
+	}
+
 	pdfBytes, filename, err := h.bookingUsecase.GenerateTicketPDF(c.Request.Context(), ticketID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
@@ -51,14 +59,16 @@ func (h *BookingHandler) DownloadTicket(c *gin.Context) {
 
 // DownloadBookingTickets downloads all tickets for a booking
 // @Summary Download all booking tickets
-// @Description Download all e-tickets for a booking as a single PDF
+// @Description Download all e-tickets for one of the user's bookings as a single PDF
 // @Tags Booking
 // @Accept json
 // @Produce application/pdf
 // @Param id path string true "Booking ID"
 // @Success 200 {file} application/pdf
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /bookings/{id}/tickets/download [get]
 func (h *BookingHandler) DownloadBookingTickets(c *gin.Context) {
 	idStr := c.Param("id")
@@ -67,6 +77,10 @@ func (h *BookingHandler) DownloadBookingTickets(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid booking ID"})
 		return
 	}
+	if !h.authorizeOwnBooking(c, bookingID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		return
+	}
 
 	pdfBytes, filename, err := h.bookingUsecase.GenerateBookingTicketsPDF(c.Request.Context(), bookingID)
 	if err != nil {
@@ -88,7 +102,9 @@ func (h *BookingHandler) DownloadBookingTickets(c *gin.Context) {
 // @Param id path string true "Booking ID"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /bookings/{id}/resend-tickets [post]
 func (h *BookingHandler) ResendTicketEmail(c *gin.Context) {
 	idStr := c.Param("id")
@@ -97,6 +113,10 @@ func (h *BookingHandler) ResendTicketEmail(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid booking ID"})
 		return
 	}
+	if !h.authorizeOwnBooking(c, bookingID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		return
+	}
 
 	if err := h.bookingUsecase.ResendTicketEmails(c.Request.Context(), bookingID); err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
@@ -172,6 +192,7 @@ func (h *BookingHandler) ReleaseSeats(c *gin.Context) {
 // @Param input body usecases.CreateBookingInput true "Booking details"
 // @Success 201 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Email not verified"
 // @Failure 409 {object} ErrorResponse "Seats not available"
 // @Router /bookings [post]
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
@@ -190,8 +211,23 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		}
 	}
 
+	if input.Locale == "" {
+		input.Locale = c.GetHeader("Accept-Language")
+	}
+
+	// Bookings made through the partner API are attributed to the partner's key
+	if keyID, err := uuid.Parse(c.GetString("api_key_id")); err == nil {
+		partner := c.GetString("api_key_partner")
+		input.APIKeyID = &keyID
+		input.PartnerName = &partner
+	}
+
 	result, err := h.bookingUsecase.CreateBooking(c.Request.Context(), input)
 	if err != nil {
+		if containsStr(err.Error(), "not verified") {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -212,7 +248,9 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 // @Param input body object true "Payment details"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /bookings/{id}/confirm [post]
 func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	idStr := c.Param("id")
@@ -221,6 +259,10 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid booking ID"})
 		return
 	}
+	if !h.authorizeOwnBooking(c, bookingID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		return
+	}
 
 	var input struct {
 		PaymentMethod    string `json:"payment_method" binding:"required"`
@@ -252,7 +294,9 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 // @Param input body object false "Cancellation details"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /bookings/{id}/cancel [post]
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	idStr := c.Param("id")
@@ -261,6 +305,10 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid booking ID"})
 		return
 	}
+	if !h.authorizeOwnBooking(c, bookingID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		return
+	}
 
 	var input struct {
 		Reason string `json:"reason"`
@@ -277,22 +325,21 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	})
 }
 
-// GetBookingByReference retrieves a booking by reference
-// @Summary Get booking by reference
-// @Description Get booking details by booking reference number
-// @Tags Booking
-// @Accept json
+// GetPartnerBooking retrieves a booking made through the partner API
+// @Summary Get partner booking
+// @Description Get a booking by reference; only bookings made with the calling partner's API keys are visible
+// @Tags Partner
 // @Produce json
-// @Param reference path string true "Booking Reference"
+// @Param reference path string true "Booking reference"
+// @Param X-API-Key header string true "Partner API key"
 // @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /bookings/ref/{reference} [get]
-func (h *BookingHandler) GetBookingByReference(c *gin.Context) {
-	reference := c.Param("reference")
-
-	result, err := h.bookingUsecase.GetBookingByReference(c.Request.Context(), reference)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+// @Router /partner/bookings/{reference} [get]
+func (h *BookingHandler) GetPartnerBooking(c *gin.Context) {
+	result, err := h.bookingUsecase.GetBookingByReference(c.Request.Context(), c.Param("reference"))
+	if err != nil || result.Booking.PartnerName == nil || *result.Booking.PartnerName != c.GetString("api_key_partner") {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
 		return
 	}
 
@@ -357,38 +404,6 @@ func (h *BookingHandler) GetUserBookings(c *gin.Context) {
 	})
 }
 
-// GetGuestBookings retrieves guest bookings
-// @Summary Get guest bookings
-// @Description Get bookings for guest users by email or phone
-// @Tags Booking
-// @Accept json
-// @Produce json
-// @Param email query string false "Email"
-// @Param phone query string false "Phone"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Router /bookings/guest [get]
-func (h *BookingHandler) GetGuestBookings(c *gin.Context) {
-	email := c.Query("email")
-	phone := c.Query("phone")
-
-	if email == "" && phone == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email or phone is required"})
-		return
-	}
-
-	bookings, err := h.bookingUsecase.GetGuestBookings(c.Request.Context(), email, phone)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{
-		Message: "Guest bookings retrieved successfully",
-		Data:    bookings,
-	})
-}
-
 // GetAvailableSeats gets available seats for a trip
 // @Summary Get available seats
 // @Description Get list of available seats for a specific trip
@@ -470,17 +485,7 @@ func (h *BookingHandler) UpdatePassengerInfo(c *gin.Context) {
 
 	// Update passenger info
 	if err := h.bookingUsecase.UpdatePassengerInfo(c.Request.Context(), bookingID, passengerID, input); err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "can only update passenger info for confirmed bookings" ||
-			err.Error() == "cannot update passenger info after trip departure" {
-			statusCode = http.StatusBadRequest
-		} else if err.Error() == "passenger not found" || err.Error() == "booking not found" {
-			statusCode = http.StatusNotFound
-		} else if err.Error() == "passenger does not belong to this booking" {
-			statusCode = http.StatusForbidden
-		}
-
-		c.JSON(statusCode, ErrorResponse{Error: err.Error()})
+		c.JSON(passengerUpdateStatus(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -489,6 +494,20 @@ func (h *BookingHandler) UpdatePassengerInfo(c *gin.Context) {
 	})
 }
 
+// passengerUpdateStatus maps UpdatePassengerInfo errors to HTTP status codes
+func passengerUpdateStatus(err error) int {
+	switch err.Error() {
+	case "can only update passenger info for confirmed bookings", "cannot update passenger info after trip departure":
+		return http.StatusBadRequest
+	case "passenger not found", "booking not found":
+		return http.StatusNotFound
+	case "passenger does not belong to this booking":
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // ChangeSeat handles PUT /api/v1/bookings/:id/passengers/:passenger_id/seat
 // Changes a passenger's seat assignment
 func (h *BookingHandler) ChangeSeat(c *gin.Context) {
@@ -582,3 +601,9 @@ func (h *BookingHandler) AddPassenger(c *gin.Context) {
 		},
 	})
 }
+
+// authorizeOwnBooking reports whether the booking belongs to the authenticated user
+func (h *BookingHandler) authorizeOwnBooking(c *gin.Context, bookingID uuid.UUID) bool {
+	userID := currentUserID(c)
+	return userID != nil && h.bookingUsecase.AuthorizeBookingOwner(c.Request.Context(), *userID, bookingID) == nil
+}
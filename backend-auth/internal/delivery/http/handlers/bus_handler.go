@@ -7,17 +7,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
 )
 
 // BusHandler handles bus-related operations
 type BusHandler struct {
-	busRepo repositories.BusRepository
+	busRepo      repositories.BusRepository
+	auditUsecase *usecases.AuditLogUsecase
 }
 
 // NewBusHandler creates a new bus handler
-func NewBusHandler(busRepo repositories.BusRepository) *BusHandler {
+func NewBusHandler(busRepo repositories.BusRepository, auditUsecase *usecases.AuditLogUsecase) *BusHandler {
 	return &BusHandler{
-		busRepo: busRepo,
+		busRepo:      busRepo,
+		auditUsecase: auditUsecase,
 	}
 }
 
@@ -30,6 +33,9 @@ type CreateBusRequest struct {
 	Manufacturer *string `json:"manufacturer,omitempty"`
 	Model        *string `json:"model,omitempty"`
 	Year         *int    `json:"year,omitempty"`
+
+	// Owning operator; operator staff always create buses for their own operator
+	OperatorID *uuid.UUID `json:"operatorId,omitempty"`
 }
 
 // UpdateBusRequest represents the request for updating a bus
@@ -79,6 +85,7 @@ func (h *BusHandler) CreateBus(c *gin.Context) {
 		Model:        req.Model,
 		Year:         req.Year,
 		Status:       entities.BusStatusActive,
+		OperatorID:   req.OperatorID,
 	}
 
 	// Save to database
@@ -98,6 +105,13 @@ func (h *BusHandler) CreateBus(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityBus,
+		EntityID:   bus.ID.String(),
+		After:      bus,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Bus created successfully",
 		"data":    bus,
@@ -203,6 +217,8 @@ func (h *BusHandler) UpdateBus(c *gin.Context) {
 		return
 	}
 
+	before := *bus
+
 	// Update fields if provided
 	if req.Name != nil {
 		bus.Name = *req.Name
@@ -238,6 +254,14 @@ func (h *BusHandler) UpdateBus(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityBus,
+		EntityID:   bus.ID.String(),
+		Before:     &before,
+		After:      bus,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Bus updated successfully",
 		"data":    bus,
@@ -266,7 +290,7 @@ func (h *BusHandler) DeleteBus(c *gin.Context) {
 	}
 
 	// Check if bus exists
-	_, err = h.busRepo.GetByID(c.Request.Context(), busID)
+	existing, err := h.busRepo.GetByID(c.Request.Context(), busID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Bus not found",
@@ -283,6 +307,13 @@ func (h *BusHandler) DeleteBus(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntityBus,
+		EntityID:   busID.String(),
+		Before:     existing,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Bus deleted successfully",
 	})
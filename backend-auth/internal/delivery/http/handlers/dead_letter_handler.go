@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// DeadLetterHandler handles admin operations on notifications that exhausted their retries
+type DeadLetterHandler struct {
+	notificationQueue *services.NotificationQueue
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler
+func NewDeadLetterHandler(notificationQueue *services.NotificationQueue) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		notificationQueue: notificationQueue,
+	}
+}
+
+// ListDeadLetters godoc
+// @Summary List dead-lettered notifications
+// @Description Get paginated list of notifications that failed after all retries
+// @Tags admin-notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/notifications/dead-letter [get]
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	notifications, total, err := h.notificationQueue.GetDeadLetters(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get dead-lettered notifications",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        notifications,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// GetDeadLetter godoc
+// @Summary Get dead-lettered notification
+// @Description Inspect a dead-lettered notification including its last error
+// @Tags admin-notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notifications/dead-letter/{id} [get]
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	notification, err := h.notificationQueue.GetDeadLetter(c.Request.Context(), notificationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Dead-lettered notification not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    notification,
+	})
+}
+
+// RetryDeadLetter godoc
+// @Summary Retry dead-lettered notification
+// @Description Reset the retry budget of a dead-lettered notification and enqueue it again
+// @Tags admin-notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/notifications/dead-letter/{id}/retry [post]
+func (h *DeadLetterHandler) RetryDeadLetter(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	notification, err := h.notificationQueue.RetryDeadLetter(c.Request.Context(), notificationID)
+	if err != nil {
+		if containsStr(err.Error(), "not found") || containsStr(err.Error(), "not in dead-letter") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Dead-lettered notification not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retry notification",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification requeued",
+		"data":    notification,
+	})
+}
+
+// DiscardDeadLetter godoc
+// @Summary Discard dead-lettered notification
+// @Description Cancel a dead-lettered notification so it is never retried
+// @Tags admin-notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/notifications/dead-letter/{id} [delete]
+func (h *DeadLetterHandler) DiscardDeadLetter(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.notificationQueue.DiscardDeadLetter(c.Request.Context(), notificationID); err != nil {
+		if containsStr(err.Error(), "not found") || containsStr(err.Error(), "not in dead-letter") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Dead-lettered notification not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to discard notification",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification discarded",
+	})
+}
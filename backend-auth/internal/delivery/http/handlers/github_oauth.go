@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitHubUserInfo represents the GitHub account behind an authorization code
+type GitHubUserInfo struct {
+	ID        string
+	Login     string
+	Name      string
+	Email     string // Primary verified address
+	AvatarURL string
+}
+
+var githubHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// GitHubAuthorizeURL returns the GitHub consent page URL for the configured OAuth app
+func GitHubAuthorizeURL(state string) string {
+	params := url.Values{}
+	params.Set("client_id", os.Getenv("GITHUB_CLIENT_ID"))
+	params.Set("redirect_uri", os.Getenv("GITHUB_REDIRECT_URL"))
+	params.Set("scope", "read:user user:email")
+	if state != "" {
+		params.Set("state", state)
+	}
+	return "https://github.com/login/oauth/authorize?" + params.Encode()
+}
+
+// ExchangeGitHubCode trades an authorization code for the GitHub account it was issued for
+func (h *AuthHandler) ExchangeGitHubCode(ctx context.Context, code string) (*GitHubUserInfo, error) {
+	clientID := os.Getenv("GITHUB_CLIENT_ID")
+	clientSecret := os.Getenv("GITHUB_CLIENT_SECRET")
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("GitHub sign-in is not configured")
+	}
+
+	form := url.Values{}
+	form.Set("client_id", clientID)
+	form.Set("client_secret", clientSecret)
+	form.Set("code", code)
+	if redirectURL := os.Getenv("GITHUB_REDIRECT_URL"); redirectURL != "" {
+		form.Set("redirect_uri", redirectURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://github.com/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := doGitHubRequest(req, &tokenResponse); err != nil {
+		return nil, err
+	}
+	if tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("code exchange failed: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getGitHubAPI(ctx, tokenResponse.AccessToken, "/user", &profile); err != nil {
+		return nil, err
+	}
+
+	// The profile email may be hidden or unverified, so use the primary verified address
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getGitHubAPI(ctx, tokenResponse.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	userInfo := &GitHubUserInfo{
+		ID:        strconv.FormatInt(profile.ID, 10),
+		Login:     profile.Login,
+		Name:      profile.Name,
+		AvatarURL: profile.AvatarURL,
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			userInfo.Email = email.Email
+		}
+	}
+	if userInfo.Email == "" {
+		return nil, fmt.Errorf("no verified primary email on the GitHub account")
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = userInfo.Login
+	}
+
+	return userInfo, nil
+}
+
+func getGitHubAPI(ctx context.Context, accessToken, path string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return doGitHubRequest(req, dest)
+}
+
+func doGitHubRequest(req *http.Request, dest interface{}) error {
+	resp, err := githubHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// GuestLookupRequest identifies a guest booking by its reference and contact email
+type GuestLookupRequest struct {
+	Reference string `json:"reference" binding:"required"`
+	Email     string `json:"email" binding:"required"` // Code is emailed when this matches the booking's contact email
+}
+
+// GuestVerifyRequest represents the request for exchanging a one-time code for an access token
+type GuestVerifyRequest struct {
+	Reference string `json:"reference" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+// RequestGuestAccess sends a one-time code for opening a guest booking
+// @Summary Look up guest booking
+// @Description Email a one-time code to the booking's contact email. The response is the same whether or not the details match a booking.
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param request body GuestLookupRequest true "Booking reference and contact email"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /bookings/guest/lookup [post]
+func (h *BookingHandler) RequestGuestAccess(c *gin.Context) {
+	var req GuestLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.bookingUsecase.RequestGuestAccess(c.Request.Context(), usecases.GuestAccessRequest{
+		Reference: req.Reference,
+		Email:     req.Email,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "If the details match a booking, a code has been sent to its contact email",
+	})
+}
+
+// VerifyGuestAccess exchanges a one-time code for a booking access token
+// @Summary Verify guest booking code
+// @Description Exchange the one-time code for a short-lived token that opens this booking. Send it in the X-Booking-Token header.
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param request body GuestVerifyRequest true "Booking reference and code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /bookings/guest/verify [post]
+func (h *BookingHandler) VerifyGuestAccess(c *gin.Context) {
+	var req GuestVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	access, err := h.bookingUsecase.VerifyGuestAccess(c.Request.Context(), req.Reference, req.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "invalid") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Booking access granted",
+		Data:    access,
+	})
+}
+
+// GetGuestBooking retrieves the booking the access token was issued for
+// @Summary Get guest booking
+// @Description Get booking details with passengers and tickets
+// @Tags Booking
+// @Produce json
+// @Param X-Booking-Token header string true "Booking access token"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/guest/booking [get]
+func (h *BookingHandler) GetGuestBooking(c *gin.Context) {
+	result, err := h.bookingUsecase.GetBookingDetails(c.Request.Context(), guestBookingID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Booking retrieved successfully",
+		Data:    result,
+	})
+}
+
+// DownloadGuestTickets downloads all tickets of the guest booking
+// @Summary Download guest booking tickets
+// @Description Download all e-tickets for the booking as a single PDF
+// @Tags Booking
+// @Produce application/pdf
+// @Param X-Booking-Token header string true "Booking access token"
+// @Success 200 {file} application/pdf
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/guest/booking/tickets/download [get]
+func (h *BookingHandler) DownloadGuestTickets(c *gin.Context) {
+	pdfBytes, filename, err := h.bookingUsecase.GenerateBookingTicketsPDF(c.Request.Context(), guestBookingID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// DownloadGuestTicket downloads one ticket of the guest booking
+// @Summary Download guest booking ticket
+// @Description Download a single e-ticket PDF; the ticket must be on the booking the token was issued for
+// @Tags Booking
+// @Produce application/pdf
+// @Param X-Booking-Token header string true "Booking access token"
+// @Param ticket_id path string true "Ticket ID"
+// @Success 200 {file} application/pdf
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/guest/booking/tickets/{ticket_id}/download [get]
+func (h *BookingHandler) DownloadGuestTicket(c *gin.Context) {
+	ticketID, err := uuid.Parse(c.Param("ticket_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ticket ID"})
+		return
+	}
+
+	bookingID, err := h.bookingUsecase.TicketBookingID(c.Request.Context(), ticketID)
+	if err != nil || bookingID != guestBookingID(c) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "ticket not found"})
+		return
+	}
+
+	pdfBytes, filename, err := h.bookingUsecase.GenerateTicketPDF(c.Request.Context(), ticketID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// ResendGuestTickets resends the guest booking's tickets
+// @Summary Resend guest booking tickets
+// @Description Resend the e-tickets to the booking's contact email
+// @Tags Booking
+// @Produce json
+// @Param X-Booking-Token header string true "Booking access token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /bookings/guest/booking/resend-tickets [post]
+func (h *BookingHandler) ResendGuestTickets(c *gin.Context) {
+	if err := h.bookingUsecase.ResendTicketEmails(c.Request.Context(), guestBookingID(c)); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Tickets resent successfully",
+	})
+}
+
+// UpdateGuestPassenger updates a passenger on the guest booking
+// @Summary Update guest booking passenger
+// @Description Update passenger details on a confirmed booking before departure
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param X-Booking-Token header string true "Booking access token"
+// @Param passenger_id path string true "Passenger ID"
+// @Param request body usecases.UpdatePassengerInfoInput true "Passenger details"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/guest/booking/passengers/{passenger_id} [put]
+func (h *BookingHandler) UpdateGuestPassenger(c *gin.Context) {
+	passengerID, err := uuid.Parse(c.Param("passenger_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid passenger ID"})
+		return
+	}
+
+	var input usecases.UpdatePassengerInfoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if err := h.bookingUsecase.UpdatePassengerInfo(c.Request.Context(), guestBookingID(c), passengerID, input); err != nil {
+		c.JSON(passengerUpdateStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Passenger information updated successfully",
+	})
+}
+
+// CancelGuestBooking cancels the guest booking
+// @Summary Cancel guest booking
+// @Description Cancel the booking the access token was issued for
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param X-Booking-Token header string true "Booking access token"
+// @Param input body object false "Cancellation details"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /bookings/guest/booking/cancel [post]
+func (h *BookingHandler) CancelGuestBooking(c *gin.Context) {
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&input)
+
+	if err := h.bookingUsecase.CancelBooking(c.Request.Context(), guestBookingID(c), input.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Booking cancelled successfully",
+	})
+}
+
+// guestBookingID returns the booking set by RequireBookingAccess
+func guestBookingID(c *gin.Context) uuid.UUID {
+	id, _ := uuid.Parse(c.GetString("booking_id"))
+	return id
+}
@@ -1,5 +1,11 @@
 package handlers
 
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -8,3 +14,23 @@ type SuccessResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+// parsePagination reads page and page_size query params (defaults: 1 and 20, max page size 100)
+func parsePagination(c *gin.Context) (page, pageSize int) {
+	page = 1
+	pageSize = 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if sizeStr := c.Query("page_size"); sizeStr != "" {
+		if s, err := strconv.Atoi(sizeStr); err == nil && s > 0 && s <= 100 {
+			pageSize = s
+		}
+	}
+
+	return page, pageSize
+}
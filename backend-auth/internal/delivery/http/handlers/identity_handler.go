@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// LinkIdentityRequest carries proof of the OAuth account to link
+type LinkIdentityRequest struct {
+	IDToken string `json:"id_token"` // Google ID token
+	Code    string `json:"code"`     // GitHub authorization code
+}
+
+// ListIdentities godoc
+// @Summary List linked sign-in providers
+// @Description Get the OAuth accounts linked to the current user and whether the user has a password
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /profile/identities [get]
+func (h *AuthHandler) ListIdentities(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	linked, err := h.authUsecase.ListIdentities(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Linked accounts retrieved",
+		Data:    linked,
+	})
+}
+
+// LinkIdentity godoc
+// @Summary Link a sign-in provider
+// @Description Link a Google (id_token) or GitHub (code) account to the current user so it can be used to sign in
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (google, github)"
+// @Param request body LinkIdentityRequest true "Provider credential"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /profile/identities/{provider} [post]
+func (h *AuthHandler) LinkIdentity(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	var req LinkIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	input, err := h.verifyOAuthCredential(c, entities.OAuthProvider(c.Param("provider")), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	identity, err := h.authUsecase.LinkIdentity(clientContext(c), *userID, *input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case containsStr(err.Error(), "already linked"):
+			status = http.StatusConflict
+		case containsStr(err.Error(), "invalid"), containsStr(err.Error(), "not found"):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: fmt.Sprintf("%s account linked", identity.Provider),
+		Data:    identity,
+	})
+}
+
+// UnlinkIdentity godoc
+// @Summary Unlink a sign-in provider
+// @Description Remove a linked OAuth account. Not allowed when it is the user's only way to sign in.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (google, github)"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /profile/identities/{provider} [delete]
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	provider := entities.OAuthProvider(c.Param("provider"))
+	if err := h.authUsecase.UnlinkIdentity(clientContext(c), *userID, provider); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case containsStr(err.Error(), "no "+string(provider)):
+			status = http.StatusNotFound
+		case containsStr(err.Error(), "cannot"):
+			status = http.StatusConflict
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: fmt.Sprintf("%s account unlinked", provider),
+	})
+}
+
+// verifyOAuthCredential checks the credential with the provider and returns the identity it proves
+func (h *AuthHandler) verifyOAuthCredential(c *gin.Context, provider entities.OAuthProvider, req LinkIdentityRequest) (*usecases.OAuthUserInput, error) {
+	switch provider {
+	case entities.OAuthProviderGoogle:
+		if req.IDToken == "" {
+			return nil, fmt.Errorf("id_token is required to link a Google account")
+		}
+		googleUser, err := h.VerifyGoogleIDToken(c.Request.Context(), req.IDToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Google token: %v", err)
+		}
+		return &usecases.OAuthUserInput{
+			Email:          googleUser.Email,
+			Name:           googleUser.Name,
+			Provider:       string(provider),
+			ProviderUserID: googleUser.ID,
+			Avatar:         googleUser.Picture,
+		}, nil
+	case entities.OAuthProviderGitHub:
+		if req.Code == "" {
+			return nil, fmt.Errorf("code is required to link a GitHub account")
+		}
+		githubUser, err := h.ExchangeGitHubCode(c.Request.Context(), req.Code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub authorization: %v", err)
+		}
+		return &usecases.OAuthUserInput{
+			Email:          githubUser.Email,
+			Name:           githubUser.Name,
+			Provider:       string(provider),
+			ProviderUserID: githubUser.ID,
+			Avatar:         githubUser.AvatarURL,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", provider)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// JWKSHandler publishes the public keys other services use to verify access tokens
+type JWKSHandler struct {
+	tokenKeys *services.JWTKeySet
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(tokenKeys *services.JWTKeySet) *JWKSHandler {
+	return &JWKSHandler{tokenKeys: tokenKeys}
+}
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens, selected by the token's kid header. Empty when tokens are signed with a shared secret.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// Verifiers may cache the set briefly, so a new key should be published a few minutes before it signs tokens
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokenKeys.JWKS())
+}
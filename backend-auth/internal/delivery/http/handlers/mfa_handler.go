@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // Authenticator code or recovery code
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when a second factor is needed
+type MFAChallengeResponse struct {
+	MFARequired           bool        `json:"mfa_required"`
+	MFAEnrollmentRequired bool        `json:"mfa_enrollment_required"`
+	MFAToken              string      `json:"mfa_token"`
+	User                  interface{} `json:"user"`
+}
+
+// respondMFAChallenge writes the challenge if the login stopped at the second factor
+func (h *AuthHandler) respondMFAChallenge(c *gin.Context, tokens *usecases.AuthTokens) bool {
+	if tokens.MFAToken == "" {
+		return false
+	}
+	c.JSON(http.StatusOK, MFAChallengeResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: tokens.MFAEnrollmentRequired,
+		MFAToken:              tokens.MFAToken,
+		User:                  tokens.User,
+	})
+	return true
+}
+
+// respondLoginThrottled writes a 429 with Retry-After when a sign-in step is throttled
+func respondLoginThrottled(c *gin.Context, err error) bool {
+	throttled, ok := err.(*usecases.LoginThrottledError)
+	if !ok {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: err.Error()})
+	return true
+}
+
+// mfaErrorStatus maps MFA usecase errors to HTTP status codes
+func mfaErrorStatus(err error) int {
+	switch {
+	case containsStr(err.Error(), "failed to"):
+		return http.StatusInternalServerError
+	case containsStr(err.Error(), "invalid or expired MFA token"),
+		containsStr(err.Error(), "too many invalid codes"),
+		containsStr(err.Error(), "invalid two-factor code"):
+		return http.StatusUnauthorized
+	case containsStr(err.Error(), "inactive"):
+		return http.StatusForbidden
+	case containsStr(err.Error(), "already enabled"),
+		containsStr(err.Error(), "cannot be disabled"):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// VerifyMFA godoc
+// @Summary Complete login with a second factor
+// @Description Exchange the MFA token from login and an authenticator or recovery code for session tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} AuthResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tokens, err := h.authUsecase.VerifyMFA(clientContext(c), req.MFAToken, req.Code)
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
+	c.JSON(http.StatusOK, AuthResponse{
+		AccessToken: tokens.AccessToken,
+		User:        tokens.User,
+	})
+}
+
+// StartMFAEnrollment godoc
+// @Summary Start required MFA enrollment
+// @Description Generate an authenticator secret and QR code for an account that must enroll before signing in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFAEnrollRequest true "MFA enrollment token from login"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) StartMFAEnrollment(c *gin.Context) {
+	var req MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	setup, err := h.authUsecase.SetupMFAEnrollment(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Scan the QR code with your authenticator app, then confirm with a code",
+		Data:    setup,
+	})
+}
+
+// ConfirmMFAEnrollment godoc
+// @Summary Confirm required MFA enrollment
+// @Description Enable MFA with a code from the authenticator app and complete the login. Recovery codes are returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "MFA enrollment token and authenticator code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa/enroll/confirm [post]
+func (h *AuthHandler) ConfirmMFAEnrollment(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tokens, recoveryCodes, err := h.authUsecase.ConfirmMFAEnrollment(clientContext(c), req.MFAToken, req.Code)
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.setCrossSiteCookie(c, "refresh_token", tokens.RefreshToken, 7*24*60*60)
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":   tokens.AccessToken,
+		"user":           tokens.User,
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// GetMFAStatus godoc
+// @Summary Get two-factor authentication status
+// @Description Get whether MFA is enabled or required for the current user and how many recovery codes remain
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /profile/mfa [get]
+func (h *AuthHandler) GetMFAStatus(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	status, err := h.authUsecase.GetMFAStatus(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "MFA status retrieved",
+		Data:    status,
+	})
+}
+
+// SetupMFA godoc
+// @Summary Start MFA enrollment
+// @Description Generate a new authenticator secret, provisioning URI and QR code. MFA is enabled once a code is confirmed.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /profile/mfa/setup [post]
+func (h *AuthHandler) SetupMFA(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	setup, err := h.authUsecase.SetupMFA(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Scan the QR code with your authenticator app, then confirm with a code",
+		Data:    setup,
+	})
+}
+
+// EnableMFA godoc
+// @Summary Enable MFA
+// @Description Confirm enrollment with a code from the authenticator app. Recovery codes are returned once.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "Authenticator code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /profile/mfa/enable [post]
+func (h *AuthHandler) EnableMFA(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.authUsecase.EnableMFA(c.Request.Context(), *userID, req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Two-factor authentication enabled, store your recovery codes somewhere safe",
+		Data:    gin.H{"recovery_codes": recoveryCodes},
+	})
+}
+
+// DisableMFA godoc
+// @Summary Disable MFA
+// @Description Turn off two-factor authentication using an authenticator or recovery code. Not allowed when policy requires MFA.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /profile/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.authUsecase.DisableMFA(c.Request.Context(), *userID, req.Code); err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate MFA recovery codes
+// @Description Replace all recovery codes after confirming an authenticator code. Old codes stop working.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "Authenticator code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /profile/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.authUsecase.RegenerateRecoveryCodes(c.Request.Context(), *userID, req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Recovery codes regenerated",
+		Data:    gin.H{"recovery_codes": recoveryCodes},
+	})
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// NotificationTemplateHandler handles admin management of stored notification templates
+type NotificationTemplateHandler struct {
+	templateUsecase *usecases.NotificationTemplateUsecase
+}
+
+// NewNotificationTemplateHandler creates a new notification template handler
+func NewNotificationTemplateHandler(templateUsecase *usecases.NotificationTemplateUsecase) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templateUsecase: templateUsecase,
+	}
+}
+
+// NotificationTemplateRequest represents the request for creating a template version
+type NotificationTemplateRequest struct {
+	Type     string `json:"type" binding:"required"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject" binding:"required"`
+	HTMLBody string `json:"html_body" binding:"required"`
+	TextBody string `json:"text_body"`
+}
+
+// UpdateNotificationTemplateRequest represents the request for editing a template (creates a new version)
+// An omitted text_body keeps the current one; an empty string removes it
+type UpdateNotificationTemplateRequest struct {
+	Subject  string  `json:"subject"`
+	HTMLBody string  `json:"html_body"`
+	TextBody *string `json:"text_body"`
+}
+
+// PreviewNotificationTemplateRequest represents the request for previewing unsaved template content
+// Data optionally overrides fields of the sample data (e.g. {"RecipientName": "Jane"})
+type PreviewNotificationTemplateRequest struct {
+	Type     string          `json:"type" binding:"required"`
+	Subject  string          `json:"subject"`
+	HTMLBody string          `json:"html_body"`
+	TextBody string          `json:"text_body"`
+	Data     json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// PreviewStoredTemplateRequest represents optional sample data overrides for previewing a stored template
+type PreviewStoredTemplateRequest struct {
+	Data json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// ListTemplates godoc
+// @Summary List notification templates
+// @Description Get active stored notification templates, optionally filtered by type
+// @Tags admin-notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Notification type"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/notification-templates [get]
+func (h *NotificationTemplateHandler) ListTemplates(c *gin.Context) {
+	var typeFilter *entities.NotificationType
+	if t := c.Query("type"); t != "" {
+		notifType := entities.NotificationType(t)
+		typeFilter = &notifType
+	}
+
+	templates, err := h.templateUsecase.ListTemplates(c.Request.Context(), typeFilter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    templates,
+		"count":   len(templates),
+	})
+}
+
+// GetTemplate godoc
+// @Summary Get notification template
+// @Description Get a stored notification template version
+// @Tags admin-notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notification-templates/{id} [get]
+func (h *NotificationTemplateHandler) GetTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	template, err := h.templateUsecase.GetTemplate(c.Request.Context(), templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Template not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// GetTemplateVersions godoc
+// @Summary List template versions
+// @Description Get all versions of the template family (same type and locale) as the given template
+// @Tags admin-notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notification-templates/{id}/versions [get]
+func (h *NotificationTemplateHandler) GetTemplateVersions(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	versions, err := h.templateUsecase.GetTemplateVersions(c.Request.Context(), templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Template not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+		"count":   len(versions),
+	})
+}
+
+// CreateTemplate godoc
+// @Summary Create notification template
+// @Description Store a new template version for a notification type and locale and make it active
+// @Tags admin-notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body NotificationTemplateRequest true "Template content"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/notification-templates [post]
+func (h *NotificationTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req NotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	template, err := h.templateUsecase.CreateTemplate(c.Request.Context(), usecases.NotificationTemplateInput{
+		Type:     entities.NotificationType(req.Type),
+		Locale:   req.Locale,
+		Subject:  req.Subject,
+		HTMLBody: req.HTMLBody,
+		TextBody: req.TextBody,
+	}, currentUserID(c))
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to create template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    template,
+		"message": "Template created successfully",
+	})
+}
+
+// UpdateTemplate godoc
+// @Summary Update notification template
+// @Description Create a new active version from an existing template; omitted fields are copied over and an empty text_body removes the plain-text part
+// @Tags admin-notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body UpdateNotificationTemplateRequest true "Template changes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/notification-templates/{id} [put]
+func (h *NotificationTemplateHandler) UpdateTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	var req UpdateNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	template, err := h.templateUsecase.UpdateTemplate(c.Request.Context(), templateID, usecases.NotificationTemplateUpdate{
+		Subject:  req.Subject,
+		HTMLBody: req.HTMLBody,
+		TextBody: req.TextBody,
+	}, currentUserID(c))
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to update template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    template,
+		"message": "Template updated successfully",
+	})
+}
+
+// ActivateTemplate godoc
+// @Summary Activate template version
+// @Description Make a specific template version the active one for its type and locale
+// @Tags admin-notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notification-templates/{id}/activate [post]
+func (h *NotificationTemplateHandler) ActivateTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	template, err := h.templateUsecase.ActivateVersion(c.Request.Context(), templateID)
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to activate template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    template,
+		"message": "Template version activated",
+	})
+}
+
+// DeleteTemplate godoc
+// @Summary Delete notification template
+// @Description Deactivate all versions of a template family so the built-in template is used again
+// @Tags admin-notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notification-templates/{id} [delete]
+func (h *NotificationTemplateHandler) DeleteTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	if err := h.templateUsecase.DeleteTemplate(c.Request.Context(), templateID); err != nil {
+		h.respondWriteError(c, err, "Failed to delete template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Template deactivated, built-in template will be used",
+	})
+}
+
+// PreviewTemplate godoc
+// @Summary Preview template content
+// @Description Render unsaved template content against sample data for its notification type
+// @Tags admin-notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PreviewNotificationTemplateRequest true "Template content and optional sample data overrides"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/notification-templates/preview [post]
+func (h *NotificationTemplateHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rendered, err := h.templateUsecase.PreviewTemplate(&entities.NotificationTemplate{
+		Type:     entities.NotificationType(req.Type),
+		Subject:  req.Subject,
+		HTMLBody: req.HTMLBody,
+		TextBody: req.TextBody,
+	}, req.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to render template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rendered,
+	})
+}
+
+// PreviewStoredTemplate godoc
+// @Summary Preview stored template
+// @Description Render a stored template version against sample data for its notification type
+// @Tags admin-notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body PreviewStoredTemplateRequest false "Optional sample data overrides"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/notification-templates/{id}/preview [post]
+func (h *NotificationTemplateHandler) PreviewStoredTemplate(c *gin.Context) {
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID format"})
+		return
+	}
+
+	// Body is optional
+	var req PreviewStoredTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	rendered, err := h.templateUsecase.PreviewStoredTemplate(c.Request.Context(), templateID, req.Data)
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to render template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rendered,
+	})
+}
+
+// respondWriteError maps template usecase errors to HTTP status codes
+func (h *NotificationTemplateHandler) respondWriteError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case containsStr(err.Error(), "not found"):
+		status = http.StatusNotFound
+	case containsStr(err.Error(), "conflict"):
+		status = http.StatusConflict
+	case containsStr(err.Error(), "invalid"), containsStr(err.Error(), "required"), containsStr(err.Error(), "failed to render"):
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
+
+// currentUserID returns the authenticated user's ID, or nil if unavailable
+func currentUserID(c *gin.Context) *uuid.UUID {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+	str, ok := userIDStr.(string)
+	if !ok {
+		return nil
+	}
+	userID, err := uuid.Parse(str)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// OperatorHandler handles bus operator onboarding
+type OperatorHandler struct {
+	operatorRepo repositories.OperatorRepository
+	auditUsecase *usecases.AuditLogUsecase
+}
+
+// NewOperatorHandler creates a new operator handler
+func NewOperatorHandler(operatorRepo repositories.OperatorRepository, auditUsecase *usecases.AuditLogUsecase) *OperatorHandler {
+	return &OperatorHandler{
+		operatorRepo: operatorRepo,
+		auditUsecase: auditUsecase,
+	}
+}
+
+// CreateOperatorRequest represents the request for onboarding a bus operator
+type CreateOperatorRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Code         string `json:"code" binding:"required,max=20"`
+	ContactEmail string `json:"contact_email" binding:"omitempty,email"`
+	ContactPhone string `json:"contact_phone"`
+}
+
+// UpdateOperatorRequest represents the request for updating a bus operator
+type UpdateOperatorRequest struct {
+	Name         *string `json:"name,omitempty"`
+	ContactEmail *string `json:"contact_email,omitempty" binding:"omitempty,email"`
+	ContactPhone *string `json:"contact_phone,omitempty"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+}
+
+// CreateOperator onboards a new bus operator
+// @Summary Create operator
+// @Description Onboard a new bus operator (platform staff only)
+// @Tags admin,operators
+// @Accept json
+// @Produce json
+// @Param operator body CreateOperatorRequest true "Operator details"
+// @Security BearerAuth
+// @Success 201 {object} map[string]interface{} "Created operator"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Platform staff access required"
+// @Failure 409 {object} map[string]interface{} "Operator code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/operators [post]
+func (h *OperatorHandler) CreateOperator(c *gin.Context) {
+	var req CreateOperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	operator := &entities.Operator{
+		Name:         strings.TrimSpace(req.Name),
+		Code:         strings.ToUpper(strings.TrimSpace(req.Code)),
+		ContactEmail: req.ContactEmail,
+		ContactPhone: req.ContactPhone,
+		IsActive:     true,
+	}
+
+	if err := h.operatorRepo.Create(c.Request.Context(), operator); err != nil {
+		if containsStr(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Operator with this code already exists",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create operator",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityOperator,
+		EntityID:   operator.ID.String(),
+		After:      operator,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Operator created successfully",
+		"data":    operator,
+	})
+}
+
+// ListOperators retrieves all bus operators
+// @Summary List operators
+// @Description Get list of all bus operators (platform staff only)
+// @Tags admin,operators
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of operators"
+// @Failure 403 {object} map[string]interface{} "Platform staff access required"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/operators [get]
+func (h *OperatorHandler) ListOperators(c *gin.Context) {
+	operators, err := h.operatorRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch operators",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": operators,
+	})
+}
+
+// GetOperator retrieves a bus operator by ID
+// @Summary Get operator by ID
+// @Description Get a specific bus operator (platform staff only)
+// @Tags admin,operators
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Operator details"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Operator not found"
+// @Router /admin/operators/{id} [get]
+func (h *OperatorHandler) GetOperator(c *gin.Context) {
+	operatorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid operator ID format",
+		})
+		return
+	}
+
+	operator, err := h.operatorRepo.GetByID(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Operator not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": operator,
+	})
+}
+
+// UpdateOperator updates a bus operator
+// @Summary Update operator
+// @Description Update a bus operator's details or suspend it (platform staff only)
+// @Tags admin,operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param operator body UpdateOperatorRequest true "Updated operator details"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Updated operator"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Operator not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/operators/{id} [put]
+func (h *OperatorHandler) UpdateOperator(c *gin.Context) {
+	operatorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid operator ID format",
+		})
+		return
+	}
+
+	var req UpdateOperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	operator, err := h.operatorRepo.GetByID(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Operator not found",
+		})
+		return
+	}
+
+	before := *operator
+
+	if req.Name != nil {
+		operator.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.ContactEmail != nil {
+		operator.ContactEmail = *req.ContactEmail
+	}
+	if req.ContactPhone != nil {
+		operator.ContactPhone = *req.ContactPhone
+	}
+	if req.IsActive != nil {
+		operator.IsActive = *req.IsActive
+	}
+
+	if err := h.operatorRepo.Update(c.Request.Context(), operator); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update operator",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityOperator,
+		EntityID:   operator.ID.String(),
+		Before:     &before,
+		After:      operator,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Operator updated successfully",
+		"data":    operator,
+	})
+}
+
+// DeleteOperator offboards a bus operator (soft delete)
+// @Summary Delete operator
+// @Description Soft delete a bus operator; its fleet, schedule and bookings are kept (platform staff only)
+// @Tags admin,operators
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 404 {object} map[string]interface{} "Operator not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/operators/{id} [delete]
+func (h *OperatorHandler) DeleteOperator(c *gin.Context) {
+	operatorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid operator ID format",
+		})
+		return
+	}
+
+	existing, err := h.operatorRepo.GetByID(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Operator not found",
+		})
+		return
+	}
+
+	if err := h.operatorRepo.Delete(c.Request.Context(), operatorID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete operator",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntityOperator,
+		EntityID:   operatorID.String(),
+		Before:     existing,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Operator deleted successfully",
+	})
+}
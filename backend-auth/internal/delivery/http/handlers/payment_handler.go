@@ -82,6 +82,13 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	// Call usecase to create payment
 	response, err := h.paymentUsecase.CreatePayment(c.Request.Context(), paymentReq)
 	if err != nil {
+		if containsStr(err.Error(), "not verified") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Email verification required",
+				"details": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create payment",
 			"details": err.Error(),
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// PaymentReconciliationHandler handles admin access to payment reconciliation
+type PaymentReconciliationHandler struct {
+	reconciliationUsecase *usecases.PaymentReconciliationUsecase
+}
+
+// NewPaymentReconciliationHandler creates a new payment reconciliation handler
+func NewPaymentReconciliationHandler(reconciliationUsecase *usecases.PaymentReconciliationUsecase) *PaymentReconciliationHandler {
+	return &PaymentReconciliationHandler{
+		reconciliationUsecase: reconciliationUsecase,
+	}
+}
+
+// GetReport godoc
+// @Summary Get daily payment reconciliation report
+// @Description Get the reconciliation activity for a day as JSON or as a CSV download
+// @Tags admin-payments
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param date query string false "Report date (YYYY-MM-DD), defaults to today"
+// @Param mismatch_only query bool false "Only include mismatches"
+// @Param format query string false "Response format (json or csv)" default(json)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/payments/reconciliation [get]
+func (h *PaymentReconciliationHandler) GetReport(c *gin.Context) {
+	date := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+	mismatchOnly, _ := strconv.ParseBool(c.Query("mismatch_only"))
+
+	report, err := h.reconciliationUsecase.GetDailyReport(c.Request.Context(), date, mismatchOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get reconciliation report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeReconciliationCSV(c, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// RunReconciliation godoc
+// @Summary Run payment reconciliation now
+// @Description Poll the payment provider for all pending payments immediately instead of waiting for the scheduled run
+// @Tags admin-payments
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/payments/reconciliation/run [post]
+func (h *PaymentReconciliationHandler) RunReconciliation(c *gin.Context) {
+	result, err := h.reconciliationUsecase.ReconcilePendingPayments(c.Request.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "already running") {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to run reconciliation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// writeReconciliationCSV streams the report entries as a CSV attachment
+func writeReconciliationCSV(c *gin.Context, report *usecases.ReconciliationReport) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=payment-reconciliation-%s.csv", report.Date))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"checked_at", "payment_id", "booking_id", "order_code", "local_status", "provider_status",
+		"booking_status", "amount", "provider_amount", "action", "mismatch", "note",
+	})
+	for _, entry := range report.Entries {
+		note := ""
+		if entry.Note != nil {
+			note = *entry.Note
+		}
+		w.Write([]string{
+			entry.CheckedAt.Format(time.RFC3339),
+			entry.PaymentID.String(),
+			entry.BookingID.String(),
+			entry.OrderCode,
+			entry.LocalStatus,
+			entry.ProviderStatus,
+			entry.BookingStatus,
+			strconv.FormatFloat(entry.Amount, 'f', 2, 64),
+			strconv.FormatFloat(entry.ProviderAmount, 'f', 2, 64),
+			string(entry.Action),
+			strconv.FormatBool(entry.Mismatch),
+			note,
+		})
+	}
+	w.Flush()
+}
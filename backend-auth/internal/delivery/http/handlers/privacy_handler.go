@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// PrivacyHandler handles personal data export and account deletion
+type PrivacyHandler struct {
+	privacyUsecase *usecases.PrivacyUsecase
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyUsecase *usecases.PrivacyUsecase) *PrivacyHandler {
+	return &PrivacyHandler{privacyUsecase: privacyUsecase}
+}
+
+// ConfirmErasureRequest represents the request for confirming account deletion
+type ConfirmErasureRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ExportData godoc
+// @Summary Download my data
+// @Description Download a ZIP archive of JSON files with the profile, linked accounts, bookings, passengers, payments, reviews and notifications of the current user
+// @Tags profile
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /profile/export [get]
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	export, err := h.privacyUsecase.ExportUserData(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"linked_accounts.json", export.LinkedAccounts},
+		{"notification_preferences.json", export.NotificationPreferences},
+		{"bookings.json", export.Bookings},
+		{"passengers.json", export.Passengers},
+		{"payments.json", export.Payments},
+		{"reviews.json", export.Reviews},
+		{"notifications.json", export.Notifications},
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=my-data-%s.zip", export.ExportedAt.Format("2006-01-02")))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure part-way can only be logged
+	archive := zip.NewWriter(c.Writer)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			log.Printf("[Privacy] Failed to write %s for user %s: %v", file.name, *userID, err)
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			log.Printf("[Privacy] Failed to write %s for user %s: %v", file.name, *userID, err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		log.Printf("[Privacy] Failed to finish export for user %s: %v", *userID, err)
+	}
+}
+
+// GetErasureStatus godoc
+// @Summary Get account deletion status
+// @Description Get the status of the current user's latest account deletion request
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /profile/erasure [get]
+func (h *PrivacyHandler) GetErasureStatus(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	request, err := h.privacyUsecase.GetErasureStatus(c.Request.Context(), *userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// RequestErasure godoc
+// @Summary Request account deletion
+// @Description Email the current user a link to confirm deleting their account. Once confirmed, personal data in the profile, bookings, passengers and reviews is anonymized; payment records are kept.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /profile/erasure [post]
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	request, err := h.privacyUsecase.RequestErasure(clientContext(c), *userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case containsStr(err.Error(), "already in progress"):
+			status = http.StatusConflict
+		case containsStr(err.Error(), "sent recently"):
+			status = http.StatusTooManyRequests
+		case containsStr(err.Error(), "not found"):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    request,
+		"message": "Check your email to confirm deleting your account",
+	})
+}
+
+// ConfirmErasure godoc
+// @Summary Confirm account deletion
+// @Description Confirm account deletion with the token from the email. The account is signed out and deactivated immediately and its personal data erased shortly after.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmErasureRequest true "Confirmation token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/erasure/confirm [post]
+func (h *PrivacyHandler) ConfirmErasure(c *gin.Context) {
+	var req ConfirmErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request, err := h.privacyUsecase.ConfirmErasure(c.Request.Context(), req.Token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "invalid") || containsStr(err.Error(), "expired") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    request,
+		"message": "Your account has been closed and your personal data will be erased shortly",
+	})
+}
@@ -7,17 +7,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
 )
 
 // RouteHandler handles route-related operations
 type RouteHandler struct {
-	routeRepo repositories.RouteRepository
+	routeRepo    repositories.RouteRepository
+	auditUsecase *usecases.AuditLogUsecase
 }
 
 // NewRouteHandler creates a new route handler
-func NewRouteHandler(routeRepo repositories.RouteRepository) *RouteHandler {
+func NewRouteHandler(routeRepo repositories.RouteRepository, auditUsecase *usecases.AuditLogUsecase) *RouteHandler {
 	return &RouteHandler{
-		routeRepo: routeRepo,
+		routeRepo:    routeRepo,
+		auditUsecase: auditUsecase,
 	}
 }
 
@@ -29,6 +32,9 @@ type CreateRouteRequest struct {
 	Distance        *float64 `json:"distance,omitempty"`
 	BasePrice       float64  `json:"basePrice" binding:"required,min=0"`
 	Description     *string  `json:"description,omitempty"`
+
+	// Owning operator; operator staff always create routes for their own operator
+	OperatorID *uuid.UUID `json:"operatorId,omitempty"`
 }
 
 // UpdateRouteRequest represents the request for updating a route
@@ -83,6 +89,7 @@ func (h *RouteHandler) CreateRoute(c *gin.Context) {
 		BasePrice:       req.BasePrice,
 		Description:     req.Description,
 		IsActive:        true,
+		OperatorID:      req.OperatorID,
 	}
 
 	// Save to database
@@ -94,6 +101,13 @@ func (h *RouteHandler) CreateRoute(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityRoute,
+		EntityID:   route.ID.String(),
+		After:      route,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Route created successfully",
 		"data":    route,
@@ -213,6 +227,8 @@ func (h *RouteHandler) UpdateRoute(c *gin.Context) {
 		return
 	}
 
+	before := *route
+
 	// Update fields if provided
 	if req.Origin != nil {
 		route.Origin = *req.Origin
@@ -253,6 +269,14 @@ func (h *RouteHandler) UpdateRoute(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityRoute,
+		EntityID:   route.ID.String(),
+		Before:     &before,
+		After:      route,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Route updated successfully",
 		"data":    route,
@@ -281,7 +305,7 @@ func (h *RouteHandler) DeleteRoute(c *gin.Context) {
 	}
 
 	// Check if route exists
-	_, err = h.routeRepo.GetByID(c.Request.Context(), routeID)
+	existing, err := h.routeRepo.GetByID(c.Request.Context(), routeID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Route not found",
@@ -298,6 +322,13 @@ func (h *RouteHandler) DeleteRoute(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntityRoute,
+		EntityID:   routeID.String(),
+		Before:     existing,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Route deleted successfully",
 	})
@@ -12,12 +12,14 @@ import (
 // RouteStopHandler handles route stop operations
 type RouteStopHandler struct {
 	routeStopUsecase *usecases.RouteStopUsecase
+	auditUsecase     *usecases.AuditLogUsecase
 }
 
 // NewRouteStopHandler creates a new route stop handler
-func NewRouteStopHandler(routeStopUsecase *usecases.RouteStopUsecase) *RouteStopHandler {
+func NewRouteStopHandler(routeStopUsecase *usecases.RouteStopUsecase, auditUsecase *usecases.AuditLogUsecase) *RouteStopHandler {
 	return &RouteStopHandler{
 		routeStopUsecase: routeStopUsecase,
+		auditUsecase:     auditUsecase,
 	}
 }
 
@@ -99,6 +101,13 @@ func (h *RouteStopHandler) CreateStop(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityRouteStop,
+		EntityID:   stop.ID.String(),
+		After:      stop,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Stop created successfully",
@@ -158,6 +167,8 @@ func (h *RouteStopHandler) UpdateStop(c *gin.Context) {
 		return
 	}
 
+	before := *existingStop
+
 	// Update stop fields
 	existingStop.RouteID = routeID
 	existingStop.Name = req.Name
@@ -182,6 +193,14 @@ func (h *RouteStopHandler) UpdateStop(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityRouteStop,
+		EntityID:   existingStop.ID.String(),
+		Before:     &before,
+		After:      existingStop,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Stop updated successfully",
@@ -205,6 +224,14 @@ func (h *RouteStopHandler) UpdateStop(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admin/routes/{id}/stops/{stopId} [delete]
 func (h *RouteStopHandler) DeleteStop(c *gin.Context) {
+	routeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid route ID format",
+		})
+		return
+	}
+
 	stopID, err := uuid.Parse(c.Param("stopId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -213,7 +240,16 @@ func (h *RouteStopHandler) DeleteStop(c *gin.Context) {
 		return
 	}
 
-	if err := h.routeStopUsecase.DeleteStop(c.Request.Context(), stopID); err != nil {
+	existing, err := h.routeStopUsecase.GetStopByID(c.Request.Context(), stopID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stop not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.routeStopUsecase.DeleteStop(c.Request.Context(), routeID, stopID); err != nil {
 		if contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Stop not found",
@@ -237,6 +273,13 @@ func (h *RouteStopHandler) DeleteStop(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntityRouteStop,
+		EntityID:   stopID.String(),
+		Before:     existing,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Stop deleted successfully",
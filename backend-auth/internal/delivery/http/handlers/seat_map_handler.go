@@ -12,12 +12,14 @@ import (
 // SeatMapHandler handles seat map configuration endpoints
 type SeatMapHandler struct {
 	seatMapUsecase *usecases.SeatMapUsecase
+	auditUsecase   *usecases.AuditLogUsecase
 }
 
 // NewSeatMapHandler creates a new seat map handler
-func NewSeatMapHandler(seatMapUsecase *usecases.SeatMapUsecase) *SeatMapHandler {
+func NewSeatMapHandler(seatMapUsecase *usecases.SeatMapUsecase, auditUsecase *usecases.AuditLogUsecase) *SeatMapHandler {
 	return &SeatMapHandler{
 		seatMapUsecase: seatMapUsecase,
+		auditUsecase:   auditUsecase,
 	}
 }
 
@@ -28,6 +30,9 @@ type CreateSeatMapRequest struct {
 	Rows        int     `json:"rows" binding:"required,min=1,max=20"`
 	Columns     int     `json:"columns" binding:"required,min=2,max=6"`
 	BusType     string  `json:"bus_type" binding:"required"`
+
+	// Owning operator; operator staff always create seat maps for their own operator
+	OperatorID *uuid.UUID `json:"operator_id,omitempty"`
 }
 
 // CreateSeatMap creates a new seat map template
@@ -59,6 +64,7 @@ func (h *SeatMapHandler) CreateSeatMap(c *gin.Context) {
 		Rows:        req.Rows,
 		Columns:     req.Columns,
 		BusType:     req.BusType,
+		OperatorID:  req.OperatorID,
 	}
 
 	seatMap, err := h.seatMapUsecase.CreateSeatMap(c.Request.Context(), input)
@@ -70,6 +76,13 @@ func (h *SeatMapHandler) CreateSeatMap(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntitySeatMap,
+		EntityID:   seatMap.ID.String(),
+		After:      seatMap,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"data":    seatMap,
@@ -190,6 +203,7 @@ func (h *SeatMapHandler) UpdateSeatMap(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
+	before, _ := h.seatMapUsecase.GetSeatMap(c.Request.Context(), id)
 	seatMap, err := h.seatMapUsecase.UpdateSeatMap(c.Request.Context(), id, input)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -199,6 +213,14 @@ func (h *SeatMapHandler) UpdateSeatMap(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntitySeatMap,
+		EntityID:   id.String(),
+		Before:     before,
+		After:      seatMap,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    seatMap,
@@ -227,6 +249,8 @@ func (h *SeatMapHandler) DeleteSeatMap(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.seatMapUsecase.GetSeatMap(c.Request.Context(), id)
+
 	if err := h.seatMapUsecase.DeleteSeatMap(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete seat map",
@@ -235,6 +259,13 @@ func (h *SeatMapHandler) DeleteSeatMap(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDelete,
+		EntityType: entities.AuditEntitySeatMap,
+		EntityID:   id.String(),
+		Before:     before,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Seat map deleted successfully",
@@ -309,6 +340,7 @@ func (h *SeatMapHandler) BulkUpdateSeats(c *gin.Context) {
 		SeatUpdates: updates,
 	}
 
+	before, _ := h.seatMapUsecase.GetSeatMap(c.Request.Context(), seatMapID)
 	seatMap, err := h.seatMapUsecase.BulkUpdateSeats(c.Request.Context(), seatMapID, input)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -318,6 +350,14 @@ func (h *SeatMapHandler) BulkUpdateSeats(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdateSeats,
+		EntityType: entities.AuditEntitySeatMap,
+		EntityID:   seatMapID.String(),
+		Before:     before,
+		After:      seatMap,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    seatMap,
@@ -363,6 +403,7 @@ func (h *SeatMapHandler) RegenerateSeatLayout(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.seatMapUsecase.GetSeatMap(c.Request.Context(), id)
 	seatMap, err := h.seatMapUsecase.RegenerateSeatLayout(c.Request.Context(), id, req.Rows, req.Columns)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -372,6 +413,14 @@ func (h *SeatMapHandler) RegenerateSeatLayout(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionRegenerate,
+		EntityType: entities.AuditEntitySeatMap,
+		EntityID:   id.String(),
+		Before:     before,
+		After:      seatMap,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    seatMap,
@@ -424,6 +473,7 @@ func (h *SeatMapHandler) AssignSeatMapToBus(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.seatMapUsecase.GetBus(c.Request.Context(), busID)
 	bus, err := h.seatMapUsecase.AssignSeatMapToBus(c.Request.Context(), busID, seatMapID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -433,6 +483,14 @@ func (h *SeatMapHandler) AssignSeatMapToBus(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionAssignSeatMap,
+		EntityType: entities.AuditEntityBus,
+		EntityID:   busID.String(),
+		Before:     before,
+		After:      bus,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Seat map assigned to bus successfully",
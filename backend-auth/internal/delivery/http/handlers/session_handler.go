@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// clientContext returns the request context carrying the caller's IP and user agent for session tracking
+func clientContext(c *gin.Context) context.Context {
+	return usecases.WithClientInfo(c.Request.Context(), usecases.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// currentSessionID returns the session of the access token, if it carries one
+func currentSessionID(c *gin.Context) *uuid.UUID {
+	value, exists := c.Get("session_id")
+	if !exists {
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	sessionID, err := uuid.Parse(str)
+	if err != nil {
+		return nil
+	}
+	return &sessionID
+}
+
+// ListSessions godoc
+// @Summary List signed-in devices
+// @Description Get the current user's active sessions with device, IP address and last activity
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /profile/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	sessions, err := h.authUsecase.ListSessions(c.Request.Context(), *userID, currentSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Sessions retrieved",
+		Data:    sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary Sign out a device
+// @Description Revoke one of the current user's sessions. The device must log in again once its access token expires.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /profile/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not authenticated"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid session ID"})
+		return
+	}
+
+	if err := h.authUsecase.RevokeSession(c.Request.Context(), *userID, sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if containsStr(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Signing out this device also drops its refresh cookie
+	if current := currentSessionID(c); current != nil && *current == sessionID {
+		h.clearCrossSiteCookie(c, "refresh_token")
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Session signed out",
+	})
+}
@@ -8,18 +8,20 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
 	"github.com/yourusername/bus-booking-auth/internal/usecases"
 )
 
 // TripHandler handles public trip search endpoints
 type TripHandler struct {
-	tripUsecase *usecases.TripUsecase
+	tripUsecase  *usecases.TripUsecase
+	auditUsecase *usecases.AuditLogUsecase
 }
 
 // NewTripHandler creates a new TripHandler
-func NewTripHandler(tu *usecases.TripUsecase) *TripHandler {
-	return &TripHandler{tripUsecase: tu}
+func NewTripHandler(tu *usecases.TripUsecase, auditUsecase *usecases.AuditLogUsecase) *TripHandler {
+	return &TripHandler{tripUsecase: tu, auditUsecase: auditUsecase}
 }
 
 // SearchTrips handles GET /api/v1/trips/search
@@ -179,7 +181,7 @@ func (h *TripHandler) GetTripByID(c *gin.Context) {
 		return
 	}
 	
-	trip, err := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+	trip, err := h.tripUsecase.ViewTrip(c.Request.Context(), tripID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "Trip not found",
@@ -249,6 +251,7 @@ type UpdateTripStatusRequest struct {
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Router /admin/trips/{id}/status [put]
 func (h *TripHandler) UpdateTripStatus(c *gin.Context) {
 	tripIDStr := c.Param("id")
@@ -274,6 +277,18 @@ func (h *TripHandler) UpdateTripStatus(c *gin.Context) {
 		return
 	}
 
+	tripID, err := uuid.Parse(tripIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID format"})
+		return
+	}
+	trip, err := h.tripUsecase.GetTripByID(c.Request.Context(), tripID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		return
+	}
+	before := *trip
+
 	if err := h.tripUsecase.UpdateTripStatus(c.Request.Context(), tripIDStr, req.Status); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update trip status",
@@ -281,6 +296,15 @@ func (h *TripHandler) UpdateTripStatus(c *gin.Context) {
 		})
 		return
 	}
+	trip.Status = entities.TripStatus(req.Status)
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityTrip,
+		EntityID:   tripID.String(),
+		Before:     &before,
+		After:      trip,
+	})
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -6,19 +6,22 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
 	"github.com/yourusername/bus-booking-auth/internal/usecases"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserManagementHandler handles admin user management operations
 type UserManagementHandler struct {
-	authUsecase *usecases.AuthUsecase
+	authUsecase  *usecases.AuthUsecase
+	auditUsecase *usecases.AuditLogUsecase
 }
 
 // NewUserManagementHandler creates a new user management handler
-func NewUserManagementHandler(authUsecase *usecases.AuthUsecase) *UserManagementHandler {
+func NewUserManagementHandler(authUsecase *usecases.AuthUsecase, auditUsecase *usecases.AuditLogUsecase) *UserManagementHandler {
 	return &UserManagementHandler{
-		authUsecase: authUsecase,
+		authUsecase:  authUsecase,
+		auditUsecase: auditUsecase,
 	}
 }
 
@@ -28,6 +31,36 @@ type CreateAdminRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 	Phone    string `json:"phone"`
+	Role     string `json:"role"` // Staff role to create, defaults to admin
+
+	// Operator the account works for; ignored for operator admins, who always create staff for their own operator
+	OperatorID *uuid.UUID `json:"operator_id,omitempty"`
+}
+
+// AssignRoleRequest represents the request for changing a user's role
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// AssignOperatorRequest represents the request for moving a staff account to an operator
+type AssignOperatorRequest struct {
+	OperatorID *uuid.UUID `json:"operator_id"` // null moves the account to the platform
+}
+
+// parseRole accepts any assignable role, plus "user" as an alias for passenger
+func parseRole(value string) (entities.Role, bool) {
+	if value == "user" {
+		return entities.RolePassenger, true
+	}
+	role := entities.Role(value)
+	return role, role.IsValid()
+}
+
+// callerCan reports whether the authenticated user's role grants a permission
+func callerCan(c *gin.Context, permission entities.Permission) bool {
+	role, _ := c.Get("user_role")
+	roleName, _ := role.(string)
+	return entities.Role(roleName).HasPermission(permission)
 }
 
 // UpdateUserRequest represents the request for updating a user
@@ -44,7 +77,7 @@ type UpdateUserRequest struct {
 // @Tags admin-users
 // @Produce json
 // @Security BearerAuth
-// @Param role query string false "Filter by role: admin, user, or empty for all"
+// @Param role query string false "Filter by role (admin, operator_staff, conductor, support_agent, passenger or user), empty for all"
 // @Success 200 {object} map[string]interface{}
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -60,15 +93,10 @@ func (h *UserManagementHandler) ListAdmins(c *gin.Context) {
 		users, err = h.authUsecase.GetAllUsers(c.Request.Context())
 	} else {
 		// Filter by specific role
-		var role entities.Role
-		switch roleFilter {
-		case "admin":
-			role = entities.RoleAdmin
-		case "user":
-			role = entities.RolePassenger
-		default:
+		role, ok := parseRole(roleFilter)
+		if !ok {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid role. Must be 'admin' or 'user'",
+				"error": "Invalid role",
 			})
 			return
 		}
@@ -91,8 +119,8 @@ func (h *UserManagementHandler) ListAdmins(c *gin.Context) {
 }
 
 // CreateAdmin godoc
-// @Summary Create admin user
-// @Description Create a new admin user account
+// @Summary Create admin or staff user
+// @Description Create a new admin or back-office staff account (operator_staff, conductor, support_agent). The role defaults to admin.
 // @Tags admin-users
 // @Accept json
 // @Produce json
@@ -113,13 +141,33 @@ func (h *UserManagementHandler) CreateAdmin(c *gin.Context) {
 		return
 	}
 
+	role := entities.RoleAdmin
+	if req.Role != "" {
+		role = entities.Role(req.Role)
+		if !role.IsStaff() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid role. Must be a staff role",
+			})
+			return
+		}
+	}
+
 	user, err := h.authUsecase.CreateAdminUser(c.Request.Context(), usecases.CreateAdminInput{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
 		Phone:    req.Phone,
+		Role:     role,
+
+		OperatorID: req.OperatorID,
 	})
 	if err != nil {
+		if containsStr(err.Error(), "operator not found") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Operator not found",
+			})
+			return
+		}
 		// Check for duplicate email
 		if containsStr(err.Error(), "already exists") || containsStr(err.Error(), "duplicate") {
 			c.JSON(http.StatusConflict, gin.H{
@@ -135,10 +183,17 @@ func (h *UserManagementHandler) CreateAdmin(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionCreate,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   user.ID.String(),
+		After:      user,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"data":    user,
-		"message": "Admin user created successfully",
+		"message": "Staff user created successfully",
 	})
 }
 
@@ -180,7 +235,7 @@ func (h *UserManagementHandler) GetUser(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update an existing user's information
+// @Description Update an existing user's information. Changing the role requires the roles.manage permission.
 // @Tags admin-users
 // @Accept json
 // @Produce json
@@ -189,6 +244,7 @@ func (h *UserManagementHandler) GetUser(c *gin.Context) {
 // @Param request body UpdateUserRequest true "Updated user data"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /admin/users/{id} [put]
 func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
@@ -210,29 +266,37 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Validate role if provided
-	var rolePtr *entities.Role
+	before, _ := h.authUsecase.GetUserByID(c.Request.Context(), userID)
+
+	// Role changes go through the same checks as the role assignment endpoint
 	if req.Role != nil {
-		var role entities.Role
-		switch *req.Role {
-		case "admin":
-			role = entities.RoleAdmin
-		case "user":
-			role = entities.RolePassenger
-		default:
+		role, ok := parseRole(*req.Role)
+		if !ok {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid role. Must be 'admin' or 'user'",
+				"error": "Invalid role",
+			})
+			return
+		}
+		if !callerCan(c, entities.PermRolesManage) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":               "Insufficient permissions",
+				"required_permission": entities.PermRolesManage,
+			})
+			return
+		}
+		if _, err := h.authUsecase.AssignRole(c.Request.Context(), userID, role, currentUserID(c)); err != nil {
+			c.JSON(assignRoleErrorStatus(err), gin.H{
+				"error":   "Failed to update role",
+				"details": err.Error(),
 			})
 			return
 		}
-		rolePtr = &role
 	}
 
 	user, err := h.authUsecase.UpdateUser(c.Request.Context(), userID, usecases.UpdateUserInput{
 		Name:     req.Name,
 		Phone:    req.Phone,
 		IsActive: req.IsActive,
-		Role:     rolePtr,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -242,6 +306,14 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUpdate,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   userID.String(),
+		Before:     before,
+		After:      user,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    user,
@@ -283,6 +355,8 @@ func (h *UserManagementHandler) DeactivateUser(c *gin.Context) {
 		}
 	}
 
+	before, _ := h.authUsecase.GetUserByID(c.Request.Context(), userID)
+
 	if err := h.authUsecase.DeactivateUser(c.Request.Context(), userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to deactivate user",
@@ -291,12 +365,306 @@ func (h *UserManagementHandler) DeactivateUser(c *gin.Context) {
 		return
 	}
 
+	after, _ := h.authUsecase.GetUserByID(c.Request.Context(), userID)
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionDeactivate,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   userID.String(),
+		Before:     before,
+		After:      after,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "User deactivated successfully",
 	})
 }
 
+// GetLockoutStatus godoc
+// @Summary Get login lockout status
+// @Description Get whether a user's account is locked after failed logins and the current failure count
+// @Tags admin-users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/lockout [get]
+func (h *UserManagementHandler) GetLockoutStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	status, err := h.authUsecase.GetLoginLockStatus(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// UnlockUser godoc
+// @Summary Unlock user account
+// @Description Lift a login lockout and clear the failed attempt history of a user's account
+// @Tags admin-users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *UserManagementHandler) UnlockUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	before, _ := h.authUsecase.GetLoginLockStatus(c.Request.Context(), userID)
+
+	if err := h.authUsecase.UnlockAccount(c.Request.Context(), userID, currentUserID(c)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	after, _ := h.authUsecase.GetLoginLockStatus(c.Request.Context(), userID)
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionUnlock,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   userID.String(),
+		Before:     before,
+		After:      after,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User account unlocked successfully",
+	})
+}
+
+// ListSecurityEvents godoc
+// @Summary List account security events
+// @Description Get the audit trail of account lockouts, IP blocks, admin unlocks and role changes
+// @Tags admin-users
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "User ID"
+// @Param email query string false "Email address"
+// @Param event_type query string false "Event type (account_locked, ip_blocked, account_unlocked, role_changed, operator_changed)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/security-events [get]
+func (h *UserManagementHandler) ListSecurityEvents(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	filter := repositories.SecurityEventFilter{
+		Email:     c.Query("email"),
+		EventType: c.Query("event_type"),
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	events, total, err := h.authUsecase.ListSecurityEvents(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get security events",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        events,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description Get every assignable role and the permissions it grants
+// @Tags admin-users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/roles [get]
+func (h *UserManagementHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        h.authUsecase.ListRoles(),
+		"permissions": entities.AllPermissions(),
+	})
+}
+
+// AssignRole godoc
+// @Summary Assign role
+// @Description Change a user's role. The user is signed out of every device so the new permissions apply on their next login.
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body AssignRoleRequest true "Role to assign"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *UserManagementHandler) AssignRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, ok := parseRole(req.Role)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid role",
+		})
+		return
+	}
+
+	before, _ := h.authUsecase.GetUserByID(c.Request.Context(), userID)
+
+	user, err := h.authUsecase.AssignRole(c.Request.Context(), userID, role, currentUserID(c))
+	if err != nil {
+		c.JSON(assignRoleErrorStatus(err), gin.H{
+			"error":   "Failed to assign role",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionAssignRole,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   userID.String(),
+		Before:     before,
+		After:      user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+		"message": "Role assigned successfully",
+	})
+}
+
+// AssignOperator godoc
+// @Summary Assign operator
+// @Description Move a staff account to a bus operator, or back to the platform with a null operator_id. The user is signed out of every device so the new scope applies on their next login.
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body AssignOperatorRequest true "Operator to assign"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/users/{id}/operator [put]
+func (h *UserManagementHandler) AssignOperator(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	var req AssignOperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	before, _ := h.authUsecase.GetUserByID(c.Request.Context(), userID)
+
+	user, err := h.authUsecase.AssignOperator(c.Request.Context(), userID, req.OperatorID, currentUserID(c))
+	if err != nil {
+		c.JSON(assignRoleErrorStatus(err), gin.H{
+			"error":   "Failed to assign operator",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, h.auditUsecase, usecases.AuditEntry{
+		Action:     entities.AuditActionAssignOperator,
+		EntityType: entities.AuditEntityUser,
+		EntityID:   userID.String(),
+		Before:     before,
+		After:      user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+		"message": "Operator assigned successfully",
+	})
+}
+
+// assignRoleErrorStatus maps role assignment errors to HTTP status codes
+func assignRoleErrorStatus(err error) int {
+	switch {
+	case containsStr(err.Error(), "user not found"), containsStr(err.Error(), "operator not found"):
+		return http.StatusNotFound
+	case containsStr(err.Error(), "invalid role"):
+		return http.StatusBadRequest
+	case containsStr(err.Error(), "cannot"), containsStr(err.Error(), "inactive"):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // Helper function to check if string contains substring
 func containsStr(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// WebhookLogHandler handles admin inspection and replay of payment webhook logs
+type WebhookLogHandler struct {
+	paymentUsecase *usecases.PaymentUsecase
+}
+
+// NewWebhookLogHandler creates a new webhook log handler
+func NewWebhookLogHandler(paymentUsecase *usecases.PaymentUsecase) *WebhookLogHandler {
+	return &WebhookLogHandler{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+// ListWebhookLogs godoc
+// @Summary List payment webhook logs
+// @Description Get paginated webhook logs filtered by processing status, payment or event type
+// @Tags admin-payments
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Processing status (pending, processed, failed, duplicate, rejected)"
+// @Param payment_id query string false "Payment ID"
+// @Param external_payment_id query string false "Gateway order code"
+// @Param event_type query string false "Event type"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/payments/webhooks [get]
+func (h *WebhookLogHandler) ListWebhookLogs(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	filter := repositories.WebhookLogFilter{
+		Status:            c.Query("status"),
+		ExternalPaymentID: c.Query("external_payment_id"),
+		EventType:         c.Query("event_type"),
+	}
+	if paymentIDStr := c.Query("payment_id"); paymentIDStr != "" {
+		paymentID, err := uuid.Parse(paymentIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+			return
+		}
+		filter.PaymentID = &paymentID
+	}
+
+	logs, total, err := h.paymentUsecase.ListWebhookLogs(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get webhook logs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        logs,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (int(total) + pageSize - 1) / pageSize,
+	})
+}
+
+// GetWebhookLog godoc
+// @Summary Get payment webhook log
+// @Description Inspect a webhook log including its raw payload and last error
+// @Tags admin-payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook log ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/payments/webhooks/{id} [get]
+func (h *WebhookLogHandler) GetWebhookLog(c *gin.Context) {
+	logID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook log ID"})
+		return
+	}
+
+	webhookLog, err := h.paymentUsecase.GetWebhookLog(c.Request.Context(), logID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Webhook log not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhookLog,
+	})
+}
+
+// ReplayWebhookLog godoc
+// @Summary Replay payment webhook
+// @Description Re-run a stored webhook payload through payment processing. Logs rejected for a bad signature cannot be replayed; logs that never reached the signature check are verified first.
+// @Tags admin-payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook log ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /admin/payments/webhooks/{id}/replay [post]
+func (h *WebhookLogHandler) ReplayWebhookLog(c *gin.Context) {
+	logID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook log ID"})
+		return
+	}
+
+	webhookLog, err := h.paymentUsecase.ReplayWebhook(c.Request.Context(), logID)
+	if err != nil {
+		if containsStr(err.Error(), "cannot be replayed") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Webhook cannot be replayed",
+				"details": err.Error(),
+			})
+			return
+		}
+		if webhookLog == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Webhook log not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		// Processing ran but failed; the log carries the error and is scheduled for retry
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Webhook replay failed",
+			"details": err.Error(),
+			"data":    webhookLog,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook replayed",
+		"data":    webhookLog,
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// APIKeyHeader carries a partner API key
+const APIKeyHeader = "X-API-Key"
+
+// RequireAPIKey authenticates partner requests by their X-API-Key header
+// The key must grant the scope, be used from an allowed address and stay within its rate limit
+func RequireAPIKey(apiKeys *usecases.APIKeyUsecase, scope entities.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := strings.TrimSpace(c.GetHeader(APIKeyHeader))
+		if rawKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			c.Abort()
+			return
+		}
+
+		key, err := apiKeys.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+		if err != nil {
+			status := http.StatusUnauthorized
+			if strings.Contains(err.Error(), "IP address") {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":          "API key does not grant this scope",
+				"required_scope": scope,
+			})
+			c.Abort()
+			return
+		}
+
+		if !writeRateLimitHeaders(c, apiKeys.CheckRateLimit(c.Request.Context(), key)) {
+			return
+		}
+
+		c.Set("api_key_id", key.ID.String())
+		c.Set("api_key_partner", key.PartnerName)
+		c.Next()
+	}
+}
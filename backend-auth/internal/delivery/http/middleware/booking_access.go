@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// BookingTokenHeader carries a booking-scoped guest access token
+const BookingTokenHeader = "X-Booking-Token"
+
+// RequireBookingAccess authenticates guest requests by their X-Booking-Token header
+// The token only opens the booking it was issued for, which is set as "booking_id", and only for routes in its scope
+func RequireBookingAccess(bookings *usecases.BookingUsecase, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSpace(c.GetHeader(BookingTokenHeader))
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Booking access token required"})
+			c.Abort()
+			return
+		}
+
+		bookingID, err := bookings.ParseBookingAccessToken(c.Request.Context(), token, scope)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if strings.Contains(err.Error(), "does not allow") {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("booking_id", bookingID.String())
+		c.Next()
+	}
+}
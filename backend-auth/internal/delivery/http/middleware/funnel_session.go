@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/bus-booking-auth/internal/usecases"
+)
+
+// FunnelSessionHeader carries the visitor session that booking funnel events are keyed by
+const FunnelSessionHeader = "X-Session-ID"
+
+// maxFunnelSessionLength matches the funnel_events.session_id column
+const maxFunnelSessionLength = 64
+
+// FunnelSession attributes the request to the visitor session in its X-Session-ID header
+// Requests without one, or with an oversized one, are simply not tracked
+func FunnelSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := strings.TrimSpace(c.GetHeader(FunnelSessionHeader))
+		if sessionID != "" && len(sessionID) <= maxFunnelSessionLength {
+			c.Request = c.Request.WithContext(usecases.WithFunnelSession(c.Request.Context(), sessionID))
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"os"
@@ -8,10 +9,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
 )
 
-// AuthMiddleware validates JWT token
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// SessionChecker reports whether the session an access token was issued for is still signed in
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID uuid.UUID) bool
+}
+
+// AuthMiddleware validates JWT access tokens against the key set
+// Tokens carrying a session (sid) are rejected once that session is revoked; tokens issued
+// before sessions existed stay valid until they expire (JWT_ACCESS_EXPIRY)
+func AuthMiddleware(tokenKeys *services.JWTKeySet, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -30,14 +43,8 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		// Parse and validate JWT token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		// Parse and validate JWT token; the kid header selects the verification key
+		token, err := tokenKeys.Parse(tokenString)
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
@@ -50,6 +57,11 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if tokenType, _ := claims["typ"].(string); tokenType == "refresh" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh tokens cannot be used for API access"})
+			c.Abort()
+			return
+		}
 
 		// Extract user info from claims
 		userID, ok := claims["user_id"].(string)
@@ -66,6 +78,27 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Set("user_id", userID)
 		c.Set("user_role", role)
 		c.Set("user_email", email)
+		if sessionClaim, ok := claims["sid"].(string); ok {
+			sessionID, err := uuid.Parse(sessionClaim)
+			if err != nil || !sessions.SessionActive(c.Request.Context(), sessionID) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been signed out"})
+				c.Abort()
+				return
+			}
+			c.Set("session_id", sessionClaim)
+		}
+
+		// Operator staff: every repository query of this request is limited to their company
+		if operatorClaim, ok := claims["operator_id"].(string); ok {
+			operatorID, err := uuid.Parse(operatorClaim)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid operator in token"})
+				c.Abort()
+				return
+			}
+			c.Set("operator_id", operatorClaim)
+			c.Request = c.Request.WithContext(repositories.WithOperatorScope(c.Request.Context(), operatorID))
+		}
 
 		c.Next()
 	}
@@ -97,6 +130,75 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 	}
 }
 
+// RequirePermission checks that the user's role grants every listed permission
+func RequirePermission(permissions ...entities.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("user_role")
+		roleName, _ := role.(string)
+		for _, permission := range permissions {
+			if !entities.Role(roleName).HasPermission(permission) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":               "Insufficient permissions",
+					"required_permission": permission,
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireStaff checks that the user has a back-office role
+func RequireStaff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("user_role")
+		roleName, _ := role.(string)
+		if !entities.Role(roleName).IsStaff() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Staff access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePlatformStaff rejects operator-scoped staff from platform-wide settings
+func RequirePlatformStaff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, scoped := c.Get("operator_id"); scoped {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Platform staff access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// OperatorFilter lets platform staff narrow a request to one operator with ?operator_id=
+// Operator staff are already scoped by their token and cannot switch operators
+func OperatorFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.Query("operator_id")
+		if value == "" {
+			c.Next()
+			return
+		}
+		if _, scoped := c.Get("operator_id"); scoped {
+			c.Next()
+			return
+		}
+		operatorID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operator ID format"})
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(repositories.WithOperatorScope(c.Request.Context(), operatorID))
+		c.Next()
+	}
+}
+
 // CORS middleware
 func CORS() gin.HandlerFunc {
 	// Read allowed origins from env var CORS_ALLOWED_ORIGINS (comma-separated)
@@ -148,8 +250,9 @@ func CORS() gin.HandlerFunc {
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Booking-Token, X-Session-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// RateLimit applies a token bucket policy to every request of a route group
+// Clients are identified by their partner API key, then their user ID, then their IP address,
+// so the middleware limits per user only when it runs after AuthMiddleware
+func RateLimit(limiter *services.RateLimiter, policy services.RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := limiter.Allow(c.Request.Context(), policy, rateLimitSubject(c))
+		if !writeRateLimitHeaders(c, result) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitSubject returns the most specific identity known for the caller
+// Anonymous callers are keyed by ClientIP, which only follows X-Forwarded-For from TRUSTED_PROXIES
+func rateLimitSubject(c *gin.Context) string {
+	if keyID := c.GetString("api_key_id"); keyID != "" {
+		return "key:" + keyID
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// writeRateLimitHeaders sets the RateLimit-* headers and rejects the request when it is over the limit
+// It returns false when the request was rejected
+func writeRateLimitHeaders(c *gin.Context, result services.RateLimitResult) bool {
+	policy := result.Policy
+	if policy.Enabled() {
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d;policy=%q", policy.PerMinute, policy.Burst, policy.Name))
+	}
+	if result.Allowed {
+		return true
+	}
+
+	retryAfter := ceilSeconds(result.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many requests",
+		"details":     fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter),
+		"retry_after": retryAfter,
+	})
+	c.Abort()
+	return false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -28,16 +28,43 @@ func (BookingAnalytics) TableName() string {
 	return "booking_analytics"
 }
 
+// OperatorBookingAnalytics holds the same daily booking metrics broken down per operator
+type OperatorBookingAnalytics struct {
+	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OperatorID          uuid.UUID `json:"operator_id" gorm:"type:uuid;not null;uniqueIndex:idx_operator_analytics_date"`
+	Date                time.Time `json:"date" gorm:"not null;uniqueIndex:idx_operator_analytics_date"`
+	TotalBookings       int       `json:"total_bookings" gorm:"default:0"`
+	ConfirmedBookings   int       `json:"confirmed_bookings" gorm:"default:0"`
+	CancelledBookings   int       `json:"cancelled_bookings" gorm:"default:0"`
+	PendingBookings     int       `json:"pending_bookings" gorm:"default:0"`
+	TotalRevenue        float64   `json:"total_revenue" gorm:"default:0"`
+	TotalSeatsBooked    int       `json:"total_seats_booked" gorm:"default:0"`
+	AverageBookingValue float64   `json:"average_booking_value" gorm:"default:0"`
+	ConversionRate      float64   `json:"conversion_rate" gorm:"default:0"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name
+func (OperatorBookingAnalytics) TableName() string {
+	return "operator_booking_analytics"
+}
+
 // RouteAnalytics tracks performance metrics per route
+// Each row covers the trips that departed on Date, so occupancy and ratings belong to the same departures
 type RouteAnalytics struct {
 	ID                   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	RouteID              uuid.UUID `json:"route_id" gorm:"type:uuid;not null;index"`
-	Date                 time.Time `json:"date" gorm:"not null;index"`
+	RouteID              uuid.UUID `json:"route_id" gorm:"type:uuid;not null;uniqueIndex:idx_route_analytics_route_date"`
+	Date                 time.Time `json:"date" gorm:"not null;index;uniqueIndex:idx_route_analytics_route_date"`
+	TotalTrips           int       `json:"total_trips" gorm:"default:0"`
 	TotalBookings        int       `json:"total_bookings" gorm:"default:0"`
+	CancelledBookings    int       `json:"cancelled_bookings" gorm:"default:0"`
 	TotalRevenue         float64   `json:"total_revenue" gorm:"default:0"`
 	AverageOccupancyRate float64   `json:"average_occupancy_rate" gorm:"default:0"` // % of seats filled
 	TotalSeatsBooked     int       `json:"total_seats_booked" gorm:"default:0"`
 	TotalSeatsAvailable  int       `json:"total_seats_available" gorm:"default:0"`
+	AverageRating        float64   `json:"average_rating" gorm:"default:0"`
+	RatingCount          int       `json:"rating_count" gorm:"default:0"` // Reviews behind AverageRating
 	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
@@ -49,3 +76,165 @@ type RouteAnalytics struct {
 func (RouteAnalytics) TableName() string {
 	return "route_analytics"
 }
+
+// RouteDepartureStats aggregates the trips of a route that leave at the same time of day
+type RouteDepartureStats struct {
+	DepartureTime       string  `json:"departure_time"` // HH:MM
+	TotalTrips          int     `json:"total_trips"`
+	TotalBookings       int     `json:"total_bookings"`
+	CancelledBookings   int     `json:"cancelled_bookings"`
+	TotalRevenue        float64 `json:"total_revenue"`
+	TotalSeatsBooked    int     `json:"total_seats_booked"`
+	TotalSeatsAvailable int     `json:"total_seats_available"`
+	AverageRating       float64 `json:"average_rating"`
+	RatingCount         int     `json:"rating_count"`
+}
+
+// TripAnalytics tracks load factor and yield for a single departure
+// Rows are refreshed for a few days after departure so check-ins and no-shows settle
+type TripAnalytics struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TripID            uuid.UUID  `json:"trip_id" gorm:"type:uuid;not null;uniqueIndex"`
+	RouteID           uuid.UUID  `json:"route_id" gorm:"type:uuid;not null;index"`
+	BusID             *uuid.UUID `json:"bus_id,omitempty" gorm:"type:uuid;index"`
+	OperatorID        *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
+	DepartureTime     time.Time  `json:"departure_time" gorm:"not null;index"`
+	SeatCapacity      int        `json:"seat_capacity" gorm:"default:0"` // Seat map total, falling back to the bus total
+	SeatsSold         int        `json:"seats_sold" gorm:"default:0"`
+	LoadFactor        float64    `json:"load_factor" gorm:"default:0"` // % of capacity sold
+	Revenue           float64    `json:"revenue" gorm:"default:0"`
+	RevenuePerSeat    float64    `json:"revenue_per_seat" gorm:"default:0"` // Revenue per available seat
+	DistanceKm        *float64   `json:"distance_km,omitempty"`
+	RevenuePerKm      float64    `json:"revenue_per_km" gorm:"default:0"`
+	RevenuePerSeatKm  float64    `json:"revenue_per_seat_km" gorm:"default:0"` // Revenue per available seat-kilometer
+	CheckedIn         int        `json:"checked_in" gorm:"default:0"`
+	NoShows           int        `json:"no_shows" gorm:"default:0"` // Sold tickets not scanned by departure
+	CancelledBookings int        `json:"cancelled_bookings" gorm:"default:0"`
+	CancelledSeats    int        `json:"cancelled_seats" gorm:"default:0"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relations
+	Route *Route `json:"route,omitempty" gorm:"foreignKey:RouteID"`
+	Bus   *Bus   `json:"bus,omitempty" gorm:"foreignKey:BusID"`
+}
+
+// TableName overrides the table name
+func (TripAnalytics) TableName() string {
+	return "trip_analytics"
+}
+
+// FunnelEventType is a step of the search-to-booking funnel
+type FunnelEventType string
+
+const (
+	FunnelSearch           FunnelEventType = "search"
+	FunnelTripView         FunnelEventType = "trip_view"
+	FunnelSeatsReserved    FunnelEventType = "seats_reserved"
+	FunnelBookingCreated   FunnelEventType = "booking_created"
+	FunnelPaymentStarted   FunnelEventType = "payment_started"
+	FunnelPaymentCompleted FunnelEventType = "payment_completed"
+)
+
+// FunnelEvent records one step a visitor's session took towards a booking
+// Searches carry the requested origin, destination and date; later steps carry the trip's route
+type FunnelEvent struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SessionID   string          `json:"session_id" gorm:"size:64;not null;index"`
+	EventType   FunnelEventType `json:"event_type" gorm:"size:32;not null;index:idx_funnel_events_type_time"`
+	UserID      *uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid"`
+	Origin      string          `json:"origin,omitempty"`
+	Destination string          `json:"destination,omitempty"`
+	TravelDate  *time.Time      `json:"travel_date,omitempty" gorm:"type:date"`
+	ResultCount *int            `json:"result_count,omitempty"` // Trips a search returned
+	RouteID     *uuid.UUID      `json:"route_id,omitempty" gorm:"type:uuid;index"`
+	TripID      *uuid.UUID      `json:"trip_id,omitempty" gorm:"type:uuid"`
+	BookingID   *uuid.UUID      `json:"booking_id,omitempty" gorm:"type:uuid;index"`
+	SeatCount   int             `json:"seat_count,omitempty" gorm:"default:0"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_funnel_events_type_time"`
+}
+
+// TableName overrides the table name
+func (FunnelEvent) TableName() string {
+	return "funnel_events"
+}
+
+// RouteFunnel counts the distinct sessions that reached each funnel step for a route
+type RouteFunnel struct {
+	RouteID           uuid.UUID `json:"route_id"`
+	Origin            string    `json:"origin"`
+	Destination       string    `json:"destination"`
+	Searches          int       `json:"searches"`
+	TripViews         int       `json:"trip_views"`
+	SeatsReserved     int       `json:"seats_reserved"`
+	BookingsCreated   int       `json:"bookings_created"`
+	PaymentsStarted   int       `json:"payments_started"`
+	PaymentsCompleted int       `json:"payments_completed"`
+}
+
+// UnmetDemand groups searches that returned no trips by what was asked for
+type UnmetDemand struct {
+	Origin      string     `json:"origin"`
+	Destination string     `json:"destination"`
+	TravelDate  *time.Time `json:"travel_date,omitempty"`
+	Searches    int        `json:"searches"`
+	Sessions    int        `json:"sessions"`
+	LastSearch  time.Time  `json:"last_searched_at"`
+}
+
+// AnalyticsBackfillStatus is the state of an analytics backfill task
+type AnalyticsBackfillStatus string
+
+const (
+	BackfillPending   AnalyticsBackfillStatus = "pending"
+	BackfillRunning   AnalyticsBackfillStatus = "running"
+	BackfillCompleted AnalyticsBackfillStatus = "completed"
+	BackfillFailed    AnalyticsBackfillStatus = "failed"
+	BackfillCancelled AnalyticsBackfillStatus = "cancelled"
+)
+
+// AnalyticsBackfill is an admin-requested recomputation of the daily aggregates over a date range
+// Days are processed oldest first; ProcessedDays doubles as the resume point after a restart
+// A partial unique index (idx_analytics_backfills_active) allows only one pending or running backfill
+type AnalyticsBackfill struct {
+	ID                uuid.UUID               `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	StartDate         time.Time               `json:"start_date" gorm:"type:date;not null"`
+	EndDate           time.Time               `json:"end_date" gorm:"type:date;not null"`
+	Status            AnalyticsBackfillStatus `json:"status" gorm:"size:20;not null;index"`
+	TotalDays         int                     `json:"total_days" gorm:"not null"`
+	ProcessedDays     int                     `json:"processed_days" gorm:"default:0"`
+	LastProcessedDate *time.Time              `json:"last_processed_date,omitempty" gorm:"type:date"`
+	Error             *string                 `json:"error,omitempty" gorm:"type:text"`
+	RequestedBy       *uuid.UUID              `json:"requested_by,omitempty" gorm:"type:uuid"`
+	StartedAt         *time.Time              `json:"started_at,omitempty"`
+	FinishedAt        *time.Time              `json:"finished_at,omitempty"`
+	CreatedAt         time.Time               `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time               `json:"updated_at" gorm:"autoUpdateTime"` // Heartbeat while running
+
+	Progress float64 `json:"progress" gorm:"-"` // % of days processed
+}
+
+// TableName overrides the table name
+func (AnalyticsBackfill) TableName() string {
+	return "analytics_backfills"
+}
+
+// SetProgress fills Progress from the processed day count
+func (b *AnalyticsBackfill) SetProgress() {
+	if b.TotalDays > 0 {
+		b.Progress = float64(b.ProcessedDays) / float64(b.TotalDays) * 100
+	}
+}
+
+// IsActive reports whether the backfill is still queued or running
+func (b *AnalyticsBackfill) IsActive() bool {
+	return b.Status == BackfillPending || b.Status == BackfillRunning
+}
+
+// MissingAnalyticsDate is a day whose aggregates were never computed
+type MissingAnalyticsDate struct {
+	Date           time.Time `json:"date"`
+	BookingMissing bool      `json:"booking_analytics_missing"` // No platform-wide booking row
+	MissingRoutes  int       `json:"missing_routes"`            // Routes with departures that day but no route row
+	MissingTrips   int       `json:"missing_trips"`             // Departures without a trip row
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope limits what a partner API key can be used for
+type APIKeyScope string
+
+const (
+	APIKeyScopeSearch  APIKeyScope = "search"  // Trip search, trip details and seat availability
+	APIKeyScopeBooking APIKeyScope = "booking" // Everything in search, plus seat holds and bookings
+)
+
+// IsValid reports whether the scope can be granted to a key
+func (s APIKeyScope) IsValid() bool {
+	return s == APIKeyScopeSearch || s == APIKeyScopeBooking
+}
+
+// APIKey lets a travel agency or reseller call the partner API from its own systems
+// Only the SHA-256 hash of the key is stored; the key itself is shown once when issued
+type APIKey struct {
+	ID                 uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name               string     `json:"name" gorm:"not null"`                                      // Label, e.g. "Production booking engine"
+	PartnerName        string     `json:"partner_name" gorm:"type:varchar(100);not null;index"`      // Agency the key belongs to
+	KeyPrefix          string     `json:"key_prefix" gorm:"type:varchar(16);not null"`               // First characters, to recognise the key
+	KeyHash            string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`            // SHA-256 of the full key
+	Scopes             string     `json:"scopes" gorm:"type:varchar(100);not null;default:'search'"` // Comma-separated APIKeyScope values
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" gorm:"not null;default:60"`
+	AllowedIPs         *string    `json:"allowed_ips,omitempty" gorm:"type:text"` // Comma-separated IPs or CIDR ranges; empty allows any address
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	RotatedFromID      *uuid.UUID `json:"rotated_from_id,omitempty" gorm:"type:uuid"` // Key this one replaced
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`                       // Set on the old key during a rotation grace period
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP         string     `json:"last_used_ip,omitempty" gorm:"type:varchar(45)"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive reports whether the key can still authenticate requests
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
+
+// ScopeList returns the scopes granted to the key
+func (k *APIKey) ScopeList() []APIKeyScope {
+	var scopes []APIKeyScope
+	for _, s := range strings.Split(k.Scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, APIKeyScope(s))
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether the key grants the scope; booking keys can also search
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope || (s == APIKeyScopeBooking && scope == APIKeyScopeSearch) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether requests from the address may use the key
+func (k *APIKey) AllowsIP(ip string) bool {
+	if k.AllowedIPs == nil || strings.TrimSpace(*k.AllowedIPs) == "" {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range strings.Split(*k.AllowedIPs, ",") {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
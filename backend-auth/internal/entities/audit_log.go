@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction identifies what a back-office user did to an entity
+type AuditAction string
+
+const (
+	AuditActionCreate         AuditAction = "create"
+	AuditActionUpdate         AuditAction = "update"
+	AuditActionDelete         AuditAction = "delete"
+	AuditActionAssignBus      AuditAction = "assign_bus"      // Bus assigned to a trip
+	AuditActionAssignSeatMap  AuditAction = "assign_seat_map" // Seat map assigned to a bus
+	AuditActionUpdateSeats    AuditAction = "update_seats"    // Seat layout edited
+	AuditActionRegenerate     AuditAction = "regenerate"      // Seat layout regenerated
+	AuditActionCheckIn        AuditAction = "check_in"
+	AuditActionDeactivate     AuditAction = "deactivate"
+	AuditActionUnlock         AuditAction = "unlock"
+	AuditActionAssignRole     AuditAction = "assign_role"
+	AuditActionAssignOperator AuditAction = "assign_operator"
+	AuditActionRotate         AuditAction = "rotate" // API key replaced by a new one
+	AuditActionRevoke         AuditAction = "revoke"
+)
+
+// Entity types recorded in the audit log
+const (
+	AuditEntityTrip      = "trip"
+	AuditEntityBus       = "bus"
+	AuditEntityRoute     = "route"
+	AuditEntityRouteStop = "route_stop"
+	AuditEntitySeatMap   = "seat_map"
+	AuditEntityPassenger = "passenger"
+	AuditEntityUser      = "user"
+	AuditEntityOperator  = "operator"
+	AuditEntityAPIKey    = "api_key"
+)
+
+// AuditLog is an append-only record of a mutating back-office action
+// Entries are never updated or deleted; Before and After hold JSON snapshots of the entity
+// and, for updates, Changes holds only the top-level fields that differ between them
+type AuditLog struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ActorID    *uuid.UUID  `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	ActorEmail string      `json:"actor_email,omitempty" gorm:"type:varchar(255)"`
+	ActorRole  string      `json:"actor_role,omitempty" gorm:"type:varchar(20)"`
+	OperatorID *uuid.UUID  `json:"operator_id,omitempty" gorm:"type:uuid;index"` // Operator scope of the request; nil for platform-wide actions
+	Action     AuditAction `json:"action" gorm:"type:varchar(30);not null;index"`
+	EntityType string      `json:"entity_type" gorm:"type:varchar(30);not null;index:idx_audit_logs_entity"`
+	EntityID   string      `json:"entity_id" gorm:"type:varchar(64);index:idx_audit_logs_entity"`
+	Before     *string     `json:"before,omitempty" gorm:"type:jsonb"`
+	After      *string     `json:"after,omitempty" gorm:"type:jsonb"`
+	Changes    *string     `json:"changes,omitempty" gorm:"type:jsonb"` // {"field": {"from": ..., "to": ...}}
+	IPAddress  string      `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	UserAgent  string      `json:"user_agent,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName overrides the table name
+func (AuditLog) TableName() string {
+	return "admin_audit_logs"
+}
@@ -16,6 +16,12 @@ type RefreshToken struct {
 	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 
+	// Rotation: every refresh replaces the token within the same session
+	// Tokens issued before session tracking have no session
+	SessionID    *uuid.UUID `json:"session_id,omitempty" gorm:"type:uuid;index"`
+	RotatedAt    *time.Time `json:"rotated_at,omitempty"`
+	ReplacedByID *uuid.UUID `json:"replaced_by_id,omitempty" gorm:"type:uuid"`
+
 	// Associations
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -34,3 +40,60 @@ func (rt *RefreshToken) IsExpired() bool {
 func (rt *RefreshToken) IsValid() bool {
 	return !rt.IsRevoked && !rt.IsExpired()
 }
+
+// Session revocation reasons
+const (
+	SessionRevokedLogout    = "logout"      // Signed out from the device itself
+	SessionRevokedByUser    = "signed_out"  // Signed out from another device's session list
+	SessionRevokedReuse     = "token_reuse" // A rotated refresh token was presented again
+	SessionRevokedAllDevice = "all_devices" // Logout everywhere, password reset or deactivation
+)
+
+// Session is a signed-in device; its refresh tokens form one rotation family
+type Session struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	DeviceName    string     `json:"device_name" gorm:"type:varchar(100)"`
+	IPAddress     string     `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent     string     `json:"user_agent" gorm:"type:text"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"type:varchar(20)"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Current marks the session making the request
+	Current bool `json:"current" gorm:"-"`
+}
+
+// TableName overrides the table name
+func (Session) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive checks if the session can still be refreshed
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// PasswordResetToken is a single-use token emailed to reset a forgotten password
+// Only the SHA-256 hash of the token is stored, so a database leak cannot be used to reset accounts
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RequestIP string     `json:"request_ip,omitempty" gorm:"type:varchar(45)"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// IsValid checks if the token is unused and not expired
+func (t *PasswordResetToken) IsValid() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -52,6 +52,16 @@ type Booking struct {
 	UpdatedAt         time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt         *time.Time    `json:"deleted_at,omitempty" gorm:"index"`
 
+	// Operator running the trip, copied at booking time for reporting
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
+
+	// Partner API key the booking was made with; nil for bookings made on the website
+	APIKeyID    *uuid.UUID `json:"api_key_id,omitempty" gorm:"type:uuid;index"`
+	PartnerName *string    `json:"partner_name,omitempty" gorm:"type:varchar(100);index"`
+
+	// Language the customer booked in, e.g. "vi"; notification templates are rendered in it
+	Locale string `json:"locale,omitempty" gorm:"type:varchar(10)"`
+
 	// Relations
 	Trip       *Trip       `json:"trip,omitempty" gorm:"foreignKey:TripID"`
 	User       *User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -147,6 +157,7 @@ type Ticket struct {
 	Barcode       *string    `json:"barcode,omitempty"`              // Barcode for scanning
 	IsUsed        bool       `json:"is_used" gorm:"default:false"`   // Has ticket been used/scanned
 	UsedAt        *time.Time `json:"used_at,omitempty"`              // When ticket was scanned
+	EmailSentAt   *time.Time `json:"email_sent_at,omitempty"`        // When the confirmation email delivered it; retries skip sent tickets
 	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt     *time.Time `json:"deleted_at,omitempty" gorm:"index"`
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookingAccessPurpose identifies what a booking access code unlocks
+type BookingAccessPurpose string
+
+const (
+	BookingAccessClaim BookingAccessPurpose = "claim"        // Attach guest bookings to the signed-in account
+	BookingAccessGuest BookingAccessPurpose = "guest_access" // Open a single booking without an account
+)
+
+// BookingAccessCode is a one-time code sent to a booking's contact address to prove the holder owns it
+// Only the SHA-256 hash of the code is stored
+type BookingAccessCode struct {
+	ID          uuid.UUID            `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Purpose     BookingAccessPurpose `json:"purpose" gorm:"type:varchar(20);not null;index"`
+	UserID      *uuid.UUID           `json:"user_id,omitempty" gorm:"type:uuid;index"`      // Account the bookings are claimed for
+	BookingIDs  string               `json:"-" gorm:"type:text;not null"`                   // Comma-separated booking IDs the code covers
+	Destination string               `json:"destination" gorm:"type:varchar(255);not null"` // Address the code was sent to
+	CodeHash    string               `json:"-" gorm:"type:varchar(64);not null"`
+	ExpiresAt   time.Time            `json:"expires_at" gorm:"not null"`
+	Attempts    int                  `json:"-" gorm:"default:0"` // Wrong codes entered
+	UsedAt      *time.Time           `json:"used_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name
+func (BookingAccessCode) TableName() string {
+	return "booking_access_codes"
+}
+
+// BookingIDList returns the bookings the code covers
+func (c *BookingAccessCode) BookingIDList() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, s := range strings.Split(c.BookingIDs, ",") {
+		if id, err := uuid.Parse(strings.TrimSpace(s)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// IsUsable reports whether the code can still be redeemed
+func (c *BookingAccessCode) IsUsable(maxAttempts int) bool {
+	return c.UsedAt == nil && time.Now().Before(c.ExpiresAt) && c.Attempts < maxAttempts
+}
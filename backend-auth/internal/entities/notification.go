@@ -14,6 +14,7 @@ const (
 	NotificationTypePaymentReceipt      NotificationType = "payment_receipt"
 	NotificationTypeTripReminder        NotificationType = "trip_reminder"
 	NotificationTypeCancellation        NotificationType = "cancellation"
+	NotificationTypeETicket             NotificationType = "e_ticket" // Ticket PDF emailed after payment
 	NotificationTypeRefund              NotificationType = "refund"
 	NotificationTypeSeatChange          NotificationType = "seat_change"
 )
@@ -39,6 +40,9 @@ const (
 	NotificationStatusRead      NotificationStatus = "read"
 	NotificationStatusFailed    NotificationStatus = "failed"
 	NotificationStatusCancelled NotificationStatus = "cancelled"
+	// NotificationStatusDeadLetter marks notifications that exhausted all retries
+	// and are parked for manual inspection by an admin
+	NotificationStatusDeadLetter NotificationStatus = "dead_letter"
 )
 
 // Notification represents a notification to be sent to a user
@@ -92,6 +96,11 @@ func (n *Notification) CanRetry() bool {
 	return n.Status == NotificationStatusFailed && n.RetryCount < n.MaxRetries
 }
 
+// IsDeadLettered checks if notification has been moved to the dead-letter queue
+func (n *Notification) IsDeadLettered() bool {
+	return n.Status == NotificationStatusDeadLetter
+}
+
 // IsReadyToSend checks if notification should be sent now
 func (n *Notification) IsReadyToSend() bool {
 	if n.Status != NotificationStatusPending && n.Status != NotificationStatusQueued {
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTemplateLocale is used when a notification has no explicit locale
+const DefaultTemplateLocale = "en"
+
+// NotificationTemplate stores admin-editable notification content
+// Each edit creates a new version; only one version per type and locale is active at a time.
+// Bodies use Go template syntax and are rendered against the matching *Data struct
+// (e.g. BookingConfirmationData), with compiled-in templates used as fallbacks.
+type NotificationTemplate struct {
+	ID       uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Type     NotificationType `json:"type" gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_template_version"`
+	Locale   string           `json:"locale" gorm:"type:varchar(10);not null;default:'en';uniqueIndex:idx_notification_template_version"`
+	Version  int              `json:"version" gorm:"not null;default:1;uniqueIndex:idx_notification_template_version"`
+	IsActive bool             `json:"is_active" gorm:"default:false;index"`
+
+	// Content
+	Subject  string `json:"subject" gorm:"not null"`
+	HTMLBody string `json:"html_body" gorm:"type:text;not null"`
+	TextBody string `json:"text_body" gorm:"type:text"`
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operator is a bus company selling trips on the platform
+// Fleet, schedule, bookings and payments carry the owning operator's ID;
+// records without one belong to the platform itself
+type Operator struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name         string     `json:"name" gorm:"not null"`
+	Code         string     `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"` // Short identifier, e.g. "PHUONGTRANG"
+	ContactEmail string     `json:"contact_email"`
+	ContactPhone string     `json:"contact_phone"`
+	IsActive     bool       `json:"is_active" gorm:"default:true"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Operator) TableName() string {
+	return "operators"
+}
+
+// SameOperator reports whether two records belong to the same operator (or both to the platform)
+func SameOperator(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEventStatus represents the delivery state of an outbox event
+type OutboxEventStatus string
+
+const (
+	OutboxEventPending    OutboxEventStatus = "pending"
+	OutboxEventDispatched OutboxEventStatus = "dispatched"
+	OutboxEventFailed     OutboxEventStatus = "failed" // Will be retried after AvailableAt
+	OutboxEventDead       OutboxEventStatus = "dead"   // Exhausted MaxAttempts
+)
+
+// Outbox event types dispatched by the relay worker
+const (
+	OutboxEventTicketEmail      = "booking.ticket_email"
+	OutboxEventBookingInApp     = "booking.in_app_notification"
+	OutboxEventPaymentReceipt   = "payment.receipt"
+	OutboxEventAnalyticsRefresh = "analytics.refresh"
+)
+
+// OutboxEvent is a side effect recorded in the same transaction as the state change
+// that caused it. A relay worker delivers pending events at least once; handlers use
+// the payload (and DedupKey) to stay idempotent.
+type OutboxEvent struct {
+	ID            uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EventType     string            `json:"event_type" gorm:"type:varchar(100);not null;index"`
+	AggregateType string            `json:"aggregate_type" gorm:"type:varchar(50);not null"` // booking, payment
+	AggregateID   uuid.UUID         `json:"aggregate_id" gorm:"type:uuid;not null;index"`
+	Payload       string            `json:"payload" gorm:"type:jsonb;not null;default:'{}'"`
+	DedupKey      string            `json:"dedup_key" gorm:"type:varchar(255);not null;uniqueIndex"` // Same effect is never recorded twice
+	Status        OutboxEventStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_status_available"`
+	Attempts      int               `json:"attempts" gorm:"default:0"`
+	MaxAttempts   int               `json:"max_attempts" gorm:"default:10"`
+	AvailableAt   time.Time         `json:"available_at" gorm:"not null;index:idx_outbox_status_available"` // Not picked up before this time
+	LastError     *string           `json:"last_error,omitempty" gorm:"type:text"`
+	DispatchedAt  *time.Time        `json:"dispatched_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// OutboxPayload is the JSON body carried by outbox events
+// Only the identifiers are stored; handlers reload current state when dispatching
+type OutboxPayload struct {
+	BookingID uuid.UUID  `json:"booking_id"`
+	PaymentID *uuid.UUID `json:"payment_id,omitempty"`
+	TripID    *uuid.UUID `json:"trip_id,omitempty"`
+}
+
+// NewOutboxEvent builds a pending outbox event with a JSON-encoded payload
+func NewOutboxEvent(eventType, aggregateType string, aggregateID uuid.UUID, dedupKey string, payload OutboxPayload) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		DedupKey:      dedupKey,
+		Status:        OutboxEventPending,
+		MaxAttempts:   10,
+		AvailableAt:   time.Now(),
+	}, nil
+}
+
+// DecodePayload unmarshals the event payload
+func (e *OutboxEvent) DecodePayload() (*OutboxPayload, error) {
+	var payload OutboxPayload
+	if err := json.Unmarshal([]byte(e.Payload), &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// CanRetry checks if the event has attempts left
+func (e *OutboxEvent) CanRetry() bool {
+	return e.Attempts < e.MaxAttempts
+}
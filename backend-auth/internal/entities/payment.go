@@ -70,6 +70,9 @@ type Payment struct {
 	WebhookProcessedAt *time.Time `json:"webhook_processed_at,omitempty"`
 	WebhookRetryCount  int        `json:"webhook_retry_count" gorm:"default:0"`
 
+	// Operator the payment is collected for, copied from the booking
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
+
 	// Standard timestamps
 	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
@@ -102,6 +105,15 @@ func (p *Payment) IsSuccessful() bool {
 	return p.Status == PaymentTransactionCompleted && p.CompletedAt != nil
 }
 
+// Webhook log processing statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusProcessed = "processed"
+	WebhookStatusFailed    = "failed"    // Retried by the webhook retry job
+	WebhookStatusDuplicate = "duplicate" // Same event already processed
+	WebhookStatusRejected  = "rejected"  // Signature verification failed, never retried automatically
+)
+
 // PaymentWebhookLog tracks all webhook events received from payment gateway
 // This ensures idempotency and helps with debugging webhook issues
 type PaymentWebhookLog struct {
@@ -115,6 +127,7 @@ type PaymentWebhookLog struct {
 	ProcessedAt       *time.Time `json:"processed_at,omitempty"`
 	ErrorMessage      *string    `json:"error_message,omitempty"`
 	RetryCount        int        `json:"retry_count" gorm:"default:0"`
+	NextRetryAt       *time.Time `json:"next_retry_at,omitempty" gorm:"index"` // Backoff: not retried before this time
 	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
 
 	// Relations
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconciliationAction is what the reconciliation job did after comparing a payment with the provider
+type ReconciliationAction string
+
+const (
+	ReconciliationActionNone      ReconciliationAction = "none"      // States agree, nothing to do
+	ReconciliationActionCompleted ReconciliationAction = "completed" // Provider paid, payment and booking confirmed
+	ReconciliationActionFailed    ReconciliationAction = "failed"
+	ReconciliationActionCancelled ReconciliationAction = "cancelled"
+	ReconciliationActionExpired   ReconciliationAction = "expired"
+	ReconciliationActionFlagged   ReconciliationAction = "flagged" // Mismatch needing manual review, no transition applied
+	ReconciliationActionError     ReconciliationAction = "error"   // Provider lookup failed
+)
+
+// PaymentReconciliation records one payment checked against the payment provider
+// Only checks that changed something, found a mismatch or failed are stored; they make up the daily report.
+// A flagged or error entry is refreshed by later runs that day that hit the same problem instead of adding rows;
+// the next day starts a new entry so each daily report still lists every open problem.
+type PaymentReconciliation struct {
+	ID             uuid.UUID            `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PaymentID      uuid.UUID            `json:"payment_id" gorm:"type:uuid;not null;index"`
+	BookingID      uuid.UUID            `json:"booking_id" gorm:"type:uuid;not null;index"`
+	OrderCode      string               `json:"order_code" gorm:"type:varchar(50)"`
+	LocalStatus    string               `json:"local_status" gorm:"type:varchar(20)"`    // Payment status before reconciliation
+	ProviderStatus string               `json:"provider_status" gorm:"type:varchar(20)"` // Status reported by the provider
+	BookingStatus  string               `json:"booking_status" gorm:"type:varchar(20)"`  // Booking status before reconciliation
+	Amount         float64              `json:"amount"`
+	ProviderAmount float64              `json:"provider_amount"`
+	Action         ReconciliationAction `json:"action" gorm:"type:varchar(20);not null"`
+	Mismatch       bool                 `json:"mismatch" gorm:"default:false;index"`
+	Note           *string              `json:"note,omitempty" gorm:"type:text"`
+	CheckedAt      time.Time            `json:"checked_at" gorm:"not null;index"`      // Last run that day that found this
+	CheckCount     int                  `json:"check_count" gorm:"not null;default:1"` // Runs that day that found this
+	CreatedAt      time.Time            `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name
+func (PaymentReconciliation) TableName() string {
+	return "payment_reconciliations"
+}
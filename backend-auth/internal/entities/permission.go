@@ -0,0 +1,126 @@
+package entities
+
+// Permission is a single back-office capability granted through a role
+type Permission string
+
+const (
+	PermTripsView           Permission = "trips.view"
+	PermTripsManage         Permission = "trips.manage"         // Schedule, edit and cancel trips, assign buses, update trip status
+	PermFleetManage         Permission = "fleet.manage"         // Buses and seat maps
+	PermRoutesManage        Permission = "routes.manage"        // Routes and their stops
+	PermPassengersView      Permission = "passengers.view"      // Trip passenger manifests
+	PermPassengersCheckIn   Permission = "passengers.checkin"   // Board passengers
+	PermRefundsIssue        Permission = "refunds.issue"        // Refund or compensate customers
+	PermPaymentsView        Permission = "payments.view"        // Webhook logs and reconciliation reports
+	PermPaymentsManage      Permission = "payments.manage"      // Replay webhooks and run reconciliation
+	PermNotificationsManage Permission = "notifications.manage" // Templates and the dead-letter queue
+	PermAnalyticsView       Permission = "analytics.view"
+	PermAnalyticsManage     Permission = "analytics.manage" // Backfill and recompute stored analytics
+	PermUsersView           Permission = "users.view"       // User accounts, lockout status and security events
+	PermUsersManage         Permission = "users.manage"     // Create, edit, deactivate and unlock accounts
+	PermRolesManage         Permission = "roles.manage"     // Assign roles to users
+	PermOperatorsManage     Permission = "operators.manage" // Onboard bus operators and assign staff to them
+	PermAuditView           Permission = "audit.view"       // Back-office audit log
+	PermAPIKeysManage       Permission = "api_keys.manage"  // Issue, rotate and revoke partner API keys
+)
+
+// AllPermissions returns every permission known to the system
+func AllPermissions() []Permission {
+	return []Permission{
+		PermTripsView,
+		PermTripsManage,
+		PermFleetManage,
+		PermRoutesManage,
+		PermPassengersView,
+		PermPassengersCheckIn,
+		PermRefundsIssue,
+		PermPaymentsView,
+		PermPaymentsManage,
+		PermNotificationsManage,
+		PermAnalyticsView,
+		PermAnalyticsManage,
+		PermUsersView,
+		PermUsersManage,
+		PermRolesManage,
+		PermOperatorsManage,
+		PermAuditView,
+		PermAPIKeysManage,
+	}
+}
+
+// RoleDefinition describes an assignable role and what it grants
+type RoleDefinition struct {
+	Role        Role         `json:"role"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+var roleDefinitions = []RoleDefinition{
+	{
+		Role:        RolePassenger,
+		Description: "Customer account with no back-office access",
+		Permissions: []Permission{},
+	},
+	{
+		Role:        RoleConductor,
+		Description: "On-board staff who check passengers in",
+		Permissions: []Permission{PermTripsView, PermPassengersView, PermPassengersCheckIn},
+	},
+	{
+		Role:        RoleSupportAgent,
+		Description: "Customer support handling accounts, payments and refunds",
+		Permissions: []Permission{PermTripsView, PermPassengersView, PermRefundsIssue, PermPaymentsView, PermNotificationsManage, PermUsersView},
+	},
+	{
+		Role:        RoleOperatorStaff,
+		Description: "Operations staff managing the fleet, routes and schedule",
+		Permissions: []Permission{PermTripsView, PermTripsManage, PermFleetManage, PermRoutesManage, PermPassengersView, PermPassengersCheckIn, PermAnalyticsView},
+	},
+	{
+		Role:        RoleAdmin,
+		Description: "Full access to every back-office feature",
+		Permissions: AllPermissions(),
+	},
+}
+
+// RoleDefinitions returns every assignable role with its permissions
+func RoleDefinitions() []RoleDefinition {
+	defs := make([]RoleDefinition, len(roleDefinitions))
+	copy(defs, roleDefinitions)
+	return defs
+}
+
+// IsValid reports whether the role can be assigned to a user
+func (r Role) IsValid() bool {
+	for _, def := range roleDefinitions {
+		if def.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether the role grants any back-office access
+func (r Role) IsStaff() bool {
+	return len(r.Permissions()) > 0
+}
+
+// Permissions returns the permissions granted by the role
+func (r Role) Permissions() []Permission {
+	for _, def := range roleDefinitions {
+		if def.Role == r {
+			return def.Permissions
+		}
+	}
+	return nil
+}
+
+// HasPermission reports whether the role grants the permission
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range r.Permissions() {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
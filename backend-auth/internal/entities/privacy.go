@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountErasureStatus tracks a self-service account deletion request
+type AccountErasureStatus string
+
+const (
+	AccountErasurePending   AccountErasureStatus = "pending"   // Waiting for the user to confirm by email
+	AccountErasureConfirmed AccountErasureStatus = "confirmed" // Confirmed, queued for the erasure job
+	AccountErasureCompleted AccountErasureStatus = "completed" // Personal data anonymized
+	AccountErasureFailed    AccountErasureStatus = "failed"    // Gave up after repeated errors, needs manual follow-up
+)
+
+// AccountErasureRequest is a user's request to delete their account and personal data
+// Only the SHA-256 hash of the confirmation token is stored; the token itself is only in the email
+type AccountErasureRequest struct {
+	ID          uuid.UUID            `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID            `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash   string               `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Status      AccountErasureStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ExpiresAt   time.Time            `json:"expires_at" gorm:"not null"` // Confirmation deadline
+	RequestIP   string               `json:"-" gorm:"type:varchar(45)"`
+	ConfirmedAt *time.Time           `json:"confirmed_at,omitempty"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	Attempts    int                  `json:"-" gorm:"default:0"`
+	LastError   *string              `json:"-" gorm:"type:text"`
+	CreatedAt   time.Time            `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name
+func (AccountErasureRequest) TableName() string {
+	return "account_erasure_requests"
+}
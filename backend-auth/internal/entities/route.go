@@ -19,6 +19,9 @@ type Route struct {
 	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt       *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Owning operator, nil for platform-owned records
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
 }
 
 // TableName overrides the table name
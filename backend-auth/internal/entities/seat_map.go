@@ -41,6 +41,9 @@ type SeatMap struct {
 	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
+	// Owning operator, nil for platform-owned records
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
+
 	// Relations
 	Seats []*Seat `json:"seats,omitempty" gorm:"foreignKey:SeatMapID"`
 }
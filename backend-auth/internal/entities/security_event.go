@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEventType identifies an account protection event
+type SecurityEventType string
+
+const (
+	SecurityEventAccountLocked    SecurityEventType = "account_locked"    // Too many failed logins for the account
+	SecurityEventIPBlocked        SecurityEventType = "ip_blocked"        // Too many failed logins from one IP address
+	SecurityEventAccountUnlocked  SecurityEventType = "account_unlocked"  // Lockout lifted by an admin
+	SecurityEventRoleChanged      SecurityEventType = "role_changed"      // Role assigned by an admin
+	SecurityEventOperatorChanged  SecurityEventType = "operator_changed"  // Staff account moved to another operator
+	SecurityEventIdentityLinked   SecurityEventType = "identity_linked"   // OAuth provider linked to the account
+	SecurityEventIdentityUnlinked SecurityEventType = "identity_unlinked" // OAuth provider removed from the account
+)
+
+// SecurityEvent is an audit record of a lockout, unlock, role, operator or sign-in method change
+type SecurityEvent struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EventType SecurityEventType `json:"event_type" gorm:"type:varchar(30);not null;index"`
+	UserID    *uuid.UUID        `json:"user_id,omitempty" gorm:"type:uuid;index"` // Nil for unknown emails and IP blocks
+	Email     string            `json:"email,omitempty" gorm:"type:varchar(255);index"`
+	IPAddress string            `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	ActorID   *uuid.UUID        `json:"actor_id,omitempty" gorm:"type:uuid"` // Admin who performed the action
+	Details   *string           `json:"details,omitempty" gorm:"type:text"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName overrides the table name
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
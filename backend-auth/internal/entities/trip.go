@@ -30,7 +30,10 @@ type Trip struct {
 	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt *time.Time  `json:"deleted_at,omitempty" gorm:"index"`
-	
+
+	// Owning operator, inherited from the route
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
+
 	// Relations (will be loaded separately)
 	Route     *Route      `json:"route,omitempty" gorm:"foreignKey:RouteID"`
 	Bus       *Bus        `json:"bus,omitempty" gorm:"foreignKey:BusID"`
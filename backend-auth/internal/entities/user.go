@@ -13,6 +13,11 @@ const (
 	RoleGuest     Role = "guest"
 	RolePassenger Role = "passenger"
 	RoleAdmin     Role = "admin"
+
+	// Back-office staff roles, see RoleDefinitions for what each one grants
+	RoleOperatorStaff Role = "operator_staff"
+	RoleConductor     Role = "conductor"
+	RoleSupportAgent  Role = "support_agent"
 )
 
 // User represents a user in the system
@@ -23,16 +28,74 @@ type User struct {
 	Phone         string     `json:"phone" gorm:"index"`
 	Role          Role       `json:"role" gorm:"type:varchar(20);not null;default:'passenger'"`
 	PasswordHash  string     `json:"-" gorm:"column:password_hash"`
-	OAuthID       *string    `json:"oauth_id,omitempty" gorm:"uniqueIndex"`
-	OAuthProvider *string    `json:"oauth_provider,omitempty" gorm:"type:varchar(20)"` // google, github
+	OAuthID       *string    `json:"oauth_id,omitempty" gorm:"uniqueIndex"`            // Legacy, see UserIdentity
+	OAuthProvider *string    `json:"oauth_provider,omitempty" gorm:"type:varchar(20)"` // Legacy: provider the account signed up with
 	Avatar        *string    `json:"avatar,omitempty"`
 	IsActive      bool       `json:"is_active" gorm:"default:true"`
 	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt     *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Email verification (OAuth accounts are verified by the provider)
+	EmailVerified      bool       `json:"email_verified" gorm:"default:false"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	VerificationSentAt *time.Time `json:"-"` // Throttles resend requests
+
+	// Two-factor authentication (TOTP)
+	MFAEnabled      bool       `json:"mfa_enabled" gorm:"default:false"`
+	MFAEnabledAt    *time.Time `json:"mfa_enabled_at,omitempty"`
+	MFASecret       string     `json:"-" gorm:"type:varchar(255)"` // Encrypted; pending until enrollment is confirmed
+	MFALastUsedStep int64      `json:"-"`                          // Rejects reuse of an accepted code
+
+	// Operator a staff account works for; nil for platform staff and customers
+	OperatorID *uuid.UUID `json:"operator_id,omitempty" gorm:"type:uuid;index"`
 }
 
 // TableName overrides the table name
 func (User) TableName() string {
 	return "users"
 }
+
+// MFARecoveryCode is a single-use backup code for signing in without the authenticator app
+// Only the SHA-256 hash is stored; the codes are shown to the user once when generated
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null;index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// OAuthProvider identifies an external sign-in provider
+type OAuthProvider string
+
+const (
+	OAuthProviderGoogle OAuthProvider = "google"
+	OAuthProviderGitHub OAuthProvider = "github"
+)
+
+// IsValid reports whether the provider is supported
+func (p OAuthProvider) IsValid() bool {
+	return p == OAuthProviderGoogle || p == OAuthProviderGitHub
+}
+
+// UserIdentity links an external OAuth account to a user, who may have one per provider
+type UserIdentity struct {
+	ID             uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID         uuid.UUID     `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider       OAuthProvider `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_subject"`
+	ProviderUserID string        `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_subject"` // The provider's stable account ID
+	Email          string        `json:"email" gorm:"type:varchar(255)"`                                              // Address reported by the provider when linked
+	LastLoginAt    *time.Time    `json:"last_login_at,omitempty"`
+	CreatedAt      time.Time     `json:"linked_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
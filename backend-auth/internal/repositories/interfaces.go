@@ -13,6 +13,7 @@ type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	// GetByOAuthID finds the user an OAuth identity is linked to
 	GetByOAuthID(ctx context.Context, oauthID string, provider string) (*entities.User, error)
 	GetByRole(ctx context.Context, role entities.Role) ([]*entities.User, error)
 	GetAll(ctx context.Context) ([]*entities.User, error)
@@ -21,15 +22,126 @@ type UserRepository interface {
 	SetActive(ctx context.Context, id uuid.UUID, active bool) error
 }
 
+// UserIdentityRepository defines the interface for linked OAuth identity operations
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entities.UserIdentity) error
+	GetByProviderUserID(ctx context.Context, provider entities.OAuthProvider, providerUserID string) (*entities.UserIdentity, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.UserIdentity, error)
+	TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, userID uuid.UUID, provider entities.OAuthProvider) error
+}
+
+// AccountErasureRepository defines the interface for account deletion requests
+type AccountErasureRepository interface {
+	Create(ctx context.Context, request *entities.AccountErasureRequest) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.AccountErasureRequest, error)
+	GetLatestForUser(ctx context.Context, userID uuid.UUID) (*entities.AccountErasureRequest, error)
+	// GetConfirmed returns confirmed requests waiting for the erasure job, oldest first
+	GetConfirmed(ctx context.Context, limit int) ([]*entities.AccountErasureRequest, error)
+	Update(ctx context.Context, request *entities.AccountErasureRequest) error
+	// EraseUserData anonymizes the user's personal data in one transaction
+	// Bookings and payments are kept for financial records with their contact details replaced
+	EraseUserData(ctx context.Context, userID uuid.UUID, erasedAt time.Time) error
+}
+
 // RefreshTokenRepository defines the interface for refresh token operations
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *entities.RefreshToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.RefreshToken, error)
 	GetByToken(ctx context.Context, token string) (*entities.RefreshToken, error)
+	// FindByToken returns the token even if it was revoked, rotated or expired (for reuse detection)
+	FindByToken(ctx context.Context, token string) (*entities.RefreshToken, error)
+	// Rotate marks current as replaced by next and stores next; returns false if current was already rotated
+	Rotate(ctx context.Context, current, next *entities.RefreshToken) (bool, error)
 	Revoke(ctx context.Context, token string) error
+	// RevokeAllForUser revokes every refresh token and session of the user
 	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
 }
 
+// SessionRepository defines the interface for device session operations
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Session, error)
+	ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, ipAddress string, expiresAt time.Time) error
+	// Revoke ends the session and revokes all of its refresh tokens
+	Revoke(ctx context.Context, id uuid.UUID, reason string) error
+}
+
+// PasswordResetTokenRepository defines the interface for password reset token operations
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *entities.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*entities.PasswordResetToken, error)
+	GetLatestForUser(ctx context.Context, userID uuid.UUID) (*entities.PasswordResetToken, error)
+	// MarkUsed consumes the token; returns false if it was already used
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	InvalidateAllForUser(ctx context.Context, userID uuid.UUID) error
+	DeleteExpired(ctx context.Context) error
+}
+
+// MFARecoveryCodeRepository defines the interface for two-factor recovery code operations
+type MFARecoveryCodeRepository interface {
+	// ReplaceForUser deletes the user's existing codes and stores the new set
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []*entities.MFARecoveryCode) error
+	// Consume marks an unused code as used; returns false if no unused code matches
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnused(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// SecurityEventRepository defines the interface for account security audit records
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *entities.SecurityEvent) error
+	List(ctx context.Context, filter SecurityEventFilter, page, pageSize int) ([]*entities.SecurityEvent, int64, error)
+}
+
+// SecurityEventFilter narrows security event listings; empty fields are ignored
+type SecurityEventFilter struct {
+	UserID    *uuid.UUID
+	Email     string
+	EventType string
+}
+
+// AuditLogRepository defines the interface for the back-office audit log
+// The log is append-only, so there are deliberately no update or delete operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *entities.AuditLog) error
+	List(ctx context.Context, filter AuditLogFilter, page, pageSize int) ([]*entities.AuditLog, int64, error)
+	// Each calls fn for every matching entry, newest first, without loading them all into memory
+	Each(ctx context.Context, filter AuditLogFilter, fn func(*entities.AuditLog) error) error
+}
+
+// AuditLogFilter narrows audit log listings; empty fields are ignored
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// APIKeyRepository defines the interface for partner API key operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	List(ctx context.Context, partnerName string, includeRevoked bool) ([]*entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+	// TouchLastUsed records when and from where the key was last used
+	TouchLastUsed(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+}
+
+// OperatorRepository defines the interface for bus operator (tenant) operations
+type OperatorRepository interface {
+	Create(ctx context.Context, operator *entities.Operator) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Operator, error)
+	GetAll(ctx context.Context) ([]*entities.Operator, error)
+	Update(ctx context.Context, operator *entities.Operator) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
 // BusRepository defines the interface for bus data operations
 type BusRepository interface {
 	Create(ctx context.Context, bus *entities.Bus) error
@@ -129,7 +241,11 @@ type BookingRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Booking, error)
 	GetByReference(ctx context.Context, reference string) (*entities.Booking, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]*entities.Booking, int64, error)
-	GetByGuestContact(ctx context.Context, email, phone string) ([]*entities.Booking, error)
+	// GetClaimableByEmail returns guest bookings made with the email that no account has claimed yet
+	GetClaimableByEmail(ctx context.Context, email string) ([]*entities.Booking, error)
+	// ClaimForUser attaches the listed guest bookings to the user, skipping any already claimed
+	// or not made with the email; returns how many were attached
+	ClaimForUser(ctx context.Context, userID uuid.UUID, email string, bookingIDs []uuid.UUID) (int64, error)
 	GetByTripID(ctx context.Context, tripID uuid.UUID) ([]*entities.Booking, error)
 	Update(ctx context.Context, booking *entities.Booking) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -141,6 +257,21 @@ type BookingRepository interface {
 	// GetByDateRange retrieves bookings within a time range
 	// Used for analytics and reporting
 	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entities.Booking, error)
+	// UpdateWithOutbox saves the booking and records the outbox events in one transaction
+	UpdateWithOutbox(ctx context.Context, booking *entities.Booking, events []*entities.OutboxEvent) error
+}
+
+// BookingAccessCodeRepository defines the interface for one-time booking access codes
+type BookingAccessCodeRepository interface {
+	Create(ctx context.Context, code *entities.BookingAccessCode) error
+	// GetLatestForUser returns the user's most recent code for the purpose
+	GetLatestForUser(ctx context.Context, userID uuid.UUID, purpose entities.BookingAccessPurpose) (*entities.BookingAccessCode, error)
+	// GetLatestForBooking returns the most recent code for the purpose that covers only this booking
+	GetLatestForBooking(ctx context.Context, bookingID uuid.UUID, purpose entities.BookingAccessPurpose) (*entities.BookingAccessCode, error)
+	Update(ctx context.Context, code *entities.BookingAccessCode) error
+	// MarkUsed consumes the code; returns false if it was already used
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	DeleteExpired(ctx context.Context) error
 }
 
 // PassengerRepository defines the interface for passenger data operations
@@ -172,9 +303,11 @@ type TicketRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Ticket, error)
 	GetByTicketNumber(ctx context.Context, ticketNumber string) (*entities.Ticket, error)
 	GetByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*entities.Ticket, error)
+	GetByTripAndPassenger(ctx context.Context, tripID, passengerID uuid.UUID) (*entities.Ticket, error)
 	BulkCreate(ctx context.Context, tickets []*entities.Ticket) error
 	Update(ctx context.Context, ticket *entities.Ticket) error
 	MarkAsUsed(ctx context.Context, ticketNumber string) error
+	MarkEmailSent(ctx context.Context, id uuid.UUID) error
 }
 
 // PaymentRepository defines the interface for payment data operations
@@ -188,6 +321,20 @@ type PaymentRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetPendingPayments(ctx context.Context) ([]*entities.Payment, error)
 	GetExpiredPayments(ctx context.Context) ([]*entities.Payment, error)
+	// CompleteWithOutbox saves the payment and its booking and records the outbox events in one transaction
+	CompleteWithOutbox(ctx context.Context, payment *entities.Payment, booking *entities.Booking, events []*entities.OutboxEvent) error
+}
+
+// OutboxRepository defines the interface for transactional outbox operations
+type OutboxRepository interface {
+	Create(ctx context.Context, event *entities.OutboxEvent) error
+	// ClaimBatch locks up to limit due events (SKIP LOCKED), pushes their AvailableAt out by lease
+	// and increments Attempts so a crashed relay does not hold them forever
+	ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, errMsg string, retryAt time.Time) error
+	MarkDead(ctx context.Context, id uuid.UUID, errMsg string) error
+	DeleteDispatchedBefore(ctx context.Context, cutoff time.Time) error
 }
 
 // PaymentWebhookLogRepository defines the interface for payment webhook log operations
@@ -197,7 +344,28 @@ type PaymentWebhookLogRepository interface {
 	GetByPaymentID(ctx context.Context, paymentID uuid.UUID) ([]*entities.PaymentWebhookLog, error)
 	GetByExternalPaymentID(ctx context.Context, externalID string) ([]*entities.PaymentWebhookLog, error)
 	Update(ctx context.Context, log *entities.PaymentWebhookLog) error
-	GetPendingLogs(ctx context.Context) ([]*entities.PaymentWebhookLog, error)
+	// GetPendingLogs returns pending or failed logs that are due for a retry
+	// Logs created after createdBefore are skipped so requests still in flight are not picked up
+	GetPendingLogs(ctx context.Context, maxRetries int, createdBefore time.Time, limit int) ([]*entities.PaymentWebhookLog, error)
+	List(ctx context.Context, filter WebhookLogFilter, page, pageSize int) ([]*entities.PaymentWebhookLog, int64, error)
+}
+
+// PaymentReconciliationRepository defines the interface for payment reconciliation records
+type PaymentReconciliationRepository interface {
+	Create(ctx context.Context, entry *entities.PaymentReconciliation) error
+	// Record stores a run's result; a flagged or error result for a payment that already has an entry
+	// with the same action on the same day updates that entry instead of inserting another
+	Record(ctx context.Context, entry *entities.PaymentReconciliation) error
+	// GetByDateRange returns entries checked in [startDate, endDate), oldest first
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time, mismatchOnly bool) ([]*entities.PaymentReconciliation, error)
+}
+
+// WebhookLogFilter holds optional filters for listing webhook logs
+type WebhookLogFilter struct {
+	Status            string
+	PaymentID         *uuid.UUID
+	ExternalPaymentID string
+	EventType         string
 }
 
 // NotificationRepository defines the interface for notification data operations
@@ -213,6 +381,9 @@ type NotificationRepository interface {
 	DeleteOlderThan(ctx context.Context, cutoffTime time.Time) error
 	MarkAsSent(ctx context.Context, id uuid.UUID) error
 	MarkAsFailed(ctx context.Context, id uuid.UUID, errorMsg string) error
+	// GetByStatus retrieves a page of notifications with the given status, newest failures first
+	// Used by the admin dead-letter view
+	GetByStatus(ctx context.Context, status entities.NotificationStatus, page, pageSize int) ([]*entities.Notification, int64, error)
 }
 
 // NotificationPreferenceRepository defines the interface for notification preference operations
@@ -225,6 +396,24 @@ type NotificationPreferenceRepository interface {
 	CreateDefault(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error)
 }
 
+// NotificationTemplateRepository defines the interface for stored notification template operations
+type NotificationTemplateRepository interface {
+	Create(ctx context.Context, template *entities.NotificationTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.NotificationTemplate, error)
+	// GetActive retrieves the active version for a type and locale
+	GetActive(ctx context.Context, notifType entities.NotificationType, locale string) (*entities.NotificationTemplate, error)
+	// CreateVersion stores the template as the next version for its type and locale and makes it
+	// the only active one, atomically
+	CreateVersion(ctx context.Context, template *entities.NotificationTemplate) error
+	GetVersions(ctx context.Context, notifType entities.NotificationType, locale string) ([]*entities.NotificationTemplate, error)
+	// GetAllActive retrieves active templates, optionally filtered by type
+	GetAllActive(ctx context.Context, notifType *entities.NotificationType) ([]*entities.NotificationTemplate, error)
+	// Activate makes the given version the only active one for its type and locale
+	Activate(ctx context.Context, id uuid.UUID) error
+	// Deactivate disables every version for a type and locale so the compiled-in fallback is used
+	Deactivate(ctx context.Context, notifType entities.NotificationType, locale string) error
+}
+
 // BookingAnalyticsRepository defines the interface for booking analytics operations
 type BookingAnalyticsRepository interface {
 	Create(ctx context.Context, analytics *entities.BookingAnalytics) error
@@ -232,6 +421,8 @@ type BookingAnalyticsRepository interface {
 	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entities.BookingAnalytics, error)
 	Update(ctx context.Context, analytics *entities.BookingAnalytics) error
 	CreateOrUpdate(ctx context.Context, analytics *entities.BookingAnalytics) error
+	// CreateOrUpdateForOperator stores one operator's share of a day's metrics
+	CreateOrUpdateForOperator(ctx context.Context, analytics *entities.OperatorBookingAnalytics) error
 }
 
 // RouteAnalyticsRepository defines the interface for route analytics operations
@@ -243,6 +434,61 @@ type RouteAnalyticsRepository interface {
 	GetTopRoutesByBookings(ctx context.Context, startDate, endDate time.Time, limit int) ([]*entities.RouteAnalytics, error)
 	Update(ctx context.Context, analytics *entities.RouteAnalytics) error
 	CreateOrUpdate(ctx context.Context, analytics *entities.RouteAnalytics) error
+	// ComputeForDate aggregates the trips departing on the given day, one row per route
+	ComputeForDate(ctx context.Context, date time.Time) ([]*entities.RouteAnalytics, error)
+	// GetDepartureBreakdown aggregates a route's trips in the range by their departure time of day
+	GetDepartureBreakdown(ctx context.Context, routeID uuid.UUID, startDate, endDate time.Time) ([]*entities.RouteDepartureStats, error)
+}
+
+// TripAnalyticsRepository defines the interface for per-departure analytics operations
+type TripAnalyticsRepository interface {
+	CreateOrUpdate(ctx context.Context, analytics *entities.TripAnalytics) error
+	// ComputeForDate aggregates the trips departing on the given day, one row per trip
+	ComputeForDate(ctx context.Context, date time.Time) ([]*entities.TripAnalytics, error)
+	List(ctx context.Context, filter TripAnalyticsFilter, page, pageSize int) ([]*entities.TripAnalytics, int64, error)
+	// Each calls fn for every matching row, latest departure first, without loading them all into memory
+	Each(ctx context.Context, filter TripAnalyticsFilter, fn func(*entities.TripAnalytics) error) error
+}
+
+// TripAnalyticsFilter narrows trip analytics listings; empty fields are ignored
+type TripAnalyticsFilter struct {
+	RouteID *uuid.UUID
+	BusID   *uuid.UUID
+	From    *time.Time // Departure time, inclusive
+	To      *time.Time // Departure time, exclusive
+}
+
+// FunnelEventRepository defines the interface for search-to-booking funnel events
+type FunnelEventRepository interface {
+	Create(ctx context.Context, event *entities.FunnelEvent) error
+	// SessionForBooking returns the session the booking was created in, or "" when it was not tracked
+	SessionForBooking(ctx context.Context, bookingID uuid.UUID) (string, error)
+	// GetRouteFunnels counts the sessions reaching each step per route, most searched first
+	GetRouteFunnels(ctx context.Context, startDate, endDate time.Time) ([]*entities.RouteFunnel, error)
+	// GetUnmetDemand groups searches that returned no trips, most searched first
+	GetUnmetDemand(ctx context.Context, startDate, endDate time.Time, limit int) ([]*entities.UnmetDemand, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// AnalyticsBackfillRepository defines the interface for analytics backfill tasks
+type AnalyticsBackfillRepository interface {
+	Create(ctx context.Context, backfill *entities.AnalyticsBackfill) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.AnalyticsBackfill, error)
+	List(ctx context.Context, page, pageSize int) ([]*entities.AnalyticsBackfill, int64, error)
+	// GetActive returns the pending or running backfill, or nil when there is none
+	GetActive(ctx context.Context) (*entities.AnalyticsBackfill, error)
+	// ListStalled returns pending or running backfills whose heartbeat is older than staleBefore
+	ListStalled(ctx context.Context, staleBefore time.Time) ([]*entities.AnalyticsBackfill, error)
+	// Claim marks a pending or stalled backfill as running; false when another worker already has it
+	Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error)
+	// RecordProgress stores a finished day; false once the backfill is no longer running (e.g. cancelled)
+	RecordProgress(ctx context.Context, id uuid.UUID, processedDays int, day time.Time) (bool, error)
+	// Finish moves a running backfill to its final status
+	Finish(ctx context.Context, id uuid.UUID, status entities.AnalyticsBackfillStatus, errMsg *string) error
+	// Cancel stops a pending or running backfill; false when it had already finished
+	Cancel(ctx context.Context, id uuid.UUID) (bool, error)
+	// FindMissingDates lists the days in the range lacking booking, route or trip aggregates
+	FindMissingDates(ctx context.Context, startDate, endDate time.Time) ([]*entities.MissingAnalyticsDate, error)
 }
 
 // ReviewRepository defines the interface for review data operations
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type operatorScopeKey struct{}
+
+// WithOperatorScope restricts repository queries made with the context to one operator's data
+func WithOperatorScope(ctx context.Context, operatorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, operatorScopeKey{}, operatorID)
+}
+
+// OperatorScope returns the operator the context is restricted to, if any
+// Contexts without a scope (platform staff, customers, background jobs) see every operator
+func OperatorScope(ctx context.Context) (uuid.UUID, bool) {
+	operatorID, ok := ctx.Value(operatorScopeKey{}).(uuid.UUID)
+	return operatorID, ok && operatorID != uuid.Nil
+}
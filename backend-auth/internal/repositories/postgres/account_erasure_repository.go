@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	erasedName          = "Erased user"
+	erasedPassengerName = "Erased passenger"
+)
+
+type accountErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountErasureRepository creates a new account erasure repository
+func NewAccountErasureRepository(db *gorm.DB) repositories.AccountErasureRepository {
+	return &accountErasureRepository{db: db}
+}
+
+func (r *accountErasureRepository) Create(ctx context.Context, request *entities.AccountErasureRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+func (r *accountErasureRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.AccountErasureRequest, error) {
+	var request entities.AccountErasureRequest
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *accountErasureRepository) GetLatestForUser(ctx context.Context, userID uuid.UUID) (*entities.AccountErasureRequest, error) {
+	var request entities.AccountErasureRequest
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *accountErasureRepository) GetConfirmed(ctx context.Context, limit int) ([]*entities.AccountErasureRequest, error) {
+	var requests []*entities.AccountErasureRequest
+	err := r.db.WithContext(ctx).
+		Where("status = ?", entities.AccountErasureConfirmed).
+		Order("confirmed_at ASC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *accountErasureRepository) Update(ctx context.Context, request *entities.AccountErasureRequest) error {
+	return r.db.WithContext(ctx).Save(request).Error
+}
+
+// EraseUserData replaces personal data with placeholders and drops what is not needed for accounting
+// Guest bookings made with the account's email address are anonymized as well
+func (r *accountErasureRepository) EraseUserData(ctx context.Context, userID uuid.UUID, erasedAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+			return fmt.Errorf("user not found: %w", err)
+		}
+		placeholderEmail := fmt.Sprintf("erased-%s@erased.invalid", userID)
+
+		// Rebuilt per statement: the subquery matches on the original contact email,
+		// so the bookings themselves are updated after their passengers, tickets and payments
+		bookingIDs := func() *gorm.DB {
+			return tx.Model(&entities.Booking{}).
+				Select("id").
+				Where("user_id = ? OR LOWER(contact_email) = LOWER(?)", userID, user.Email)
+		}
+
+		steps := []func() error{
+			func() error {
+				return tx.Model(&entities.Passenger{}).
+					Where("booking_id IN (?)", bookingIDs()).
+					Updates(map[string]interface{}{
+						"full_name":     erasedPassengerName,
+						"id_number":     nil,
+						"phone":         nil,
+						"email":         nil,
+						"age":           nil,
+						"gender":        nil,
+						"special_needs": nil,
+					}).Error
+			},
+			func() error {
+				return tx.Model(&entities.Ticket{}).
+					Where("booking_id IN (?)", bookingIDs()).
+					Update("passenger_name", erasedPassengerName).Error
+			},
+			func() error {
+				// Payments stay for accounting; only the payer's device details are dropped
+				return tx.Model(&entities.Payment{}).
+					Where("booking_id IN (?)", bookingIDs()).
+					Updates(map[string]interface{}{"ip_address": nil, "user_agent": nil}).Error
+			},
+			func() error {
+				return tx.Where("user_id = ? OR booking_id IN (?)", userID, bookingIDs()).
+					Delete(&entities.Notification{}).Error
+			},
+			func() error {
+				return tx.Model(&entities.Booking{}).
+					Where("user_id = ? OR LOWER(contact_email) = LOWER(?)", userID, user.Email).
+					Updates(map[string]interface{}{
+						"contact_email": placeholderEmail,
+						"contact_name":  erasedName,
+						"contact_phone": "",
+						"notes":         nil,
+					}).Error
+			},
+			func() error {
+				// Ratings stay in trip statistics; the free text may identify the author
+				return tx.Model(&entities.Review{}).
+					Where("user_id = ?", userID).
+					Updates(map[string]interface{}{"title": "", "comment": ""}).Error
+			},
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.NotificationPreference{}).Error },
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.UserIdentity{}).Error },
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error },
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.PasswordResetToken{}).Error },
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.RefreshToken{}).Error },
+			func() error { return tx.Where("user_id = ?", userID).Delete(&entities.Session{}).Error },
+			func() error {
+				return tx.Model(&entities.User{}).
+					Where("id = ?", userID).
+					Updates(map[string]interface{}{
+						"email":                placeholderEmail,
+						"name":                 erasedName,
+						"phone":                "",
+						"password_hash":        "",
+						"oauth_id":             nil,
+						"oauth_provider":       nil,
+						"avatar":               nil,
+						"is_active":            false,
+						"email_verified":       false,
+						"verification_sent_at": nil,
+						"mfa_enabled":          false,
+						"mfa_secret":           "",
+						"deleted_at":           erasedAt,
+					}).Error
+			},
+		}
+		for _, step := range steps {
+			if err := step(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type analyticsBackfillRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsBackfillRepository creates a new analytics backfill repository
+func NewAnalyticsBackfillRepository(db *gorm.DB) repositories.AnalyticsBackfillRepository {
+	return &analyticsBackfillRepository{db: db}
+}
+
+func (r *analyticsBackfillRepository) Create(ctx context.Context, backfill *entities.AnalyticsBackfill) error {
+	return r.db.WithContext(ctx).Create(backfill).Error
+}
+
+func (r *analyticsBackfillRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.AnalyticsBackfill, error) {
+	var backfill entities.AnalyticsBackfill
+	if err := r.db.WithContext(ctx).First(&backfill, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+func (r *analyticsBackfillRepository) List(ctx context.Context, page, pageSize int) ([]*entities.AnalyticsBackfill, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entities.AnalyticsBackfill{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var backfills []*entities.AnalyticsBackfill
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&backfills).Error
+	return backfills, total, err
+}
+
+func (r *analyticsBackfillRepository) GetActive(ctx context.Context) (*entities.AnalyticsBackfill, error) {
+	var backfill entities.AnalyticsBackfill
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []entities.AnalyticsBackfillStatus{entities.BackfillPending, entities.BackfillRunning}).
+		Order("created_at").
+		First(&backfill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+func (r *analyticsBackfillRepository) ListStalled(ctx context.Context, staleBefore time.Time) ([]*entities.AnalyticsBackfill, error) {
+	var backfills []*entities.AnalyticsBackfill
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?", []entities.AnalyticsBackfillStatus{entities.BackfillPending, entities.BackfillRunning}, staleBefore).
+		Order("created_at").
+		Find(&backfills).Error
+	return backfills, err
+}
+
+func (r *analyticsBackfillRepository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&entities.AnalyticsBackfill{}).
+		Where("id = ?", id).
+		Where("status = ? OR (status = ? AND updated_at < ?)", entities.BackfillPending, entities.BackfillRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     entities.BackfillRunning,
+			"started_at": gorm.Expr("COALESCE(started_at, ?)", now),
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *analyticsBackfillRepository) RecordProgress(ctx context.Context, id uuid.UUID, processedDays int, day time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entities.AnalyticsBackfill{}).
+		Where("id = ? AND status = ?", id, entities.BackfillRunning).
+		Updates(map[string]interface{}{
+			"processed_days":      processedDays,
+			"last_processed_date": day,
+			"updated_at":          time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *analyticsBackfillRepository) Finish(ctx context.Context, id uuid.UUID, status entities.AnalyticsBackfillStatus, errMsg *string) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.AnalyticsBackfill{}).
+		Where("id = ? AND status = ?", id, entities.BackfillRunning).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"error":       errMsg,
+			"finished_at": time.Now(),
+		}).Error
+}
+
+func (r *analyticsBackfillRepository) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entities.AnalyticsBackfill{}).
+		Where("id = ? AND status IN ?", id, []entities.AnalyticsBackfillStatus{entities.BackfillPending, entities.BackfillRunning}).
+		Updates(map[string]interface{}{
+			"status":      entities.BackfillCancelled,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// missingDatesQuery checks each day of the range against the tables the nightly job fills
+// Day bounds come from the caller's midnights, matching how ComputeForDate selects departures
+const missingDatesQuery = `
+WITH days AS (
+	SELECT day_start, day_start + interval '1 day' AS day_end
+	FROM generate_series(CAST(? AS timestamptz), CAST(? AS timestamptz), interval '1 day') AS day_start
+), departures AS (
+	SELECT d.day_start, t.id, t.route_id
+	FROM days d
+	JOIN trips t ON t.deleted_at IS NULL AND t.start_time >= d.day_start AND t.start_time < d.day_end
+), coverage AS (
+	SELECT d.day_start AS date,
+		NOT EXISTS (
+			SELECT 1 FROM booking_analytics ba WHERE ba.date >= d.day_start AND ba.date < d.day_end
+		) AS booking_missing,
+		(SELECT COUNT(DISTINCT dep.route_id) FROM departures dep
+			WHERE dep.day_start = d.day_start AND NOT EXISTS (
+				SELECT 1 FROM route_analytics ra
+				WHERE ra.route_id = dep.route_id AND ra.date >= d.day_start AND ra.date < d.day_end
+			)) AS missing_routes,
+		(SELECT COUNT(*) FROM departures dep
+			WHERE dep.day_start = d.day_start AND NOT EXISTS (
+				SELECT 1 FROM trip_analytics ta WHERE ta.trip_id = dep.id
+			)) AS missing_trips
+	FROM days d
+)
+SELECT * FROM coverage
+WHERE booking_missing OR missing_routes > 0 OR missing_trips > 0
+ORDER BY date`
+
+func (r *analyticsBackfillRepository) FindMissingDates(ctx context.Context, startDate, endDate time.Time) ([]*entities.MissingAnalyticsDate, error) {
+	var missing []*entities.MissingAnalyticsDate
+	err := r.db.WithContext(ctx).Raw(missingDatesQuery, startDate, endDate).Scan(&missing).Error
+	return missing, err
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,7 +29,7 @@ func (r *bookingAnalyticsRepository) GetByDate(ctx context.Context, date time.Ti
 	var analytics entities.BookingAnalytics
 	// Truncate to date only for comparison
 	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
 		Where("DATE(date) = DATE(?)", dateOnly).
 		First(&analytics).Error
 	if err != nil {
@@ -39,7 +40,7 @@ func (r *bookingAnalyticsRepository) GetByDate(ctx context.Context, date time.Ti
 
 func (r *bookingAnalyticsRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entities.BookingAnalytics, error) {
 	var analytics []*entities.BookingAnalytics
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
 		Where("date >= ? AND date <= ?", startDate, endDate).
 		Order("date ASC").
 		Find(&analytics).Error
@@ -70,6 +71,36 @@ func (r *bookingAnalyticsRepository) CreateOrUpdate(ctx context.Context, analyti
 		Create(analytics).Error
 }
 
+func (r *bookingAnalyticsRepository) CreateOrUpdateForOperator(ctx context.Context, analytics *entities.OperatorBookingAnalytics) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "operator_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"total_bookings",
+				"confirmed_bookings",
+				"cancelled_bookings",
+				"pending_bookings",
+				"total_revenue",
+				"total_seats_booked",
+				"average_booking_value",
+				"conversion_rate",
+				"updated_at",
+			}),
+		}).
+		Create(analytics).Error
+}
+
+// scoped reads an operator's own daily rows instead of the platform totals when the context is operator-scoped
+// Both tables share their metric columns, so rows load into BookingAnalytics either way
+func (r *bookingAnalyticsRepository) scoped(ctx context.Context) *gorm.DB {
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		return r.db.WithContext(ctx).
+			Table(entities.OperatorBookingAnalytics{}.TableName()).
+			Where("operator_id = ?", operatorID)
+	}
+	return r.db.WithContext(ctx)
+}
+
 // Route analytics repository
 type routeAnalyticsRepository struct {
 	db *gorm.DB
@@ -87,7 +118,7 @@ func (r *routeAnalyticsRepository) Create(ctx context.Context, analytics *entiti
 func (r *routeAnalyticsRepository) GetByRouteAndDate(ctx context.Context, routeID uuid.UUID, date time.Time) (*entities.RouteAnalytics, error) {
 	var analytics entities.RouteAnalytics
 	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	err := r.db.WithContext(ctx).
+	err := scopeRouteOperator(ctx, r.db.WithContext(ctx)).
 		Where("route_id = ? AND DATE(date) = DATE(?)", routeID, dateOnly).
 		First(&analytics).Error
 	if err != nil {
@@ -98,7 +129,7 @@ func (r *routeAnalyticsRepository) GetByRouteAndDate(ctx context.Context, routeI
 
 func (r *routeAnalyticsRepository) GetByRouteIDAndDateRange(ctx context.Context, routeID uuid.UUID, startDate, endDate time.Time) ([]*entities.RouteAnalytics, error) {
 	var analytics []*entities.RouteAnalytics
-	err := r.db.WithContext(ctx).
+	err := scopeRouteOperator(ctx, r.db.WithContext(ctx)).
 		Where("route_id = ? AND date >= ? AND date <= ?", routeID, startDate, endDate).
 		Order("date ASC").
 		Find(&analytics).Error
@@ -109,7 +140,7 @@ func (r *routeAnalyticsRepository) GetTopRoutesByRevenue(ctx context.Context, st
 	var analytics []*entities.RouteAnalytics
 
 	// Aggregate by route_id and sum revenue
-	err := r.db.WithContext(ctx).
+	err := scopeRouteOperator(ctx, r.db.WithContext(ctx)).
 		Model(&entities.RouteAnalytics{}).
 		Select("route_id, SUM(total_revenue) as total_revenue, SUM(total_bookings) as total_bookings").
 		Where("date >= ? AND date <= ?", startDate, endDate).
@@ -126,7 +157,7 @@ func (r *routeAnalyticsRepository) GetTopRoutesByBookings(ctx context.Context, s
 	var analytics []*entities.RouteAnalytics
 
 	// Aggregate by route_id and sum bookings
-	err := r.db.WithContext(ctx).
+	err := scopeRouteOperator(ctx, r.db.WithContext(ctx)).
 		Model(&entities.RouteAnalytics{}).
 		Select("route_id, SUM(total_bookings) as total_bookings, SUM(total_revenue) as total_revenue").
 		Where("date >= ? AND date <= ?", startDate, endDate).
@@ -149,13 +180,321 @@ func (r *routeAnalyticsRepository) CreateOrUpdate(ctx context.Context, analytics
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "route_id"}, {Name: "date"}},
 			DoUpdates: clause.AssignmentColumns([]string{
+				"total_trips",
 				"total_bookings",
+				"cancelled_bookings",
 				"total_revenue",
 				"average_occupancy_rate",
 				"total_seats_booked",
 				"total_seats_available",
+				"average_rating",
+				"rating_count",
 				"updated_at",
 			}),
 		}).
 		Create(analytics).Error
 }
+
+// departureStatsQuery aggregates bookings, seats and reviews per trip before grouping by groupExpr
+// Joining bookings and reviews in separate subqueries keeps one trip's rows from multiplying the other's
+const departureStatsQuery = `
+WITH departures AS (
+	SELECT t.id, t.route_id, t.start_time, COALESCE(sm.total_seats, bu.total_seats, 0) AS capacity
+	FROM trips t
+	LEFT JOIN buses bu ON bu.id = t.bus_id
+	LEFT JOIN seat_maps sm ON sm.id = bu.seat_map_id
+	WHERE t.deleted_at IS NULL AND t.start_time >= ? AND t.start_time < ? %s
+), trip_bookings AS (
+	SELECT b.trip_id,
+		COUNT(*) AS total_bookings,
+		COUNT(*) FILTER (WHERE b.status = 'cancelled') AS cancelled_bookings,
+		COALESCE(SUM(b.total_amount) FILTER (WHERE b.status IN ('confirmed', 'completed')), 0) AS total_revenue,
+		COALESCE(SUM(b.total_seats) FILTER (WHERE b.status IN ('confirmed', 'completed')), 0) AS total_seats_booked
+	FROM bookings b
+	WHERE b.deleted_at IS NULL AND b.trip_id IN (SELECT id FROM departures)
+	GROUP BY b.trip_id
+), trip_reviews AS (
+	SELECT r.trip_id, SUM(r.rating) AS rating_sum, COUNT(*) AS rating_count
+	FROM reviews r
+	WHERE r.deleted_at IS NULL AND r.trip_id IN (SELECT id FROM departures)
+	GROUP BY r.trip_id
+)
+SELECT %s,
+	COUNT(d.id) AS total_trips,
+	COALESCE(SUM(tb.total_bookings), 0) AS total_bookings,
+	COALESCE(SUM(tb.cancelled_bookings), 0) AS cancelled_bookings,
+	COALESCE(SUM(tb.total_revenue), 0) AS total_revenue,
+	COALESCE(SUM(tb.total_seats_booked), 0) AS total_seats_booked,
+	COALESCE(SUM(d.capacity), 0) AS total_seats_available,
+	COALESCE(SUM(tr.rating_sum), 0) AS rating_sum,
+	COALESCE(SUM(tr.rating_count), 0) AS rating_count
+FROM departures d
+LEFT JOIN trip_bookings tb ON tb.trip_id = d.id
+LEFT JOIN trip_reviews tr ON tr.trip_id = d.id
+GROUP BY 1
+ORDER BY 1`
+
+// departureStatsRow is one group of departureStatsQuery
+type departureStatsRow struct {
+	GroupKey            string
+	TotalTrips          int
+	TotalBookings       int
+	CancelledBookings   int
+	TotalRevenue        float64
+	TotalSeatsBooked    int
+	TotalSeatsAvailable int
+	RatingSum           float64
+	RatingCount         int
+}
+
+func (row departureStatsRow) averageRating() float64 {
+	if row.RatingCount == 0 {
+		return 0
+	}
+	return row.RatingSum / float64(row.RatingCount)
+}
+
+func (r *routeAnalyticsRepository) ComputeForDate(ctx context.Context, date time.Time) ([]*entities.RouteAnalytics, error) {
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var rows []departureStatsRow
+	query := fmt.Sprintf(departureStatsQuery, "", "d.route_id::text AS group_key")
+	if err := r.db.WithContext(ctx).Raw(query, dateOnly, dateOnly.AddDate(0, 0, 1)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	analytics := make([]*entities.RouteAnalytics, 0, len(rows))
+	for _, row := range rows {
+		routeID, err := uuid.Parse(row.GroupKey)
+		if err != nil {
+			continue
+		}
+		occupancy := 0.0
+		if row.TotalSeatsAvailable > 0 {
+			occupancy = float64(row.TotalSeatsBooked) / float64(row.TotalSeatsAvailable) * 100
+		}
+		analytics = append(analytics, &entities.RouteAnalytics{
+			RouteID:              routeID,
+			Date:                 dateOnly,
+			TotalTrips:           row.TotalTrips,
+			TotalBookings:        row.TotalBookings,
+			CancelledBookings:    row.CancelledBookings,
+			TotalRevenue:         row.TotalRevenue,
+			AverageOccupancyRate: occupancy,
+			TotalSeatsBooked:     row.TotalSeatsBooked,
+			TotalSeatsAvailable:  row.TotalSeatsAvailable,
+			AverageRating:        row.averageRating(),
+			RatingCount:          row.RatingCount,
+		})
+	}
+	return analytics, nil
+}
+
+func (r *routeAnalyticsRepository) GetDepartureBreakdown(ctx context.Context, routeID uuid.UUID, startDate, endDate time.Time) ([]*entities.RouteDepartureStats, error) {
+	filter := "AND t.route_id = ?"
+	args := []interface{}{startDate, endDate, routeID}
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		filter += " AND t.route_id IN (SELECT id FROM routes WHERE operator_id = ?)"
+		args = append(args, operatorID)
+	}
+
+	var rows []departureStatsRow
+	query := fmt.Sprintf(departureStatsQuery, filter, "to_char(d.start_time, 'HH24:MI') AS group_key")
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]*entities.RouteDepartureStats, len(rows))
+	for i, row := range rows {
+		stats[i] = &entities.RouteDepartureStats{
+			DepartureTime:       row.GroupKey,
+			TotalTrips:          row.TotalTrips,
+			TotalBookings:       row.TotalBookings,
+			CancelledBookings:   row.CancelledBookings,
+			TotalRevenue:        row.TotalRevenue,
+			TotalSeatsBooked:    row.TotalSeatsBooked,
+			TotalSeatsAvailable: row.TotalSeatsAvailable,
+			AverageRating:       row.averageRating(),
+			RatingCount:         row.RatingCount,
+		}
+	}
+	return stats, nil
+}
+
+// scopeRouteOperator limits route analytics to routes owned by the context's operator
+func scopeRouteOperator(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		return db.Where("route_id IN (SELECT id FROM routes WHERE operator_id = ?)", operatorID)
+	}
+	return db
+}
+
+// Trip analytics repository
+type tripAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewTripAnalyticsRepository creates a new trip analytics repository
+func NewTripAnalyticsRepository(db *gorm.DB) repositories.TripAnalyticsRepository {
+	return &tripAnalyticsRepository{db: db}
+}
+
+func (r *tripAnalyticsRepository) CreateOrUpdate(ctx context.Context, analytics *entities.TripAnalytics) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "trip_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"route_id",
+				"bus_id",
+				"operator_id",
+				"departure_time",
+				"seat_capacity",
+				"seats_sold",
+				"load_factor",
+				"revenue",
+				"revenue_per_seat",
+				"distance_km",
+				"revenue_per_km",
+				"revenue_per_seat_km",
+				"checked_in",
+				"no_shows",
+				"cancelled_bookings",
+				"cancelled_seats",
+				"updated_at",
+			}),
+		}).
+		Create(analytics).Error
+}
+
+// tripStatsQuery aggregates bookings and tickets per trip departing in a time window
+// No-shows are only counted once the bus has left, before that unscanned tickets are just not boarded yet
+const tripStatsQuery = `
+WITH departures AS (
+	SELECT t.id, t.route_id, t.bus_id, t.operator_id, t.start_time, rt.distance,
+		COALESCE(sm.total_seats, bu.total_seats, 0) AS capacity
+	FROM trips t
+	JOIN routes rt ON rt.id = t.route_id
+	LEFT JOIN buses bu ON bu.id = t.bus_id
+	LEFT JOIN seat_maps sm ON sm.id = bu.seat_map_id
+	WHERE t.deleted_at IS NULL AND t.start_time >= ? AND t.start_time < ?
+), trip_bookings AS (
+	SELECT b.trip_id,
+		COALESCE(SUM(b.total_seats) FILTER (WHERE b.status IN ('confirmed', 'completed')), 0) AS seats_sold,
+		COALESCE(SUM(b.total_amount) FILTER (WHERE b.status IN ('confirmed', 'completed')), 0) AS revenue,
+		COUNT(*) FILTER (WHERE b.status = 'cancelled') AS cancelled_bookings,
+		COALESCE(SUM(b.total_seats) FILTER (WHERE b.status = 'cancelled'), 0) AS cancelled_seats
+	FROM bookings b
+	WHERE b.deleted_at IS NULL AND b.trip_id IN (SELECT id FROM departures)
+	GROUP BY b.trip_id
+), trip_tickets AS (
+	SELECT tk.trip_id,
+		COUNT(*) FILTER (WHERE tk.is_used) AS checked_in,
+		COUNT(*) FILTER (WHERE NOT tk.is_used) AS not_boarded
+	FROM tickets tk
+	JOIN bookings b ON b.id = tk.booking_id
+	WHERE tk.deleted_at IS NULL AND b.status IN ('confirmed', 'completed') AND tk.trip_id IN (SELECT id FROM departures)
+	GROUP BY tk.trip_id
+)
+SELECT d.id AS trip_id, d.route_id, d.bus_id, d.operator_id, d.start_time AS departure_time,
+	d.capacity AS seat_capacity, d.distance AS distance_km,
+	COALESCE(tb.seats_sold, 0) AS seats_sold,
+	COALESCE(tb.revenue, 0) AS revenue,
+	COALESCE(tb.cancelled_bookings, 0) AS cancelled_bookings,
+	COALESCE(tb.cancelled_seats, 0) AS cancelled_seats,
+	COALESCE(tt.checked_in, 0) AS checked_in,
+	CASE WHEN d.start_time < ? THEN COALESCE(tt.not_boarded, 0) ELSE 0 END AS no_shows
+FROM departures d
+LEFT JOIN trip_bookings tb ON tb.trip_id = d.id
+LEFT JOIN trip_tickets tt ON tt.trip_id = d.id
+ORDER BY d.start_time`
+
+func (r *tripAnalyticsRepository) ComputeForDate(ctx context.Context, date time.Time) ([]*entities.TripAnalytics, error) {
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var analytics []*entities.TripAnalytics
+	err := r.db.WithContext(ctx).
+		Raw(tripStatsQuery, dateOnly, dateOnly.AddDate(0, 0, 1), time.Now()).
+		Scan(&analytics).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range analytics {
+		if a.SeatCapacity > 0 {
+			a.LoadFactor = float64(a.SeatsSold) / float64(a.SeatCapacity) * 100
+			a.RevenuePerSeat = a.Revenue / float64(a.SeatCapacity)
+		}
+		if a.DistanceKm != nil && *a.DistanceKm > 0 {
+			a.RevenuePerKm = a.Revenue / *a.DistanceKm
+			if a.SeatCapacity > 0 {
+				a.RevenuePerSeatKm = a.Revenue / (float64(a.SeatCapacity) * *a.DistanceKm)
+			}
+		}
+	}
+	return analytics, nil
+}
+
+func (r *tripAnalyticsRepository) List(ctx context.Context, filter repositories.TripAnalyticsFilter, page, pageSize int) ([]*entities.TripAnalytics, int64, error) {
+	var analytics []*entities.TripAnalytics
+	var total int64
+
+	query := r.filtered(ctx, filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Route").
+		Preload("Bus").
+		Order("departure_time DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&analytics).Error
+	return analytics, total, err
+}
+
+func (r *tripAnalyticsRepository) Each(ctx context.Context, filter repositories.TripAnalyticsFilter, fn func(*entities.TripAnalytics) error) error {
+	for offset := 0; ; offset += tripAnalyticsExportBatchSize {
+		var batch []*entities.TripAnalytics
+		err := r.filtered(ctx, filter).
+			Preload("Route").
+			Preload("Bus").
+			Order("departure_time DESC, id").
+			Offset(offset).
+			Limit(tripAnalyticsExportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for _, analytics := range batch {
+			if err := fn(analytics); err != nil {
+				return err
+			}
+		}
+		if len(batch) < tripAnalyticsExportBatchSize {
+			return nil
+		}
+	}
+}
+
+// tripAnalyticsExportBatchSize is how many rows Each loads at a time
+const tripAnalyticsExportBatchSize = 500
+
+// filtered builds the listing query; operator staff only ever see their own operator's trips
+func (r *tripAnalyticsRepository) filtered(ctx context.Context, filter repositories.TripAnalyticsFilter) *gorm.DB {
+	query := scopeOperator(ctx, r.db.WithContext(ctx).Model(&entities.TripAnalytics{}), "operator_id")
+	if filter.RouteID != nil {
+		query = query.Where("route_id = ?", *filter.RouteID)
+	}
+	if filter.BusID != nil {
+		query = query.Where("bus_id = ?", *filter.BusID)
+	}
+	if filter.From != nil {
+		query = query.Where("departure_time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("departure_time < ?", *filter.To)
+	}
+	return query
+}
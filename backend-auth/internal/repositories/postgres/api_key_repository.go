@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new partner API key repository
+func NewAPIKeyRepository(db *gorm.DB) repositories.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context, partnerName string, includeRevoked bool) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	query := r.db.WithContext(ctx)
+	if partnerName != "" {
+		query = query.Where("LOWER(partner_name) = LOWER(?)", partnerName)
+	}
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+	err := query.Order("partner_name ASC, created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	return r.db.WithContext(ctx).Save(key).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// auditLogExportBatchSize is how many entries Each loads per query
+const auditLogExportBatchSize = 500
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) repositories.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *entities.AuditLog) error {
+	stampOperator(ctx, &entry.OperatorID)
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filter repositories.AuditLogFilter, page, pageSize int) ([]*entities.AuditLog, int64, error) {
+	var entries []*entities.AuditLog
+	var total int64
+
+	query := r.filtered(ctx, filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&entries).Error
+	return entries, total, err
+}
+
+func (r *auditLogRepository) Each(ctx context.Context, filter repositories.AuditLogFilter, fn func(*entities.AuditLog) error) error {
+	for offset := 0; ; offset += auditLogExportBatchSize {
+		var batch []*entities.AuditLog
+		err := r.filtered(ctx, filter).
+			Order("created_at DESC, id").
+			Offset(offset).
+			Limit(auditLogExportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for _, entry := range batch {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(batch) < auditLogExportBatchSize {
+			return nil
+		}
+	}
+}
+
+// filtered builds the listing query; operator staff only ever see their own operator's entries
+func (r *auditLogRepository) filtered(ctx context.Context, filter repositories.AuditLogFilter) *gorm.DB {
+	query := scopeOperator(ctx, r.db.WithContext(ctx).Model(&entities.AuditLog{}), "operator_id")
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type bookingAccessCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewBookingAccessCodeRepository creates a new booking access code repository
+func NewBookingAccessCodeRepository(db *gorm.DB) repositories.BookingAccessCodeRepository {
+	return &bookingAccessCodeRepository{db: db}
+}
+
+func (r *bookingAccessCodeRepository) Create(ctx context.Context, code *entities.BookingAccessCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+func (r *bookingAccessCodeRepository) GetLatestForUser(ctx context.Context, userID uuid.UUID, purpose entities.BookingAccessPurpose) (*entities.BookingAccessCode, error) {
+	var code entities.BookingAccessCode
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *bookingAccessCodeRepository) GetLatestForBooking(ctx context.Context, bookingID uuid.UUID, purpose entities.BookingAccessPurpose) (*entities.BookingAccessCode, error) {
+	var code entities.BookingAccessCode
+	err := r.db.WithContext(ctx).
+		Where("booking_ids = ? AND purpose = ?", bookingID.String(), purpose).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *bookingAccessCodeRepository) Update(ctx context.Context, code *entities.BookingAccessCode) error {
+	return r.db.WithContext(ctx).Save(code).Error
+}
+
+// MarkUsed sets used_at only if it is still empty, so a code cannot be redeemed twice concurrently
+func (r *bookingAccessCodeRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entities.BookingAccessCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *bookingAccessCodeRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now().AddDate(0, 0, -1)).
+		Delete(&entities.BookingAccessCode{}).Error
+}
@@ -69,18 +69,31 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID, p
 	return bookings, total, err
 }
 
-func (r *bookingRepository) GetByGuestContact(ctx context.Context, email, phone string) ([]*entities.Booking, error) {
+func (r *bookingRepository) GetClaimableByEmail(ctx context.Context, email string) ([]*entities.Booking, error) {
 	var bookings []*entities.Booking
 	err := r.db.WithContext(ctx).
-		Where("is_guest_booking = ? AND (contact_email = ? OR contact_phone = ?)", true, email, phone).
+		Preload("Trip").
+		Preload("Trip.Route").
+		Where("is_guest_booking = ? AND user_id IS NULL AND LOWER(contact_email) = LOWER(?)", true, email).
 		Order("created_at DESC").
 		Find(&bookings).Error
 	return bookings, err
 }
 
+func (r *bookingRepository) ClaimForUser(ctx context.Context, userID uuid.UUID, email string, bookingIDs []uuid.UUID) (int64, error) {
+	if len(bookingIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Model(&entities.Booking{}).
+		Where("id IN ? AND is_guest_booking = ? AND user_id IS NULL AND LOWER(contact_email) = LOWER(?)", bookingIDs, true, email).
+		Update("user_id", userID)
+	return result.RowsAffected, result.Error
+}
+
 func (r *bookingRepository) GetByTripID(ctx context.Context, tripID uuid.UUID) ([]*entities.Booking, error) {
 	var bookings []*entities.Booking
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("trip_id = ? AND status IN ?", tripID, []string{
 			string(entities.BookingStatusConfirmed),
 			string(entities.BookingStatusPending),
@@ -93,6 +106,15 @@ func (r *bookingRepository) Update(ctx context.Context, booking *entities.Bookin
 	return r.db.WithContext(ctx).Save(booking).Error
 }
 
+func (r *bookingRepository) UpdateWithOutbox(ctx context.Context, booking *entities.Booking, events []*entities.OutboxEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(booking).Error; err != nil {
+			return err
+		}
+		return insertOutboxEvents(tx, events)
+	})
+}
+
 func (r *bookingRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&entities.Booking{}, "id = ?", id).Error
 }
@@ -126,7 +148,7 @@ func (r *bookingRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (*
 // Used by background jobs for trip reminders and analytics
 func (r *bookingRepository) GetByStatus(ctx context.Context, status entities.BookingStatus) ([]*entities.Booking, error) {
 	var bookings []*entities.Booking
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("status = ?", status).
 		Preload("Trip").
 		Preload("Trip.Route").
@@ -140,7 +162,7 @@ func (r *bookingRepository) GetByStatus(ctx context.Context, status entities.Boo
 // Used for analytics and daily report generation
 func (r *bookingRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entities.Booking, error) {
 	var bookings []*entities.Booking
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("created_at >= ? AND created_at < ?", startDate, endDate).
 		Preload("Trip").
 		Preload("Trip.Route").
@@ -21,12 +21,13 @@ func NewBusRepository(db *gorm.DB) repositories.BusRepository {
 }
 
 func (r *busRepository) Create(ctx context.Context, bus *entities.Bus) error {
+	stampOperator(ctx, &bus.OperatorID)
 	return r.db.WithContext(ctx).Create(bus).Error
 }
 
 func (r *busRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Bus, error) {
 	var bus entities.Bus
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&bus).Error
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").Where("id = ?", id).First(&bus).Error
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +36,7 @@ func (r *busRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Bu
 
 func (r *busRepository) GetAll(ctx context.Context) ([]*entities.Bus, error) {
 	var buses []*entities.Bus
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Preload("SeatMap").
 		Where("deleted_at IS NULL").
 		Order("created_at DESC").
@@ -50,7 +51,7 @@ func (r *busRepository) GetAvailable(ctx context.Context, startTime, endTime tim
 	// Find buses that don't have any trips overlapping with the given time range
 	// A trip overlaps if NOT (trip.end_time <= startTime OR trip.start_time >= endTime)
 	// We want buses that DON'T have such overlapping trips
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("status = ? AND deleted_at IS NULL", entities.BusStatusActive).
 		Where(`id NOT IN (
 			SELECT bus_id FROM trips 
@@ -70,7 +71,7 @@ func (r *busRepository) Update(ctx context.Context, bus *entities.Bus) error {
 }
 
 func (r *busRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Model(&entities.Bus{}).
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
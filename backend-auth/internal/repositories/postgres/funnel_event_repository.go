@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type funnelEventRepository struct {
+	db *gorm.DB
+}
+
+// NewFunnelEventRepository creates a new funnel event repository
+func NewFunnelEventRepository(db *gorm.DB) repositories.FunnelEventRepository {
+	return &funnelEventRepository{db: db}
+}
+
+func (r *funnelEventRepository) Create(ctx context.Context, event *entities.FunnelEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *funnelEventRepository) SessionForBooking(ctx context.Context, bookingID uuid.UUID) (string, error) {
+	var sessions []string
+	err := r.db.WithContext(ctx).
+		Model(&entities.FunnelEvent{}).
+		Where("booking_id = ? AND event_type = ?", bookingID, entities.FunnelBookingCreated).
+		Order("created_at").
+		Limit(1).
+		Pluck("session_id", &sessions).Error
+	if err != nil || len(sessions) == 0 {
+		return "", err
+	}
+	return sessions[0], nil
+}
+
+// routeFunnelQuery counts distinct sessions per step and route
+// Searches carry no route, so they are matched to routes the same way trip search matches them
+const routeFunnelQuery = `
+WITH steps AS (
+	SELECT fe.session_id, fe.event_type, COALESCE(fe.route_id, r.id) AS route_id
+	FROM funnel_events fe
+	LEFT JOIN routes r ON fe.route_id IS NULL
+		AND r.origin = fe.origin AND r.destination = fe.destination AND r.deleted_at IS NULL
+	WHERE fe.created_at BETWEEN ? AND ?
+)
+SELECT ro.id AS route_id, ro.origin, ro.destination,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'search') AS searches,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'trip_view') AS trip_views,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'seats_reserved') AS seats_reserved,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'booking_created') AS bookings_created,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'payment_started') AS payments_started,
+	COUNT(DISTINCT s.session_id) FILTER (WHERE s.event_type = 'payment_completed') AS payments_completed
+FROM steps s
+JOIN routes ro ON ro.id = s.route_id
+WHERE 1 = 1 %s
+GROUP BY ro.id, ro.origin, ro.destination
+ORDER BY searches DESC, bookings_created DESC`
+
+func (r *funnelEventRepository) GetRouteFunnels(ctx context.Context, startDate, endDate time.Time) ([]*entities.RouteFunnel, error) {
+	filter := ""
+	args := []interface{}{startDate, endDate}
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		filter = "AND ro.operator_id = ?"
+		args = append(args, operatorID)
+	}
+
+	var funnels []*entities.RouteFunnel
+	err := r.db.WithContext(ctx).Raw(fmt.Sprintf(routeFunnelQuery, filter), args...).Scan(&funnels).Error
+	return funnels, err
+}
+
+// GetUnmetDemand is not operator-scoped: a search that found nothing belongs to no operator
+func (r *funnelEventRepository) GetUnmetDemand(ctx context.Context, startDate, endDate time.Time, limit int) ([]*entities.UnmetDemand, error) {
+	var demand []*entities.UnmetDemand
+	err := r.db.WithContext(ctx).
+		Model(&entities.FunnelEvent{}).
+		Select("origin, destination, travel_date, COUNT(*) AS searches, COUNT(DISTINCT session_id) AS sessions, MAX(created_at) AS last_search").
+		Where("event_type = ? AND result_count = 0", entities.FunnelSearch).
+		Where("created_at BETWEEN ? AND ?", startDate, endDate).
+		Group("origin, destination, travel_date").
+		Order("searches DESC, last_search DESC").
+		Limit(limit).
+		Scan(&demand).Error
+	return demand, err
+}
+
+func (r *funnelEventRepository) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&entities.FunnelEvent{}).Error
+}
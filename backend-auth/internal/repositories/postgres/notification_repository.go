@@ -125,6 +125,28 @@ func (r *notificationRepository) MarkAsFailed(ctx context.Context, id uuid.UUID,
 		}).Error
 }
 
+func (r *notificationRepository) GetByStatus(ctx context.Context, status entities.NotificationStatus, page, pageSize int) ([]*entities.Notification, int64, error) {
+	var notifs []*entities.Notification
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&entities.Notification{}).
+		Where("status = ?", string(status))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("failed_at DESC NULLS LAST, created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&notifs).Error
+
+	return notifs, total, err
+}
+
 // Notification preference repository
 type notificationPreferenceRepository struct {
 	db *gorm.DB
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationTemplateRepository creates a new notification template repository
+func NewNotificationTemplateRepository(db *gorm.DB) repositories.NotificationTemplateRepository {
+	return &notificationTemplateRepository{db: db}
+}
+
+func (r *notificationTemplateRepository) Create(ctx context.Context, template *entities.NotificationTemplate) error {
+	return r.db.WithContext(ctx).Create(template).Error
+}
+
+func (r *notificationTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.NotificationTemplate, error) {
+	var template entities.NotificationTemplate
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *notificationTemplateRepository) GetActive(ctx context.Context, notifType entities.NotificationType, locale string) (*entities.NotificationTemplate, error) {
+	var template entities.NotificationTemplate
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND locale = ? AND is_active = ?", string(notifType), locale, true).
+		Order("version DESC").
+		First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateVersion numbers, inserts and activates the template in one transaction
+// Existing versions are locked so concurrent saves for the same type and locale queue up;
+// the unique (type, locale, version) index rejects a clash on the very first version
+func (r *notificationTemplateRepository) CreateVersion(ctx context.Context, template *entities.NotificationTemplate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var versions []int
+		if err := tx.Model(&entities.NotificationTemplate{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("type = ? AND locale = ?", string(template.Type), template.Locale).
+			Pluck("version", &versions).Error; err != nil {
+			return err
+		}
+
+		template.Version = 1
+		for _, v := range versions {
+			if v >= template.Version {
+				template.Version = v + 1
+			}
+		}
+		template.IsActive = true
+
+		if err := tx.Model(&entities.NotificationTemplate{}).
+			Where("type = ? AND locale = ?", string(template.Type), template.Locale).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(template).Error
+	})
+}
+
+func (r *notificationTemplateRepository) GetVersions(ctx context.Context, notifType entities.NotificationType, locale string) ([]*entities.NotificationTemplate, error) {
+	var templates []*entities.NotificationTemplate
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND locale = ?", string(notifType), locale).
+		Order("version DESC").
+		Find(&templates).Error
+	return templates, err
+}
+
+func (r *notificationTemplateRepository) GetAllActive(ctx context.Context, notifType *entities.NotificationType) ([]*entities.NotificationTemplate, error) {
+	var templates []*entities.NotificationTemplate
+	query := r.db.WithContext(ctx).Where("is_active = ?", true)
+	if notifType != nil {
+		query = query.Where("type = ?", string(*notifType))
+	}
+	err := query.Order("type ASC, locale ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *notificationTemplateRepository) Activate(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var template entities.NotificationTemplate
+		if err := tx.Where("id = ?", id).First(&template).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entities.NotificationTemplate{}).
+			Where("type = ? AND locale = ?", string(template.Type), template.Locale).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entities.NotificationTemplate{}).
+			Where("id = ?", id).
+			Update("is_active", true).Error
+	})
+}
+
+func (r *notificationTemplateRepository) Deactivate(ctx context.Context, notifType entities.NotificationType, locale string) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.NotificationTemplate{}).
+		Where("type = ? AND locale = ?", string(notifType), locale).
+		Update("is_active", false).Error
+}
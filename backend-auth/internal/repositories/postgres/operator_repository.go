@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+type operatorRepository struct {
+	db *gorm.DB
+}
+
+// NewOperatorRepository creates a new operator repository
+func NewOperatorRepository(db *gorm.DB) repositories.OperatorRepository {
+	return &operatorRepository{db: db}
+}
+
+func (r *operatorRepository) Create(ctx context.Context, operator *entities.Operator) error {
+	return r.db.WithContext(ctx).Create(operator).Error
+}
+
+func (r *operatorRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Operator, error) {
+	var operator entities.Operator
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "id").
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&operator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &operator, nil
+}
+
+func (r *operatorRepository) GetAll(ctx context.Context) ([]*entities.Operator, error) {
+	var operators []*entities.Operator
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "id").
+		Where("deleted_at IS NULL").
+		Order("name ASC").
+		Find(&operators).Error
+	return operators, err
+}
+
+func (r *operatorRepository) Update(ctx context.Context, operator *entities.Operator) error {
+	return r.db.WithContext(ctx).Save(operator).Error
+}
+
+func (r *operatorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.Operator{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"is_active":  false,
+		}).Error
+}
@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+// scopeOperator limits a query to the context's operator; column names the owning operator column
+func scopeOperator(ctx context.Context, db *gorm.DB, column string) *gorm.DB {
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		return db.Where(column+" = ?", operatorID)
+	}
+	return db
+}
+
+// stampOperator makes records created by operator-scoped staff belong to their operator
+func stampOperator(ctx context.Context, operatorID **uuid.UUID) {
+	if scoped, ok := repositories.OperatorScope(ctx); ok {
+		*operatorID = &scoped
+	}
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) repositories.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// insertOutboxEvents records events inside an existing transaction
+// Events whose dedup key already exists are skipped, so repeated state changes never duplicate side effects
+func insertOutboxEvents(tx *gorm.DB, events []*entities.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(&events).Error
+}
+
+func (r *outboxRepository) Create(ctx context.Context, event *entities.OutboxEvent) error {
+	return insertOutboxEvents(r.db.WithContext(ctx), []*entities.OutboxEvent{event})
+}
+
+func (r *outboxRepository) ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboxEvent, error) {
+	var events []*entities.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND available_at <= ?",
+				[]entities.OutboxEventStatus{entities.OutboxEventPending, entities.OutboxEventFailed}, now).
+			Order("available_at ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(events))
+		leaseUntil := now.Add(lease)
+		for i, event := range events {
+			ids[i] = event.ID
+			event.Attempts++
+			event.AvailableAt = leaseUntil
+		}
+
+		return tx.Model(&entities.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"available_at": leaseUntil,
+			}).Error
+	})
+	return events, err
+}
+
+func (r *outboxRepository) MarkDispatched(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        entities.OutboxEventDispatched,
+			"dispatched_at": time.Now(),
+			"last_error":    nil,
+		}).Error
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string, retryAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       entities.OutboxEventFailed,
+			"last_error":   errMsg,
+			"available_at": retryAt,
+		}).Error
+}
+
+func (r *outboxRepository) MarkDead(ctx context.Context, id uuid.UUID, errMsg string) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     entities.OutboxEventDead,
+			"last_error": errMsg,
+		}).Error
+}
+
+func (r *outboxRepository) DeleteDispatchedBefore(ctx context.Context, cutoff time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("status = ? AND dispatched_at < ?", entities.OutboxEventDispatched, cutoff).
+		Delete(&entities.OutboxEvent{}).Error
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type paymentReconciliationRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentReconciliationRepository creates a new payment reconciliation repository
+func NewPaymentReconciliationRepository(db *gorm.DB) repositories.PaymentReconciliationRepository {
+	return &paymentReconciliationRepository{db: db}
+}
+
+func (r *paymentReconciliationRepository) Create(ctx context.Context, entry *entities.PaymentReconciliation) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *paymentReconciliationRepository) Record(ctx context.Context, entry *entities.PaymentReconciliation) error {
+	if entry.Action != entities.ReconciliationActionFlagged && entry.Action != entities.ReconciliationActionError {
+		return r.Create(ctx, entry)
+	}
+
+	// Only rows from the same day are refreshed so earlier daily reports keep their entries
+	checked := entry.CheckedAt
+	dayStart := time.Date(checked.Year(), checked.Month(), checked.Day(), 0, 0, 0, 0, checked.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entities.PaymentReconciliation
+		err := tx.Where("payment_id = ? AND action = ?", entry.PaymentID, entry.Action).
+			Where("checked_at >= ? AND checked_at < ?", dayStart, dayEnd).
+			Order("checked_at DESC").
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(entry).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		entry.ID = existing.ID
+		entry.CreatedAt = existing.CreatedAt
+		entry.CheckCount = existing.CheckCount + 1
+		return tx.Model(&existing).Updates(map[string]interface{}{
+			"local_status":    entry.LocalStatus,
+			"provider_status": entry.ProviderStatus,
+			"booking_status":  entry.BookingStatus,
+			"amount":          entry.Amount,
+			"provider_amount": entry.ProviderAmount,
+			"mismatch":        entry.Mismatch,
+			"note":            entry.Note,
+			"checked_at":      entry.CheckedAt,
+			"check_count":     entry.CheckCount,
+		}).Error
+	})
+}
+
+func (r *paymentReconciliationRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time, mismatchOnly bool) ([]*entities.PaymentReconciliation, error) {
+	var entries []*entities.PaymentReconciliation
+	query := r.db.WithContext(ctx).
+		Where("checked_at >= ? AND checked_at < ?", startDate, endDate)
+	if mismatchOnly {
+		query = query.Where("mismatch = ?", true)
+	}
+	err := query.Order("checked_at ASC").Find(&entries).Error
+	return entries, err
+}
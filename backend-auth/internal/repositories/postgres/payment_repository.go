@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type paymentRepository struct {
@@ -71,6 +73,18 @@ func (r *paymentRepository) Update(ctx context.Context, payment *entities.Paymen
 	return r.db.WithContext(ctx).Save(payment).Error
 }
 
+func (r *paymentRepository) CompleteWithOutbox(ctx context.Context, payment *entities.Payment, booking *entities.Booking, events []*entities.OutboxEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(payment).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(booking).Error; err != nil {
+			return err
+		}
+		return insertOutboxEvents(tx, events)
+	})
+}
+
 func (r *paymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&entities.Payment{}, "id = ?", id).Error
 }
@@ -139,12 +153,44 @@ func (r *paymentWebhookLogRepository) Update(ctx context.Context, log *entities.
 	return r.db.WithContext(ctx).Save(log).Error
 }
 
-func (r *paymentWebhookLogRepository) GetPendingLogs(ctx context.Context) ([]*entities.PaymentWebhookLog, error) {
+func (r *paymentWebhookLogRepository) GetPendingLogs(ctx context.Context, maxRetries int, createdBefore time.Time, limit int) ([]*entities.PaymentWebhookLog, error) {
 	var logs []*entities.PaymentWebhookLog
 	err := r.db.WithContext(ctx).
-		Where("processed_status = ? AND retry_count < 3", "pending").
+		Where("processed_status IN ? AND retry_count < ?",
+			[]string{entities.WebhookStatusPending, entities.WebhookStatusFailed}, maxRetries).
+		Where("created_at < ?", createdBefore).
+		Where("next_retry_at IS NULL OR next_retry_at <= ?", time.Now()).
 		Order("created_at ASC").
-		Limit(100).
+		Limit(limit).
 		Find(&logs).Error
 	return logs, err
 }
+
+func (r *paymentWebhookLogRepository) List(ctx context.Context, filter repositories.WebhookLogFilter, page, pageSize int) ([]*entities.PaymentWebhookLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entities.PaymentWebhookLog{})
+	if filter.Status != "" {
+		query = query.Where("processed_status = ?", filter.Status)
+	}
+	if filter.PaymentID != nil {
+		query = query.Where("payment_id = ?", *filter.PaymentID)
+	}
+	if filter.ExternalPaymentID != "" {
+		query = query.Where("external_payment_id = ?", filter.ExternalPaymentID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*entities.PaymentWebhookLog
+	err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	return logs, total, err
+}
@@ -42,6 +42,51 @@ func (r *refreshTokenRepository) GetByToken(ctx context.Context, token string) (
 	return &refreshToken, nil
 }
 
+func (r *refreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.RefreshToken, error) {
+	var refreshToken entities.RefreshToken
+	if err := r.db.WithContext(ctx).First(&refreshToken, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string) (*entities.RefreshToken, error) {
+	var refreshToken entities.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&refreshToken).Error; err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// Rotate replaces current with next in one transaction
+// The conditional update lets only one of several concurrent refreshes rotate the token
+func (r *refreshTokenRepository) Rotate(ctx context.Context, current, next *entities.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND is_revoked = false", current.ID).
+			Updates(map[string]interface{}{
+				"is_revoked":     true,
+				"revoked_at":     now,
+				"rotated_at":     now,
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
 func (r *refreshTokenRepository) Revoke(ctx context.Context, token string) error {
 	return r.db.WithContext(ctx).
 		Model(&entities.RefreshToken{}).
@@ -53,13 +98,23 @@ func (r *refreshTokenRepository) Revoke(ctx context.Context, token string) error
 }
 
 func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Model(&entities.RefreshToken{}).
-		Where("user_id = ? AND is_revoked = false", userID).
-		Updates(map[string]interface{}{
-			"is_revoked": true,
-			"revoked_at": time.Now(),
-		}).Error
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = false", userID).
+			Updates(map[string]interface{}{
+				"is_revoked": true,
+				"revoked_at": now,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"revoked_at":     now,
+				"revoked_reason": entities.SessionRevokedAllDevice,
+			}).Error
+	})
 }
 
 func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
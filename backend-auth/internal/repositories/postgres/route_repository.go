@@ -21,12 +21,13 @@ func NewRouteRepository(db *gorm.DB) repositories.RouteRepository {
 }
 
 func (r *routeRepository) Create(ctx context.Context, route *entities.Route) error {
+	stampOperator(ctx, &route.OperatorID)
 	return r.db.WithContext(ctx).Create(route).Error
 }
 
 func (r *routeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Route, error) {
 	var route entities.Route
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&route).Error
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").Where("id = ?", id).First(&route).Error
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +36,7 @@ func (r *routeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.
 
 func (r *routeRepository) GetAll(ctx context.Context) ([]*entities.Route, error) {
 	var routes []*entities.Route
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("deleted_at IS NULL").
 		Order("origin ASC, destination ASC").
 		Find(&routes).Error
@@ -44,7 +45,7 @@ func (r *routeRepository) GetAll(ctx context.Context) ([]*entities.Route, error)
 
 func (r *routeRepository) GetActiveRoutes(ctx context.Context) ([]*entities.Route, error) {
 	var routes []*entities.Route
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("is_active = ? AND deleted_at IS NULL", true).
 		Order("origin ASC, destination ASC").
 		Find(&routes).Error
@@ -56,7 +57,7 @@ func (r *routeRepository) Update(ctx context.Context, route *entities.Route) err
 }
 
 func (r *routeRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Model(&entities.Route{}).
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
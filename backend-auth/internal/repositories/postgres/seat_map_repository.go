@@ -22,12 +22,13 @@ func NewSeatMapRepository(db *gorm.DB) repositories.SeatMapRepository {
 }
 
 func (r *seatMapRepository) Create(ctx context.Context, seatMap *entities.SeatMap) error {
+	stampOperator(ctx, &seatMap.OperatorID)
 	return r.db.WithContext(ctx).Create(seatMap).Error
 }
 
 func (r *seatMapRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.SeatMap, error) {
 	var seatMap entities.SeatMap
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("id = ? AND deleted_at IS NULL", id).
 		First(&seatMap).Error
 	if err != nil {
@@ -38,7 +39,7 @@ func (r *seatMapRepository) GetByID(ctx context.Context, id uuid.UUID) (*entitie
 
 func (r *seatMapRepository) GetAll(ctx context.Context) ([]*entities.SeatMap, error) {
 	var seatMaps []*entities.SeatMap
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("deleted_at IS NULL").
 		Order("created_at DESC").
 		Find(&seatMaps).Error
@@ -71,7 +72,7 @@ func (r *seatMapRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *seatMapRepository) GetWithSeats(ctx context.Context, id uuid.UUID) (*entities.SeatMap, error) {
 	var seatMap entities.SeatMap
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Preload("Seats", func(db *gorm.DB) *gorm.DB {
 			return db.Order("row ASC, \"column\" ASC")
 		}).
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(db *gorm.DB) repositories.SecurityEventRepository {
+	return &securityEventRepository{db: db}
+}
+
+func (r *securityEventRepository) Create(ctx context.Context, event *entities.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *securityEventRepository) List(ctx context.Context, filter repositories.SecurityEventFilter, page, pageSize int) ([]*entities.SecurityEvent, int64, error) {
+	var events []*entities.SecurityEvent
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&entities.SecurityEvent{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", filter.Email)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new device session repository
+func NewSessionRepository(db *gorm.DB) repositories.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	var session entities.Session
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	var sessions []*entities.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress string, expiresAt time.Time) error {
+	updates := map[string]interface{}{
+		"last_used_at": time.Now(),
+		"expires_at":   expiresAt,
+	}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+	return r.db.WithContext(ctx).
+		Model(&entities.Session{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, reason string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"revoked_at":     now,
+				"revoked_reason": reason,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.RefreshToken{}).
+			Where("session_id = ? AND is_revoked = false", id).
+			Updates(map[string]interface{}{
+				"is_revoked": true,
+				"revoked_at": now,
+			}).Error
+	})
+}
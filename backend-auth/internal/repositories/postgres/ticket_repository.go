@@ -58,6 +58,17 @@ func (r *ticketRepository) GetByBookingID(ctx context.Context, bookingID uuid.UU
 	return tickets, err
 }
 
+func (r *ticketRepository) GetByTripAndPassenger(ctx context.Context, tripID, passengerID uuid.UUID) (*entities.Ticket, error) {
+	var ticket entities.Ticket
+	err := r.db.WithContext(ctx).
+		Where("trip_id = ? AND passenger_id = ?", tripID, passengerID).
+		First(&ticket).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
 func (r *ticketRepository) BulkCreate(ctx context.Context, tickets []*entities.Ticket) error {
 	if len(tickets) == 0 {
 		return nil
@@ -79,3 +90,10 @@ func (r *ticketRepository) MarkAsUsed(ctx context.Context, ticketNumber string)
 			"used_at": now,
 		}).Error
 }
+
+func (r *ticketRepository) MarkEmailSent(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.Ticket{}).
+		Where("id = ? AND email_sent_at IS NULL", id).
+		Update("email_sent_at", time.Now()).Error
+}
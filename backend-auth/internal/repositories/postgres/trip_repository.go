@@ -23,12 +23,13 @@ func NewTripRepository(db *gorm.DB) repositories.TripRepository {
 }
 
 func (r *tripRepository) Create(ctx context.Context, trip *entities.Trip) error {
+	stampOperator(ctx, &trip.OperatorID)
 	return r.db.WithContext(ctx).Create(trip).Error
 }
 
 func (r *tripRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Trip, error) {
 	var trip entities.Trip
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Preload("Route").
 		Preload("Bus").
 		Where("id = ?", id).
@@ -41,7 +42,7 @@ func (r *tripRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.T
 
 func (r *tripRepository) GetAll(ctx context.Context) ([]*entities.Trip, error) {
 	var trips []*entities.Trip
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Preload("Route").
 		Preload("Bus").
 		Where("deleted_at IS NULL").
@@ -68,7 +69,7 @@ func (r *tripRepository) AssignBus(ctx context.Context, tripID, busID uuid.UUID)
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 1. Get the trip
 		var trip entities.Trip
-		if err := tx.Where("id = ?", tripID).First(&trip).Error; err != nil {
+		if err := scopeOperator(ctx, tx, "operator_id").Where("id = ?", tripID).First(&trip).Error; err != nil {
 			return fmt.Errorf("trip not found: %w", err)
 		}
 
@@ -82,6 +83,9 @@ func (r *tripRepository) AssignBus(ctx context.Context, tripID, busID uuid.UUID)
 		if err := tx.Where("id = ? AND status = ?", busID, entities.BusStatusActive).First(&bus).Error; err != nil {
 			return fmt.Errorf("bus not found or not active: %w", err)
 		}
+		if !entities.SameOperator(bus.OperatorID, trip.OperatorID) {
+			return fmt.Errorf("bus belongs to a different operator than the trip")
+		}
 
 		// 4. Check for schedule conflicts
 		var conflictingTrips []*entities.Trip
@@ -114,7 +118,7 @@ func (r *tripRepository) Update(ctx context.Context, trip *entities.Trip) error
 }
 
 func (r *tripRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Model(&entities.Trip{}).
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
@@ -293,7 +297,7 @@ func (r *tripRepository) GetRelatedTrips(ctx context.Context, tripID uuid.UUID,
 
 // UpdateStatus updates the operational status of a trip (departed, arrived, cancelled)
 func (r *tripRepository) UpdateStatus(ctx context.Context, tripID uuid.UUID, status entities.TripStatus) error {
-	result := r.db.WithContext(ctx).
+	result := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Model(&entities.Trip{}).
 		Where("id = ?", tripID).
 		Update("status", status)
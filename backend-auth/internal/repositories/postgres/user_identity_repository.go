@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new linked identity repository
+func NewUserIdentityRepository(db *gorm.DB) repositories.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *userIdentityRepository) GetByProviderUserID(ctx context.Context, provider entities.OAuthProvider, providerUserID string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND provider_user_id = ?", provider, providerUserID).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.UserIdentity, error) {
+	var identities []*entities.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error
+	return identities, err
+}
+
+func (r *userIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&entities.UserIdentity{}).
+		Where("id = ?", id).
+		Update("last_login_at", at).Error
+}
+
+func (r *userIdentityRepository) Delete(ctx context.Context, userID uuid.UUID, provider entities.OAuthProvider) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&entities.UserIdentity{}).Error
+}
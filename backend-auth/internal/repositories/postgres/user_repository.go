@@ -42,7 +42,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entitie
 func (r *userRepository) GetByOAuthID(ctx context.Context, oauthID string, provider string) (*entities.User, error) {
 	var user entities.User
 	err := r.db.WithContext(ctx).
-		Where("oauth_id = ? AND oauth_provider = ? AND deleted_at IS NULL", oauthID, provider).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.provider_user_id = ? AND users.deleted_at IS NULL", provider, oauthID).
 		First(&user).Error
 	if err != nil {
 		return nil, err
@@ -63,7 +64,7 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // GetByRole returns all users with a specific role
 func (r *userRepository) GetByRole(ctx context.Context, role entities.Role) ([]*entities.User, error) {
 	var users []*entities.User
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("role = ? AND deleted_at IS NULL", role).
 		Order("created_at DESC").
 		Find(&users).Error
@@ -76,7 +77,7 @@ func (r *userRepository) GetByRole(ctx context.Context, role entities.Role) ([]*
 // GetAll returns all users (excluding deleted)
 func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
 	var users []*entities.User
-	err := r.db.WithContext(ctx).
+	err := scopeOperator(ctx, r.db.WithContext(ctx), "operator_id").
 		Where("deleted_at IS NULL").
 		Order("created_at DESC").
 		Find(&users).Error
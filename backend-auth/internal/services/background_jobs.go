@@ -27,6 +27,7 @@ type BackgroundJobScheduler struct {
 	notificationPrefRepo    repositories.NotificationPreferenceRepository
 	bookingAnalyticsRepo    repositories.BookingAnalyticsRepository
 	routeAnalyticsRepo      repositories.RouteAnalyticsRepository
+	tripAnalyticsRepo       repositories.TripAnalyticsRepository
 	tripRepo                repositories.TripRepository
 	seatReservationRepo     repositories.SeatReservationRepository
 	notificationQueue       *NotificationQueue
@@ -37,11 +38,24 @@ type BackgroundJobScheduler struct {
 	tripReminderHours    int // Hours before trip to send reminder (default: 24)
 	cleanupRetentionDays int // Days to retain old logs (default: 30)
 
+	// Jobs registered by other layers (e.g. usecases) before Start
+	extraJobs []scheduledJob
+
 	// Control
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// scheduledJob is a job registered through RegisterPeriodicJob or RegisterDailyJob
+type scheduledJob struct {
+	name     string
+	interval time.Duration // Periodic jobs
+	hour     int           // Daily jobs
+	minute   int
+	daily    bool
+	jobFunc  func() error
+}
+
 // NewBackgroundJobScheduler creates a new background job scheduler
 func NewBackgroundJobScheduler(
 	bookingRepo repositories.BookingRepository,
@@ -50,6 +64,7 @@ func NewBackgroundJobScheduler(
 	notificationPrefRepo repositories.NotificationPreferenceRepository,
 	bookingAnalyticsRepo repositories.BookingAnalyticsRepository,
 	routeAnalyticsRepo repositories.RouteAnalyticsRepository,
+	tripAnalyticsRepo repositories.TripAnalyticsRepository,
 	tripRepo repositories.TripRepository,
 	seatReservationRepo repositories.SeatReservationRepository,
 	notificationQueue *NotificationQueue,
@@ -64,6 +79,7 @@ func NewBackgroundJobScheduler(
 		notificationPrefRepo:    notificationPrefRepo,
 		bookingAnalyticsRepo:    bookingAnalyticsRepo,
 		routeAnalyticsRepo:      routeAnalyticsRepo,
+		tripAnalyticsRepo:       tripAnalyticsRepo,
 		tripRepo:                tripRepo,
 		seatReservationRepo:     seatReservationRepo,
 		notificationQueue:       notificationQueue,
@@ -96,9 +112,28 @@ func (s *BackgroundJobScheduler) Start() {
 	// Job 5: Cleanup expired data (runs at 3 AM daily)
 	go s.runDaily("CleanupExpiredData", 3, 0, s.cleanupExpiredData)
 
+	for _, job := range s.extraJobs {
+		if job.daily {
+			go s.runDaily(job.name, job.hour, job.minute, job.jobFunc)
+		} else {
+			go s.runPeriodically(job.name, job.interval, job.jobFunc)
+		}
+	}
+
 	log.Println("Background job scheduler started successfully")
 }
 
+// RegisterPeriodicJob adds a job that runs every interval once the scheduler starts
+// Used for jobs whose logic lives outside the services layer; must be called before Start
+func (s *BackgroundJobScheduler) RegisterPeriodicJob(name string, interval time.Duration, jobFunc func() error) {
+	s.extraJobs = append(s.extraJobs, scheduledJob{name: name, interval: interval, jobFunc: jobFunc})
+}
+
+// RegisterDailyJob adds a job that runs once a day at hour:minute; must be called before Start
+func (s *BackgroundJobScheduler) RegisterDailyJob(name string, hour, minute int, jobFunc func() error) {
+	s.extraJobs = append(s.extraJobs, scheduledJob{name: name, hour: hour, minute: minute, daily: true, jobFunc: jobFunc})
+}
+
 // Stop gracefully shuts down all background jobs
 func (s *BackgroundJobScheduler) Stop() {
 	log.Println("Stopping background job scheduler...")
@@ -298,39 +333,85 @@ func (s *BackgroundJobScheduler) computeDailyAnalytics() error {
 	// Compute for yesterday
 	now := time.Now()
 	startOfYesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
-	endOfYesterday := startOfYesterday.Add(24 * time.Hour)
 
-	// Get all bookings from yesterday
-	bookings, err := s.bookingRepo.GetByDateRange(ctx, startOfYesterday, endOfYesterday)
+	// Route and trip aggregates are still refreshed when the booking totals fail
+	bookingAnalytics, bookingErr := s.computeBookingAnalytics(ctx, startOfYesterday)
+	if bookingErr != nil {
+		log.Printf("Error computing booking analytics: %v", bookingErr)
+	}
+
+	// Store route and trip analytics by departure date. Recent days are recomputed too,
+	// since reviews, check-ins and late cancellations keep arriving after a trip has left
+	for i := 0; i < analyticsLookbackDays; i++ {
+		day := startOfYesterday.AddDate(0, 0, -i)
+		if err := s.computeRouteAnalytics(ctx, day); err != nil {
+			log.Printf("Error computing route analytics: %v", err)
+		}
+		if err := s.computeTripAnalytics(ctx, day); err != nil {
+			log.Printf("Error computing trip analytics: %v", err)
+		}
+	}
+
+	if bookingErr != nil {
+		return bookingErr
+	}
+
+	log.Printf("Daily analytics computed: %d bookings, %.2f revenue, %.1f%% conversion",
+		bookingAnalytics.TotalBookings, bookingAnalytics.TotalRevenue, bookingAnalytics.ConversionRate)
+
+	return nil
+}
+
+// RecomputeAnalytics rebuilds the booking, route and trip aggregates of one day
+// Rows are upserted, so running it again for the same day replaces rather than duplicates them
+func (s *BackgroundJobScheduler) RecomputeAnalytics(ctx context.Context, date time.Time) error {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	if _, err := s.computeBookingAnalytics(ctx, day); err != nil {
+		return err
+	}
+	if err := s.computeRouteAnalytics(ctx, day); err != nil {
+		return err
+	}
+	return s.computeTripAnalytics(ctx, day)
+}
+
+// computeBookingAnalytics stores the platform-wide and per-operator booking totals of the bookings made on day
+func (s *BackgroundJobScheduler) computeBookingAnalytics(ctx context.Context, day time.Time) (*entities.BookingAnalytics, error) {
+	bookings, err := s.bookingRepo.GetByDateRange(ctx, day, day.AddDate(0, 0, 1))
 	if err != nil {
-		return fmt.Errorf("failed to get bookings for analytics: %w", err)
+		return nil, fmt.Errorf("failed to get bookings for analytics: %w", err)
 	}
 
 	// Aggregate booking analytics
 	var totalBookings, confirmedBookings, cancelledBookings int
 	var totalRevenue float64
-	routeStats := make(map[uuid.UUID]*routeAnalyticsData)
+	operatorStats := make(map[uuid.UUID]*entities.OperatorBookingAnalytics)
 
 	for _, booking := range bookings {
 		totalBookings++
 
+		var operator *entities.OperatorBookingAnalytics
+		if booking.OperatorID != nil {
+			operator = operatorStats[*booking.OperatorID]
+			if operator == nil {
+				operator = &entities.OperatorBookingAnalytics{OperatorID: *booking.OperatorID, Date: day}
+				operatorStats[*booking.OperatorID] = operator
+			}
+			operator.TotalBookings++
+		}
+
 		switch booking.Status {
 		case "confirmed", "completed":
 			confirmedBookings++
 			totalRevenue += booking.TotalAmount
+			if operator != nil {
+				operator.ConfirmedBookings++
+				operator.TotalRevenue += booking.TotalAmount
+			}
 		case "cancelled":
 			cancelledBookings++
-		}
-
-		// Aggregate by route
-		if booking.Trip != nil && booking.Trip.RouteID != uuid.Nil {
-			routeID := booking.Trip.RouteID
-			if routeStats[routeID] == nil {
-				routeStats[routeID] = &routeAnalyticsData{}
-			}
-			routeStats[routeID].totalBookings++
-			if booking.Status == "confirmed" || booking.Status == "completed" {
-				routeStats[routeID].totalRevenue += booking.TotalAmount
+			if operator != nil {
+				operator.CancelledBookings++
 			}
 		}
 	}
@@ -343,7 +424,7 @@ func (s *BackgroundJobScheduler) computeDailyAnalytics() error {
 
 	// Store booking analytics - use CreateOrUpdate to handle existing records
 	bookingAnalytics := &entities.BookingAnalytics{
-		Date:              startOfYesterday,
+		Date:              day,
 		TotalBookings:     totalBookings,
 		ConfirmedBookings: confirmedBookings,
 		CancelledBookings: cancelledBookings,
@@ -352,32 +433,51 @@ func (s *BackgroundJobScheduler) computeDailyAnalytics() error {
 	}
 
 	if err := s.bookingAnalyticsRepo.CreateOrUpdate(ctx, bookingAnalytics); err != nil {
-		log.Printf("Error storing booking analytics: %v", err)
+		return nil, fmt.Errorf("failed to store booking analytics for %s: %w", day.Format("2006-01-02"), err)
 	}
 
-	// Store route analytics
-	for routeID, stats := range routeStats {
-		routeAnalytics := &entities.RouteAnalytics{
-			RouteID:       routeID,
-			Date:          startOfYesterday,
-			TotalBookings: stats.totalBookings,
-			TotalRevenue:  stats.totalRevenue,
+	// Store each operator's share
+	for operatorID, stats := range operatorStats {
+		if stats.TotalBookings > 0 {
+			stats.ConversionRate = float64(stats.ConfirmedBookings) / float64(stats.TotalBookings) * 100
 		}
-		if err := s.routeAnalyticsRepo.CreateOrUpdate(ctx, routeAnalytics); err != nil {
-			log.Printf("Error storing route analytics for route %s: %v", routeID, err)
+		if err := s.bookingAnalyticsRepo.CreateOrUpdateForOperator(ctx, stats); err != nil {
+			log.Printf("Error storing booking analytics for operator %s: %v", operatorID, err)
 		}
 	}
 
-	log.Printf("Daily analytics computed: %d bookings, %.2f revenue, %.1f%% conversion",
-		totalBookings, totalRevenue, conversionRate)
+	return bookingAnalytics, nil
+}
+
+// analyticsLookbackDays is how many departure days the nightly run refreshes
+const analyticsLookbackDays = 7
 
+// computeRouteAnalytics stores the per-route aggregates of the trips departing on date
+func (s *BackgroundJobScheduler) computeRouteAnalytics(ctx context.Context, date time.Time) error {
+	routeAnalytics, err := s.routeAnalyticsRepo.ComputeForDate(ctx, date)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate routes for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for _, analytics := range routeAnalytics {
+		if err := s.routeAnalyticsRepo.CreateOrUpdate(ctx, analytics); err != nil {
+			log.Printf("Error storing route analytics for route %s: %v", analytics.RouteID, err)
+		}
+	}
 	return nil
 }
 
-// routeAnalyticsData holds temporary aggregation data for route analytics
-type routeAnalyticsData struct {
-	totalBookings int
-	totalRevenue  float64
+// computeTripAnalytics stores load factor and yield for each trip departing on date
+func (s *BackgroundJobScheduler) computeTripAnalytics(ctx context.Context, date time.Time) error {
+	tripAnalytics, err := s.tripAnalyticsRepo.ComputeForDate(ctx, date)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate trips for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for _, analytics := range tripAnalytics {
+		if err := s.tripAnalyticsRepo.CreateOrUpdate(ctx, analytics); err != nil {
+			log.Printf("Error storing trip analytics for trip %s: %v", analytics.TripID, err)
+		}
+	}
+	return nil
 }
 
 // cleanupExpiredData removes old webhook logs and expired reservations
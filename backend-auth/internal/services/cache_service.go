@@ -173,6 +173,105 @@ func (c *CacheService) Invalidate(ctx context.Context, patterns ...string) error
 	return nil
 }
 
+// Increment atomically increments a counter; the TTL starts when the counter is created
+func (c *CacheService) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if !c.enabled {
+		return 0, fmt.Errorf("cache is disabled")
+	}
+
+	count, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("cache increment error: %w", err)
+	}
+	if count == 1 {
+		if err := c.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return count, fmt.Errorf("cache expire error: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// SetWithTTL stores a raw string value with an explicit TTL
+func (c *CacheService) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) error {
+	if !c.enabled {
+		return fmt.Errorf("cache is disabled")
+	}
+
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("cache set error: %w", err)
+	}
+
+	return nil
+}
+
+// TTL returns the remaining lifetime of a key, or 0 if it does not exist
+func (c *CacheService) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if !c.enabled {
+		return 0, fmt.Errorf("cache is disabled")
+	}
+
+	ttl, err := c.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("cache ttl error: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil // -2: missing key, -1: no expiry (never set by callers of this method)
+	}
+
+	return ttl, nil
+}
+
+// tokenBucketScript refills a bucket by elapsed time and takes one token if available
+// It runs atomically in Redis and uses the server clock so every API instance agrees on refills
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// TakeToken takes one token from a token bucket, refilling it at refillPerSecond up to capacity
+// It returns whether a token was taken and how many tokens are left
+func (c *CacheService) TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64) (bool, float64, error) {
+	if !c.enabled {
+		return false, 0, fmt.Errorf("cache is disabled")
+	}
+
+	result, err := tokenBucketScript.Run(ctx, c.client, []string{key}, capacity, refillPerSecond/1000).Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("cache token bucket error: %w", err)
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("cache token bucket error: unexpected reply %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	tokensStr, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("cache token bucket error: %w", err)
+	}
+
+	return allowed == 1, tokens, nil
+}
+
 // Flush clears all cache entries
 func (c *CacheService) Flush(ctx context.Context) error {
 	if !c.enabled {
@@ -2,10 +2,20 @@ package services
 
 // EmailProvider defines the interface for sending emails
 type EmailProvider interface {
-	SendTicketEmail(toEmail, toName, bookingReference, ticketNumber string, pdfBytes []byte) error
 	SendBookingConfirmationEmail(toEmail, toName, bookingReference string) error
 	SendHTMLEmail(toEmail, toName, subject, htmlBody string) error
-	SendPaymentReceiptEmail(toEmail, toName, bookingRef string, amount float64, transactionID string) error
+	SendEmail(message EmailMessage) error
 	SendTripReminderEmail(toEmail, toName, bookingRef, seatNumbers, departureTime, origin string) error
 	SendCancellationEmail(toEmail, toName, bookingRef, reason string) error
 }
+
+// EmailMessage is an HTML email with an optional plain-text alternative and attachment
+type EmailMessage struct {
+	ToEmail        string
+	ToName         string
+	Subject        string
+	HTMLBody       string
+	TextBody       string // Sent as the text/plain alternative when set
+	AttachmentName string
+	Attachment     []byte // PDF attachment, omitted when empty
+}
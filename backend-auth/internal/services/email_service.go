@@ -37,98 +37,6 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
-// SendTicketEmail sends an e-ticket via email with PDF attachment
-func (s *EmailService) SendTicketEmail(
-	toEmail string,
-	toName string,
-	bookingReference string,
-	ticketNumber string,
-	pdfBytes []byte,
-) error {
-	// Skip sending if SMTP credentials not configured
-	if s.smtpUsername == "" || s.smtpPassword == "" {
-		fmt.Printf("SMTP not configured - skipping email for %s\n", toEmail)
-		return nil
-	}
-
-	subject := fmt.Sprintf("Your E-Ticket - Booking %s", bookingReference)
-	body := s.templates.TicketEmail(toName, bookingReference, ticketNumber)
-
-	// Create message with attachment
-	message := s.createEmailWithAttachment(
-		toEmail,
-		toName,
-		subject,
-		body,
-		pdfBytes,
-		fmt.Sprintf("ticket-%s.pdf", ticketNumber),
-	)
-
-	// Send email
-	auth := smtp.PlainAuth("", s.smtpUsername, s.smtpPassword, s.smtpHost)
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
-
-	err := smtp.SendMail(
-		addr,
-		auth,
-		s.fromEmail,
-		[]string{toEmail},
-		message,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
-	}
-
-	fmt.Printf("E-ticket sent successfully to %s\n", toEmail)
-	return nil
-}
-
-func (s *EmailService) createEmailWithAttachment(
-	toEmail, toName, subject, htmlBody string,
-	attachmentBytes []byte,
-	attachmentName string,
-) []byte {
-	var buf bytes.Buffer
-
-	boundary := "boundary-string-12345"
-
-	// Email headers
-	buf.WriteString(fmt.Sprintf("From: %s <%s>\r\n", s.fromName, s.fromEmail))
-	buf.WriteString(fmt.Sprintf("To: %s <%s>\r\n", toName, toEmail))
-	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	buf.WriteString("MIME-Version: 1.0\r\n")
-	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n", boundary))
-	buf.WriteString("\r\n")
-
-	// HTML body part
-	buf.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
-	buf.WriteString("\r\n")
-	buf.WriteString(htmlBody)
-	buf.WriteString("\r\n")
-
-	// PDF attachment part
-	buf.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	buf.WriteString("Content-Type: application/pdf\r\n")
-	buf.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=\"%s\"\r\n", attachmentName))
-	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
-	buf.WriteString("\r\n")
-
-	// Encode PDF to base64
-	b := make([]byte, len(attachmentBytes))
-	copy(b, attachmentBytes)
-	encoded := encodeBase64(b)
-	buf.WriteString(encoded)
-	buf.WriteString("\r\n")
-
-	// Closing boundary
-	buf.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
-
-	return buf.Bytes()
-}
-
 func encodeBase64(data []byte) string {
 	const maxLineLength = 76
 	encoded := ""
@@ -221,20 +129,63 @@ func (s *EmailService) SendHTMLEmail(toEmail, toName, subject, htmlBody string)
 	return smtp.SendMail(addr, auth, s.fromEmail, []string{toEmail}, message)
 }
 
-// SendPaymentReceiptEmail sends payment receipt email
-func (s *EmailService) SendPaymentReceiptEmail(
-	toEmail, toName, bookingRef string,
-	amount float64,
-	transactionID string,
-) error {
+// SendEmail sends an HTML email with its plain-text alternative and attachment, when set
+func (s *EmailService) SendEmail(message EmailMessage) error {
 	if s.smtpUsername == "" || s.smtpPassword == "" {
-		fmt.Printf("SMTP not configured - skipping payment receipt for %s\n", toEmail)
+		fmt.Printf("SMTP not configured - skipping email for %s\n", message.ToEmail)
 		return nil
 	}
 
-	subject := fmt.Sprintf("Payment Receipt - Booking %s", bookingRef)
-	body := s.templates.PaymentReceiptEmail(toName, bookingRef, amount, transactionID)
-	return s.SendHTMLEmail(toEmail, toName, subject, body)
+	auth := smtp.PlainAuth("", s.smtpUsername, s.smtpPassword, s.smtpHost)
+	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
+
+	return smtp.SendMail(addr, auth, s.fromEmail, []string{message.ToEmail}, s.createMessage(message))
+}
+
+// createMessage builds a multipart/mixed message holding a multipart/alternative body
+// (text, then HTML so clients prefer HTML) followed by the attachment
+func (s *EmailService) createMessage(message EmailMessage) []byte {
+	var buf bytes.Buffer
+
+	mixedBoundary := "mixed-boundary-12345"
+	altBoundary := "alt-boundary-12345"
+
+	buf.WriteString(fmt.Sprintf("From: %s <%s>\r\n", s.fromName, s.fromEmail))
+	buf.WriteString(fmt.Sprintf("To: %s <%s>\r\n", message.ToName, message.ToEmail))
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", message.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n", mixedBoundary))
+	buf.WriteString("\r\n")
+
+	buf.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%s\r\n", altBoundary))
+	buf.WriteString("\r\n")
+	if message.TextBody != "" {
+		buf.WriteString(fmt.Sprintf("--%s\r\n", altBoundary))
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("\r\n")
+		buf.WriteString(message.TextBody)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString(fmt.Sprintf("--%s\r\n", altBoundary))
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(message.HTMLBody)
+	buf.WriteString("\r\n")
+	buf.WriteString(fmt.Sprintf("--%s--\r\n", altBoundary))
+
+	if len(message.Attachment) > 0 {
+		buf.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+		buf.WriteString("Content-Type: application/pdf\r\n")
+		buf.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=\"%s\"\r\n", message.AttachmentName))
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		buf.WriteString("\r\n")
+		buf.WriteString(encodeBase64(message.Attachment))
+		buf.WriteString("\r\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("--%s--\r\n", mixedBoundary))
+	return buf.Bytes()
 }
 
 // SendTripReminderEmail sends trip reminder email
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"html"
+	"strings"
 	"time"
 )
 
@@ -81,57 +83,6 @@ func (t *EmailTemplates) BookingConfirmationEmail(toName, bookingReference strin
 `, toName, bookingReference)
 }
 
-// PaymentReceiptEmail generates the HTML for payment receipt emails
-func (t *EmailTemplates) PaymentReceiptEmail(toName, bookingRef string, amount float64, txID string) string {
-	return fmt.Sprintf(`
-<html>
-<head>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #27ae60; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
-        .payment-details { background-color: white; padding: 20px; margin: 20px 0; border-left: 4px solid #27ae60; }
-        .amount { font-size: 32px; font-weight: bold; color: #27ae60; text-align: center; margin: 20px 0; }
-        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>💳 Payment Successful!</h1>
-        </div>
-        <div class="content">
-            <p>Dear %s,</p>
-            
-            <p>We have successfully received your payment.</p>
-            
-            <div class="amount">%.0f ₫</div>
-            <p style="text-align: center; color: #666;">Payment Details</p>
-            
-            <div class="payment-details">
-                <p><strong>Booking Reference:</strong> %s</p>
-                <p><strong>Transaction ID:</strong> %s</p>
-                <p><strong>Payment Method:</strong> bank_transfer</p>
-                <p><strong>Payment Date:</strong> %s</p>
-                <p><strong>Status:</strong> <span style="color: #27ae60;">✓ COMPLETED</span></p>
-            </div>
-            
-            <p>Your e-tickets have been sent to your email. You can also download them from your booking history.</p>
-            
-            <p>Have a safe journey!</p>
-            
-            <div class="footer">
-                <p>This is your official payment receipt. Please keep it for your records.</p>
-                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
-            </div>
-        </div>
-    </div>
-</body>
-</html>
-`, toName, amount, bookingRef, txID, time.Now().Format("January 2, 2006 at 3:04 PM"))
-}
-
 // TripReminderEmail generates the HTML for trip reminder emails
 func (t *EmailTemplates) TripReminderEmail(toName, bookingRef, seats, departureTime, origin string) string {
 	return fmt.Sprintf(`
@@ -230,3 +181,332 @@ func (t *EmailTemplates) CancellationEmail(toName, bookingRef, reason string) st
 </html>
 `, toName, bookingRef, reason)
 }
+
+// EmailVerificationEmail generates the HTML for account email verification
+func (t *EmailTemplates) EmailVerificationEmail(toName, verifyURL string, validFor time.Duration) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2980b9; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .button { display: inline-block; background-color: #2980b9; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Verify Your Email</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>Please confirm that this is your email address so we can send your tickets and booking updates here.</p>
+            
+            <p style="text-align: center;"><a class="button" href="%s">Verify Email</a></p>
+            
+            <p>This link expires in %s. If you did not create an account, you can ignore this email.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, verifyURL, formatValidity(validFor))
+}
+
+// PasswordResetEmail generates the HTML for a password reset link
+func (t *EmailTemplates) PasswordResetEmail(toName, resetURL string, validFor time.Duration) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2980b9; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .button { display: inline-block; background-color: #2980b9; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Reset Your Password</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>We received a request to reset the password for your account. Click the button below to choose a new password.</p>
+            
+            <p style="text-align: center;"><a class="button" href="%s">Reset Password</a></p>
+            
+            <p>This link expires in %s and can only be used once. Resetting your password signs you out of all devices.</p>
+            
+            <p>If you did not request a password reset, you can ignore this email; your password will not change.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, resetURL, formatValidity(validFor))
+}
+
+// AccountLockedEmail generates the HTML for a temporary lockout after repeated failed logins
+func (t *EmailTemplates) AccountLockedEmail(toName string, lockedUntil time.Time, ipAddress, resetURL string) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #c0392b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .info-box { background-color: white; padding: 15px; margin: 15px 0; border-left: 4px solid #c0392b; }
+        .button { display: inline-block; background-color: #2980b9; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Account Temporarily Locked</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>We temporarily locked your account after several failed sign-in attempts.</p>
+            
+            <div class="info-box">
+                <p><strong>Locked until:</strong> %s</p>
+                <p><strong>Last attempt from IP:</strong> %s</p>
+            </div>
+            
+            <p>If this was you, wait until the lock expires and try again. If you did not try to sign in, someone may be guessing your password; we recommend resetting it.</p>
+            
+            <p style="text-align: center;"><a class="button" href="%s">Reset Password</a></p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, lockedUntil.Format("January 2, 2006 at 3:04 PM"), ipAddress, resetURL)
+}
+
+// AccountErasureEmail generates the HTML asking the user to confirm deletion of their account
+func (t *EmailTemplates) AccountErasureEmail(toName, confirmURL string, validFor time.Duration) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #c0392b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .button { display: inline-block; background-color: #c0392b; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Confirm Account Deletion</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>We received a request to delete your account. Once confirmed, you are signed out everywhere and your personal details are removed from your profile, bookings, passengers and reviews. Payment records are kept, without your contact details, as required for accounting.</p>
+            
+            <p style="text-align: center;"><a class="button" href="%s">Delete My Account</a></p>
+            
+            <p>This link expires in %s. Deletion cannot be undone; download a copy of your data from your profile first if you need one.</p>
+            
+            <p>If you did not request this, ignore this email and your account will stay as it is.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, confirmURL, formatValidity(validFor))
+}
+
+// BookingClaimCodeEmail generates the HTML with the one-time code for adding guest bookings to an account
+func (t *EmailTemplates) BookingClaimCodeEmail(toName, code string, bookingCount int, validFor time.Duration) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2980b9; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .code { font-size: 32px; font-weight: bold; letter-spacing: 8px; text-align: center; margin: 20px 0; color: #2980b9; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Add Bookings to Your Account</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>Enter this code to add %d booking(s) made as a guest with this email address to your account:</p>
+            
+            <div class="code">%s</div>
+            
+            <p>The code expires in %s. Once added, the bookings appear in your booking history and you can review the trips.</p>
+            
+            <p>If you did not request this, you can ignore this email; your bookings will not change.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, bookingCount, code, formatValidity(validFor))
+}
+
+// GuestAccessCodeEmail generates the HTML with the one-time code for opening a guest booking
+func (t *EmailTemplates) GuestAccessCodeEmail(toName, bookingRef, code string, validFor time.Duration) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2980b9; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        .code { font-size: 32px; font-weight: bold; letter-spacing: 8px; text-align: center; margin: 20px 0; color: #2980b9; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Your Booking Access Code</h1>
+        </div>
+        <div class="content">
+            <p>Dear %s,</p>
+            
+            <p>Enter this code to open booking <strong>%s</strong>:</p>
+            
+            <div class="code">%s</div>
+            
+            <p>The code expires in %s. If you did not try to look up this booking, you can ignore this email.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, toName, bookingRef, code, formatValidity(validFor))
+}
+
+// WeeklyReportRoute is one row of the top routes table in the weekly analytics email
+type WeeklyReportRoute struct {
+	Name     string
+	Bookings int
+	Revenue  float64
+}
+
+// WeeklyAnalyticsEmail generates the HTML for the weekly analytics summary sent to admins
+// Changes are percentages against the week before
+func (t *EmailTemplates) WeeklyAnalyticsEmail(weekStart, weekEnd time.Time, bookings, confirmed int, revenue, conversionRate, bookingsChange, revenueChange float64, topRoutes []WeeklyReportRoute) string {
+	var routes strings.Builder
+	for _, route := range topRoutes {
+		fmt.Fprintf(&routes, "<tr><td>%s</td><td class=\"num\">%d</td><td class=\"num\">%.2f</td></tr>",
+			html.EscapeString(route.Name), route.Bookings, route.Revenue)
+	}
+	if len(topRoutes) == 0 {
+		routes.WriteString(`<tr><td colspan="3">No route data for this week</td></tr>`)
+	}
+
+	return fmt.Sprintf(`
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2c3e50; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+        .content { background-color: #f9f9f9; padding: 30px; border: 1px solid #ddd; border-radius: 0 0 5px 5px; }
+        table { width: 100%%; border-collapse: collapse; margin: 15px 0; }
+        th, td { padding: 8px; border-bottom: 1px solid #ddd; text-align: left; }
+        .num { text-align: right; }
+        .footer { text-align: center; margin-top: 30px; font-size: 12px; color: #777; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Weekly Summary</h1>
+            <p>%s to %s</p>
+        </div>
+        <div class="content">
+            <table>
+                <tr><th>Bookings</th><td class="num">%d (%+.1f%%)</td></tr>
+                <tr><th>Confirmed bookings</th><td class="num">%d</td></tr>
+                <tr><th>Revenue</th><td class="num">%.2f (%+.1f%%)</td></tr>
+                <tr><th>Conversion rate</th><td class="num">%.1f%%</td></tr>
+            </table>
+            
+            <h3>Top Routes by Revenue</h3>
+            <table>
+                <tr><th>Route</th><th class="num">Bookings</th><th class="num">Revenue</th></tr>
+                %s
+            </table>
+            
+            <p>Full reports can be downloaded as CSV or XLSX from the analytics dashboard.</p>
+            
+            <div class="footer">
+                <p>This is an automated message, please do not reply to this email.</p>
+                <p>&copy; 2025 Bus Booking System. All rights reserved.</p>
+            </div>
+        </div>
+    </div>
+</body>
+</html>
+`, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"),
+		bookings, bookingsChange, confirmed, revenue, revenueChange, conversionRate, routes.String())
+}
+
+// formatValidity renders a token lifetime as "24 hours" or "30 minutes"
+func formatValidity(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	minutes := int(d / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey is one signing or verification key, identified by the kid header of the tokens it signs
+type JWTKey struct {
+	ID        string
+	Algorithm string // RS256 or EdDSA
+	public    crypto.PublicKey
+	private   crypto.PrivateKey // Nil for verification-only keys
+}
+
+// JWTKeySet signs session tokens with one active key and verifies them against every loaded key
+// Rotation: add the new key file, point JWT_SIGNING_KEY_ID at it, and remove the old file
+// once the tokens it signed have expired
+type JWTKeySet struct {
+	signing *JWTKey
+	keys    map[string]*JWTKey
+
+	// HS256 secret for deployments without key files, or for tokens issued before the switch
+	hmacSecret []byte
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet signs and verifies session tokens with a shared secret (HS256)
+func NewHMACKeySet(secret string) *JWTKeySet {
+	return &JWTKeySet{
+		keys:       make(map[string]*JWTKey),
+		hmacSecret: []byte(secret),
+	}
+}
+
+// LoadJWTKeySet loads every PEM file in dir as a key whose kid is the file name without extension
+// Private key files can sign and verify; public key files only verify. The key named by
+// signingKeyID signs new tokens. A non-empty legacySecret keeps HS256 tokens valid during migration
+func LoadJWTKeySet(dir, signingKeyID, legacySecret string) (*JWTKeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key directory: %w", err)
+	}
+
+	set := &JWTKeySet{keys: make(map[string]*JWTKey)}
+	if legacySecret != "" {
+		set.hmacSecret = []byte(legacySecret)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".pem") {
+			continue
+		}
+		kid := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %s: %w", entry.Name(), err)
+		}
+		key, err := parseJWTKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT key %s: %w", entry.Name(), err)
+		}
+		set.keys[kid] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("no .pem keys found in %s", dir)
+	}
+	if signingKeyID == "" {
+		// Default to the last private key in name order, so date-named keys pick the newest
+		for _, id := range set.KeyIDs() {
+			if set.keys[id].private != nil {
+				signingKeyID = id
+			}
+		}
+	}
+	signing, ok := set.keys[signingKeyID]
+	if !ok && signingKeyID == "" {
+		return nil, fmt.Errorf("no private key found in %s", dir)
+	}
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found in %s", signingKeyID, dir)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("signing key %q is a public key; a private key is required", signingKeyID)
+	}
+	set.signing = signing
+
+	log.Printf("JWT keys loaded (signing: %s %s, verification keys: %s, legacy HS256: %t)",
+		signing.ID, signing.Algorithm, strings.Join(set.KeyIDs(), ", "), set.hmacSecret != nil)
+	return set, nil
+}
+
+// parseJWTKey reads an RSA or Ed25519 key from PEM (PKCS#1, PKCS#8 or PKIX)
+func parseJWTKey(kid string, data []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &JWTKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.private, key.public = jwt.SigningMethodRS256.Alg(), k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.public = jwt.SigningMethodRS256.Alg(), k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.private, key.public = jwt.SigningMethodEdDSA.Alg(), k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.public = jwt.SigningMethodEdDSA.Alg(), k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	return key, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (s *JWTKeySet) Algorithm() string {
+	if s.signing == nil {
+		return jwt.SigningMethodHS256.Alg()
+	}
+	return s.signing.Algorithm
+}
+
+// KeyIDs returns the kid of every verification key in name order
+func (s *JWTKeySet) KeyIDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Sign signs claims with the active key, setting the kid header
+func (s *JWTKeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(s.signing.Algorithm), claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// Parse verifies a token against the key named by its kid header and validates its claims
+// A token must use the algorithm of its key, so a public key can never be used as an HMAC secret
+func (s *JWTKeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, s.keyFunc, jwt.WithValidMethods(s.validMethods()))
+}
+
+func (s *JWTKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && s.hmacSecret != nil {
+			return s.hmacSecret, nil
+		}
+		return nil, errors.New("token has no key ID")
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+func (s *JWTKeySet) validMethods() []string {
+	methods := make([]string, 0, 3)
+	if s.hmacSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	seen := make(map[string]bool)
+	for _, key := range s.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			methods = append(methods, key.Algorithm)
+		}
+	}
+	return methods
+}
+
+// JWKS returns the public verification keys; it is empty when tokens are signed with HS256
+func (s *JWTKeySet) JWKS() JWKS {
+	doc := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, id := range s.KeyIDs() {
+		key := s.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	return doc
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginThrottle tracks failed logins per account and per IP address
+// Counters live in Redis when the cache is enabled so every API instance shares them,
+// otherwise (or when Redis errors) they are kept in process memory
+type LoginThrottle struct {
+	cache *CacheService
+
+	accountThreshold int           // Failures before the account is locked
+	ipThreshold      int           // Failures before the IP address is blocked
+	delayAfter       int           // Failures before progressive delays start
+	window           time.Duration // How long failures are counted
+	lockout          time.Duration // How long a lock or block lasts
+	maxDelay         time.Duration
+
+	mu    sync.Mutex
+	local map[string]localCounter
+}
+
+type localCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// LoginFailureResult reports what a failed attempt triggered
+type LoginFailureResult struct {
+	AccountFailures int64
+	IPFailures      int64
+	AccountLocked   bool // The account was locked by this attempt
+	IPBlocked       bool // The IP address was blocked by this attempt
+	LockedUntil     time.Time
+}
+
+// LoginLockStatus describes the current lockout state of an account
+type LoginLockStatus struct {
+	Locked         bool       `json:"locked"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	FailedAttempts int64      `json:"failed_attempts"`
+}
+
+// NewLoginThrottle creates a login throttle configured from the environment
+func NewLoginThrottle(cache *CacheService) *LoginThrottle {
+	t := &LoginThrottle{
+		cache:            cache,
+		accountThreshold: getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		ipThreshold:      getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 20),
+		delayAfter:       getEnvInt("LOGIN_DELAY_AFTER_ATTEMPTS", 2),
+		window:           time.Duration(getEnvInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+		lockout:          time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		maxDelay:         30 * time.Second,
+		local:            make(map[string]localCounter),
+	}
+
+	store := "memory"
+	if t.useRedis() {
+		store = "redis"
+	}
+	log.Printf("Login throttle initialized (store: %s, lock after %d failures per account, %d per IP, lockout %v)",
+		store, t.accountThreshold, t.ipThreshold, t.lockout)
+	return t
+}
+
+// Check returns how long the caller must wait before another attempt, or 0 if allowed
+func (t *LoginThrottle) Check(ctx context.Context, email, ip string) time.Duration {
+	wait := t.remaining(ctx, lockKey("acct", normalizeEmail(email)))
+	if d := t.remaining(ctx, delayKey(normalizeEmail(email))); d > wait {
+		wait = d
+	}
+	if ip != "" {
+		if d := t.remaining(ctx, lockKey("ip", ip)); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// RecordFailure counts a failed attempt and applies delays, locks and blocks
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email, ip string) LoginFailureResult {
+	email = normalizeEmail(email)
+	result := LoginFailureResult{}
+
+	result.AccountFailures = t.increment(ctx, failKey("acct", email), t.window)
+	if result.AccountFailures >= int64(t.accountThreshold) {
+		result.AccountLocked = true
+		result.LockedUntil = time.Now().Add(t.lockout)
+		t.set(ctx, lockKey("acct", email), t.lockout)
+		t.delete(ctx, failKey("acct", email), delayKey(email))
+	} else if result.AccountFailures > int64(t.delayAfter) {
+		// 1s, 2s, 4s ... between attempts so guessing gets slower with every failure
+		delay := time.Second << uint(result.AccountFailures-int64(t.delayAfter)-1)
+		if delay > t.maxDelay || delay <= 0 {
+			delay = t.maxDelay
+		}
+		t.set(ctx, delayKey(email), delay)
+	}
+
+	if ip != "" {
+		result.IPFailures = t.increment(ctx, failKey("ip", ip), t.window)
+		if result.IPFailures >= int64(t.ipThreshold) {
+			result.IPBlocked = true
+			t.set(ctx, lockKey("ip", ip), t.lockout)
+			t.delete(ctx, failKey("ip", ip))
+		}
+	}
+
+	return result
+}
+
+// RecordSuccess clears the account's failure history after a successful login
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, email string) {
+	email = normalizeEmail(email)
+	t.delete(ctx, failKey("acct", email), delayKey(email))
+}
+
+// Unlock lifts an account lockout and clears its failure history
+func (t *LoginThrottle) Unlock(ctx context.Context, email string) {
+	email = normalizeEmail(email)
+	t.delete(ctx, lockKey("acct", email), failKey("acct", email), delayKey(email))
+}
+
+// Status returns the current lockout state of an account
+func (t *LoginThrottle) Status(ctx context.Context, email string) LoginLockStatus {
+	email = normalizeEmail(email)
+	status := LoginLockStatus{FailedAttempts: t.count(ctx, failKey("acct", email))}
+	if wait := t.remaining(ctx, lockKey("acct", email)); wait > 0 {
+		until := time.Now().Add(wait)
+		status.Locked = true
+		status.LockedUntil = &until
+	}
+	return status
+}
+
+func (t *LoginThrottle) useRedis() bool {
+	return t.cache != nil && t.cache.IsEnabled()
+}
+
+func (t *LoginThrottle) increment(ctx context.Context, key string, ttl time.Duration) int64 {
+	if t.useRedis() {
+		count, err := t.cache.Increment(ctx, key, ttl)
+		if err == nil {
+			return count
+		}
+		log.Printf("[LoginThrottle] Redis increment failed, using memory: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked()
+	counter, ok := t.local[key]
+	if !ok {
+		counter.expiresAt = time.Now().Add(ttl)
+	}
+	counter.count++
+	t.local[key] = counter
+	return counter.count
+}
+
+func (t *LoginThrottle) set(ctx context.Context, key string, ttl time.Duration) {
+	if t.useRedis() {
+		err := t.cache.SetWithTTL(ctx, key, "1", ttl)
+		if err == nil {
+			return
+		}
+		log.Printf("[LoginThrottle] Redis set failed, using memory: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.local[key] = localCounter{count: 1, expiresAt: time.Now().Add(ttl)}
+}
+
+func (t *LoginThrottle) remaining(ctx context.Context, key string) time.Duration {
+	if t.useRedis() {
+		ttl, err := t.cache.TTL(ctx, key)
+		if err == nil {
+			return ttl
+		}
+		log.Printf("[LoginThrottle] Redis TTL failed, using memory: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counter, ok := t.local[key]
+	if !ok {
+		return 0
+	}
+	wait := time.Until(counter.expiresAt)
+	if wait <= 0 {
+		delete(t.local, key)
+		return 0
+	}
+	return wait
+}
+
+func (t *LoginThrottle) count(ctx context.Context, key string) int64 {
+	if t.useRedis() {
+		var count int64
+		if err := t.cache.Get(ctx, key, &count); err == nil {
+			return count
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counter, ok := t.local[key]
+	if !ok || time.Now().After(counter.expiresAt) {
+		return 0
+	}
+	return counter.count
+}
+
+func (t *LoginThrottle) delete(ctx context.Context, keys ...string) {
+	if t.useRedis() {
+		if err := t.cache.Delete(ctx, keys...); err != nil {
+			log.Printf("[LoginThrottle] Redis delete failed: %v", err)
+		}
+	}
+
+	// Always clear memory too, in case a Redis outage left entries there
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		delete(t.local, key)
+	}
+}
+
+// pruneLocked drops expired entries; the caller must hold t.mu
+func (t *LoginThrottle) pruneLocked() {
+	now := time.Now()
+	for key, counter := range t.local {
+		if now.After(counter.expiresAt) {
+			delete(t.local, key)
+		}
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func failKey(scope, id string) string {
+	return "login:fail:" + scope + ":" + id
+}
+
+func lockKey(scope, id string) string {
+	return "login:lock:" + scope + ":" + id
+}
+
+func delayKey(email string) string {
+	return "login:delay:acct:" + email
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MFAKeyring holds the keys that encrypt TOTP secrets at rest, identified by the key ID stored with each secret
+// Rotation: set the new MFA_ENCRYPTION_KEY and MFA_ENCRYPTION_KEY_ID, move the old pair to
+// MFA_RETIRED_ENCRYPTION_KEYS, and drop it once every secret has been re-encrypted on use
+type MFAKeyring struct {
+	currentID string
+	keys      map[string][]byte // AES-256 keys by ID
+}
+
+// NewMFAKeyring builds a keyring from the current key and comma-separated <id>:<secret> retired keys
+// Key IDs cannot contain ':' because they are stored as part of the encrypted value's prefix
+func NewMFAKeyring(currentID, currentSecret, retired string) (*MFAKeyring, error) {
+	k := &MFAKeyring{currentID: currentID, keys: make(map[string][]byte)}
+	if err := k.add(currentID, currentSecret); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range strings.Split(retired, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("retired MFA key %q must be <id>:<secret>", entry)
+		}
+		if err := k.add(id, secret); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+func (k *MFAKeyring) add(id, secret string) error {
+	switch {
+	case id == "" || strings.Contains(id, ":"):
+		return fmt.Errorf("invalid MFA key ID %q", id)
+	case secret == "":
+		return fmt.Errorf("MFA key %q has no secret", id)
+	}
+	if _, exists := k.keys[id]; exists {
+		return fmt.Errorf("duplicate MFA key ID %q", id)
+	}
+	key := sha256.Sum256([]byte(secret))
+	k.keys[id] = key[:]
+	return nil
+}
+
+// Current returns the key that encrypts new secrets
+func (k *MFAKeyring) Current() (string, []byte) {
+	return k.currentID, k.keys[k.currentID]
+}
+
+// Key returns the key with the given ID
+func (k *MFAKeyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, errors.New("unknown MFA key ID")
+	}
+	return key, nil
+}
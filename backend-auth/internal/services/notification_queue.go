@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
 )
@@ -23,6 +24,11 @@ type NotificationQueue struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 
+	// Retry configuration
+	// Failed sends are rescheduled after retryBaseDelay * 2^(attempt-1), capped at retryMaxDelay
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+
 	// Dependencies
 	notifRepo      repositories.NotificationRepository
 	emailService   EmailProvider
@@ -47,6 +53,8 @@ func NewNotificationQueue(
 		running:        false,
 		ctx:            ctx,
 		cancel:         cancel,
+		retryBaseDelay: time.Duration(getEnvInt("NOTIFICATION_RETRY_BASE_SECONDS", 60)) * time.Second,
+		retryMaxDelay:  time.Duration(getEnvInt("NOTIFICATION_RETRY_MAX_SECONDS", 3600)) * time.Second,
 		notifRepo:      notifRepo,
 		emailService:   emailService,
 		templateEngine: templateEngine,
@@ -184,14 +192,17 @@ func (q *NotificationQueue) processNotification(notif *entities.Notification) {
 		notif.ErrorMessage = &errMsg
 		notif.RetryCount++
 
-		// If can retry, re-enqueue after delay
 		if notif.CanRetry() {
-			time.AfterFunc(time.Duration(notif.RetryCount)*time.Minute, func() {
-				log.Printf("[NotificationQueue] Retrying notification %s (attempt %d)", notif.ID, notif.RetryCount+1)
-				if err := q.Enqueue(notif); err != nil {
-					log.Printf("[NotificationQueue] Failed to re-enqueue notification %s: %v", notif.ID, err)
-				}
-			})
+			// Reschedule with backoff; the scheduled-notification job picks it up when due
+			delay := q.retryDelay(notif.RetryCount)
+			nextAttempt := now.Add(delay)
+			notif.Status = entities.NotificationStatusPending
+			notif.ScheduledFor = &nextAttempt
+			log.Printf("[NotificationQueue] Notification %s rescheduled in %v (attempt %d of %d)",
+				notif.ID, delay.Round(time.Second), notif.RetryCount+1, notif.MaxRetries)
+		} else {
+			notif.Status = entities.NotificationStatusDeadLetter
+			log.Printf("[NotificationQueue] Notification %s moved to dead-letter after %d attempts", notif.ID, notif.RetryCount)
 		}
 	} else {
 		log.Printf("[NotificationQueue] Successfully sent notification %s via %s", notif.ID, notif.Channel)
@@ -207,24 +218,33 @@ func (q *NotificationQueue) processNotification(notif *entities.Notification) {
 	}
 }
 
+// retryDelay returns the exponential backoff delay for the given retry attempt
+// Full jitter is applied over the upper half of the window to avoid retry storms
+func (q *NotificationQueue) retryDelay(attempt int) time.Duration {
+	return BackoffWithJitter(q.retryBaseDelay, q.retryMaxDelay, attempt)
+}
+
 // sendEmail sends notification via email
 func (q *NotificationQueue) sendEmail(notif *entities.Notification) error {
 	if notif.RecipientEmail == nil || *notif.RecipientEmail == "" {
 		return fmt.Errorf("recipient email is required")
 	}
 
-	// Use HTML body if available, otherwise plain text
-	body := notif.Body
+	// Use HTML body if available, with Body as its plain-text alternative unless it is the same HTML
+	message := EmailMessage{
+		ToEmail:  *notif.RecipientEmail,
+		ToName:   notif.RecipientName,
+		Subject:  notif.Subject,
+		HTMLBody: notif.Body,
+	}
 	if notif.HTMLBody != nil && *notif.HTMLBody != "" {
-		body = *notif.HTMLBody
+		message.HTMLBody = *notif.HTMLBody
+		if notif.Body != *notif.HTMLBody {
+			message.TextBody = notif.Body
+		}
 	}
 
-	return q.emailService.SendHTMLEmail(
-		*notif.RecipientEmail,
-		notif.RecipientName,
-		notif.Subject,
-		body,
-	)
+	return q.emailService.SendEmail(message)
 }
 
 // sendSMS sends notification via SMS (placeholder for future implementation)
@@ -234,10 +254,8 @@ func (q *NotificationQueue) sendSMS(notif *entities.Notification) error {
 	}
 
 	// SMS integration would go here (Twilio, AWS SNS, etc.)
-	log.Printf("[NotificationQueue] SMS sending not implemented: %s -> %s", *notif.RecipientPhone, notif.Body)
-
-	// For now, just log and mark as sent
-	return nil
+	// Until then fail instead of marking it sent; the body is never logged as it may hold a one-time code
+	return fmt.Errorf("SMS delivery is not configured")
 }
 
 // cleanupWorker periodically cleans up old sent notifications
@@ -286,3 +304,60 @@ func (q *NotificationQueue) LoadPendingNotifications(ctx context.Context) error
 
 	return nil
 }
+
+// GetDeadLetters returns a page of notifications that exhausted their retries
+func (q *NotificationQueue) GetDeadLetters(ctx context.Context, page, pageSize int) ([]*entities.Notification, int64, error) {
+	return q.notifRepo.GetByStatus(ctx, entities.NotificationStatusDeadLetter, page, pageSize)
+}
+
+// GetDeadLetter returns a single dead-lettered notification
+func (q *NotificationQueue) GetDeadLetter(ctx context.Context, id uuid.UUID) (*entities.Notification, error) {
+	notif, err := q.notifRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("notification not found: %w", err)
+	}
+	if !notif.IsDeadLettered() {
+		return nil, fmt.Errorf("notification is not in dead-letter status")
+	}
+	return notif, nil
+}
+
+// RetryDeadLetter resets the retry budget of a dead-lettered notification and enqueues it again
+func (q *NotificationQueue) RetryDeadLetter(ctx context.Context, id uuid.UUID) (*entities.Notification, error) {
+	notif, err := q.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	notif.Status = entities.NotificationStatusPending
+	notif.RetryCount = 0
+	notif.ScheduledFor = nil
+	notif.FailedAt = nil
+	notif.ErrorMessage = nil
+
+	if err := q.notifRepo.Update(ctx, notif); err != nil {
+		return nil, fmt.Errorf("failed to reset notification: %w", err)
+	}
+
+	// If the queue is unavailable the notification stays pending and is picked up on next load
+	if err := q.Enqueue(notif); err != nil {
+		log.Printf("[NotificationQueue] Failed to enqueue retried notification %s: %v", notif.ID, err)
+	}
+
+	return notif, nil
+}
+
+// DiscardDeadLetter cancels a dead-lettered notification so it is never retried
+func (q *NotificationQueue) DiscardDeadLetter(ctx context.Context, id uuid.UUID) error {
+	notif, err := q.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	notif.Status = entities.NotificationStatusCancelled
+	if err := q.notifRepo.Update(ctx, notif); err != nil {
+		return fmt.Errorf("failed to discard notification: %w", err)
+	}
+
+	return nil
+}
@@ -1,23 +1,212 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"strings"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
 )
 
 // NotificationTemplateEngine renders notification templates with data
 // This provides a centralized way to generate notification content
+// Admin-edited templates stored in the database take precedence; the
+// compiled-in templates below are used when none is active or rendering fails.
 type NotificationTemplateEngine struct {
-	emailService EmailProvider
+	emailService  EmailProvider
+	templateRepo  repositories.NotificationTemplateRepository
+	defaultLocale string
 }
 
 // NewNotificationTemplateEngine creates a new template engine
-func NewNotificationTemplateEngine(emailService EmailProvider) *NotificationTemplateEngine {
+// templateRepo may be nil, in which case only compiled-in templates are used
+func NewNotificationTemplateEngine(emailService EmailProvider, templateRepo repositories.NotificationTemplateRepository) *NotificationTemplateEngine {
+	defaultLocale := os.Getenv("NOTIFICATION_DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = entities.DefaultTemplateLocale
+	}
+
 	return &NotificationTemplateEngine{
-		emailService: emailService,
+		emailService:  emailService,
+		templateRepo:  templateRepo,
+		defaultLocale: defaultLocale,
+	}
+}
+
+// DefaultLocale returns the locale used when rendering without an explicit locale
+func (e *NotificationTemplateEngine) DefaultLocale() string {
+	return e.defaultLocale
+}
+
+// RenderedTemplate holds the output of rendering a stored template
+type RenderedTemplate struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+}
+
+// templateFuncs are available inside stored templates
+var templateFuncs = map[string]interface{}{
+	"money": func(amount float64) string { return fmt.Sprintf("%.0f", amount) },
+}
+
+// ValidateTemplate checks that the subject and bodies of a template parse correctly
+func ValidateTemplate(tmpl *entities.NotificationTemplate) error {
+	if _, err := texttemplate.New("subject").Funcs(templateFuncs).Parse(tmpl.Subject); err != nil {
+		return fmt.Errorf("invalid subject template: %w", err)
+	}
+	if _, err := htmltemplate.New("html").Funcs(templateFuncs).Parse(tmpl.HTMLBody); err != nil {
+		return fmt.Errorf("invalid html template: %w", err)
+	}
+	if _, err := texttemplate.New("text").Funcs(templateFuncs).Parse(tmpl.TextBody); err != nil {
+		return fmt.Errorf("invalid text template: %w", err)
+	}
+	return nil
+}
+
+// RenderTemplate renders a template against data
+// The HTML body goes through html/template so data values are escaped;
+// subject and text body are plain text.
+func RenderTemplate(tmpl *entities.NotificationTemplate, data interface{}) (*RenderedTemplate, error) {
+	subjectTmpl, err := texttemplate.New("subject").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	htmlTmpl, err := htmltemplate.New("html").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl.HTMLBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid html template: %w", err)
+	}
+	textTmpl, err := texttemplate.New("text").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl.TextBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid text template: %w", err)
+	}
+
+	var subject, htmlBody, textBody bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := htmlTmpl.Execute(&htmlBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+	if err := textTmpl.Execute(&textBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+
+	return &RenderedTemplate{
+		Subject:  subject.String(),
+		HTMLBody: htmlBody.String(),
+		TextBody: textBody.String(),
+	}, nil
+}
+
+// NormalizeLocale reduces a locale or Accept-Language value to its primary language subtag
+// "vi-VN,vi;q=0.9,en;q=0.8" becomes "vi"; an empty or malformed value gives ""
+func NormalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ",;-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if len(locale) < 2 || len(locale) > 8 {
+		return ""
+	}
+	for _, r := range locale {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return locale
+}
+
+// renderStoredOrFallback renders the stored template active for the recipient's locale,
+// or for the default locale when that one has none
+// Returns false when the compiled-in template should be used instead
+func (e *NotificationTemplateEngine) renderStoredOrFallback(notifType entities.NotificationType, locale string, data interface{}) (*RenderedTemplate, bool) {
+	if e.templateRepo == nil {
+		return nil, false
+	}
+
+	locale = NormalizeLocale(locale)
+	if locale == "" {
+		locale = e.defaultLocale
+	}
+	tmpl, err := e.templateRepo.GetActive(context.Background(), notifType, locale)
+	if err != nil && locale != e.defaultLocale {
+		tmpl, err = e.templateRepo.GetActive(context.Background(), notifType, e.defaultLocale)
+	}
+	if err != nil {
+		// No active stored template, use the compiled-in one
+		return nil, false
+	}
+
+	rendered, err := RenderTemplate(tmpl, data)
+	if err != nil {
+		log.Printf("[NotificationTemplate] Failed to render stored %s template v%d, using fallback: %v", notifType, tmpl.Version, err)
+		return nil, false
+	}
+	if rendered.HTMLBody == "" {
+		log.Printf("[NotificationTemplate] Stored %s template v%d rendered an empty body, using fallback", notifType, tmpl.Version)
+		return nil, false
+	}
+
+	return rendered, true
+}
+
+// SampleTemplateData returns representative data for previewing templates of a given type
+func SampleTemplateData(notifType entities.NotificationType) interface{} {
+	switch notifType {
+	case entities.NotificationTypeBookingConfirmation:
+		return BookingConfirmationData{
+			RecipientName:    "Nguyen Van A",
+			BookingReference: "BK20250101ABCD",
+			TripOrigin:       "Ho Chi Minh City",
+			TripDestination:  "Da Lat",
+			DepartureTime:    "Monday, January 6, 2025 at 7:00 AM",
+			TotalSeats:       2,
+			TotalAmount:      560000,
+			SeatNumbers:      "A1, A2",
+		}
+	case entities.NotificationTypePaymentReceipt:
+		return PaymentReceiptData{
+			RecipientName:    "Nguyen Van A",
+			BookingReference: "BK20250101ABCD",
+			Amount:           560000,
+			TransactionID:    "TX123456789",
+			PaymentMethod:    "bank_transfer",
+			PaymentDate:      "January 1, 2025 at 9:30 AM",
+		}
+	case entities.NotificationTypeTripReminder:
+		return TripReminderData{
+			RecipientName:    "Nguyen Van A",
+			BookingReference: "BK20250101ABCD",
+			SeatNumbers:      "A1, A2",
+			DepartureTime:    "Monday, January 6, 2025 at 7:00 AM",
+			Origin:           "Ho Chi Minh City",
+			Destination:      "Da Lat",
+			PickupPoint:      "Mien Dong Bus Station",
+		}
+	case entities.NotificationTypeETicket:
+		return TicketEmailData{
+			RecipientName:    "Nguyen Van A",
+			BookingReference: "BK20250101ABCD",
+			TicketNumber:     "TK20250101ABCD01",
+		}
+	default:
+		return CancellationData{
+			RecipientName:    "Nguyen Van A",
+			BookingReference: "BK20250101ABCD",
+			Reason:           "Cancelled by customer",
+			RefundAmount:     448000,
+			RefundMethod:     "bank_transfer",
+		}
 	}
 }
 
@@ -63,8 +252,20 @@ type CancellationData struct {
 	RefundMethod     string
 }
 
+// TicketEmailData contains data for the e-ticket email sent with each ticket PDF
+type TicketEmailData struct {
+	RecipientName    string
+	BookingReference string
+	TicketNumber     string
+}
+
 // RenderBookingConfirmation renders booking confirmation notification
-func (e *NotificationTemplateEngine) RenderBookingConfirmation(data BookingConfirmationData) (string, string, error) {
+// The compiled-in fallbacks have no text body
+func (e *NotificationTemplateEngine) RenderBookingConfirmation(locale string, data BookingConfirmationData) (*RenderedTemplate, error) {
+	if rendered, ok := e.renderStoredOrFallback(entities.NotificationTypeBookingConfirmation, locale, data); ok {
+		return rendered, nil
+	}
+
 	subject := fmt.Sprintf("Booking Confirmed - %s", data.BookingReference)
 
 	body := fmt.Sprintf(`
@@ -121,11 +322,15 @@ func (e *NotificationTemplateEngine) RenderBookingConfirmation(data BookingConfi
 `, data.RecipientName, data.BookingReference, data.TripOrigin, data.TripDestination,
 		data.DepartureTime, data.SeatNumbers, data.TotalSeats, data.TotalAmount)
 
-	return subject, body, nil
+	return &RenderedTemplate{Subject: subject, HTMLBody: body}, nil
 }
 
 // RenderPaymentReceipt renders payment receipt notification
-func (e *NotificationTemplateEngine) RenderPaymentReceipt(data PaymentReceiptData) (string, string, error) {
+func (e *NotificationTemplateEngine) RenderPaymentReceipt(locale string, data PaymentReceiptData) (*RenderedTemplate, error) {
+	if rendered, ok := e.renderStoredOrFallback(entities.NotificationTypePaymentReceipt, locale, data); ok {
+		return rendered, nil
+	}
+
 	subject := fmt.Sprintf("Payment Receipt - %s", data.BookingReference)
 
 	body := fmt.Sprintf(`
@@ -177,11 +382,15 @@ func (e *NotificationTemplateEngine) RenderPaymentReceipt(data PaymentReceiptDat
 `, data.RecipientName, data.Amount, data.BookingReference, data.TransactionID,
 		data.PaymentMethod, data.PaymentDate)
 
-	return subject, body, nil
+	return &RenderedTemplate{Subject: subject, HTMLBody: body}, nil
 }
 
 // RenderTripReminder renders trip reminder notification
-func (e *NotificationTemplateEngine) RenderTripReminder(data TripReminderData) (string, string, error) {
+func (e *NotificationTemplateEngine) RenderTripReminder(locale string, data TripReminderData) (*RenderedTemplate, error) {
+	if rendered, ok := e.renderStoredOrFallback(entities.NotificationTypeTripReminder, locale, data); ok {
+		return rendered, nil
+	}
+
 	subject := fmt.Sprintf("Trip Reminder - Your trip is tomorrow!")
 
 	body := fmt.Sprintf(`
@@ -239,11 +448,15 @@ func (e *NotificationTemplateEngine) RenderTripReminder(data TripReminderData) (
 `, data.RecipientName, data.BookingReference, data.SeatNumbers, data.Origin,
 		data.Destination, data.DepartureTime, data.PickupPoint)
 
-	return subject, body, nil
+	return &RenderedTemplate{Subject: subject, HTMLBody: body}, nil
 }
 
 // RenderCancellation renders cancellation notification
-func (e *NotificationTemplateEngine) RenderCancellation(data CancellationData) (string, string, error) {
+func (e *NotificationTemplateEngine) RenderCancellation(locale string, data CancellationData) (*RenderedTemplate, error) {
+	if rendered, ok := e.renderStoredOrFallback(entities.NotificationTypeCancellation, locale, data); ok {
+		return rendered, nil
+	}
+
 	subject := fmt.Sprintf("Booking Cancelled - %s", data.BookingReference)
 
 	refundInfo := ""
@@ -301,7 +514,37 @@ func (e *NotificationTemplateEngine) RenderCancellation(data CancellationData) (
 </html>
 `, data.RecipientName, data.BookingReference, data.Reason, refundInfo)
 
-	return subject, body, nil
+	return &RenderedTemplate{Subject: subject, HTMLBody: body}, nil
+}
+
+// RenderTicketEmail renders the e-ticket email
+func (e *NotificationTemplateEngine) RenderTicketEmail(locale string, data TicketEmailData) (*RenderedTemplate, error) {
+	if rendered, ok := e.renderStoredOrFallback(entities.NotificationTypeETicket, locale, data); ok {
+		return rendered, nil
+	}
+
+	return &RenderedTemplate{
+		Subject:  fmt.Sprintf("Your E-Ticket - Booking %s", data.BookingReference),
+		HTMLBody: NewEmailTemplates().TicketEmail(data.RecipientName, data.BookingReference, data.TicketNumber),
+	}, nil
+}
+
+// SendTicketEmail renders the e-ticket email in the recipient's locale and sends it with the ticket PDF attached
+func (e *NotificationTemplateEngine) SendTicketEmail(locale, toEmail string, data TicketEmailData, pdfBytes []byte) error {
+	rendered, err := e.RenderTicketEmail(locale, data)
+	if err != nil {
+		return err
+	}
+
+	return e.emailService.SendEmail(EmailMessage{
+		ToEmail:        toEmail,
+		ToName:         data.RecipientName,
+		Subject:        rendered.Subject,
+		HTMLBody:       rendered.HTMLBody,
+		TextBody:       rendered.TextBody,
+		AttachmentName: fmt.Sprintf("ticket_%s.pdf", data.TicketNumber),
+		Attachment:     pdfBytes,
+	})
 }
 
 // ParseTemplateData parses JSON template data from notification
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+// OutboxHandler delivers a single outbox event
+// Handlers must be idempotent: an event can be delivered more than once if the relay
+// crashes between running the handler and marking the event dispatched
+type OutboxHandler func(ctx context.Context, event *entities.OutboxEvent) error
+
+// OutboxRelay polls the outbox table and dispatches pending events to registered handlers
+// Failed events are retried with exponential backoff until MaxAttempts, then marked dead
+type OutboxRelay struct {
+	outboxRepo repositories.OutboxRepository
+	handlers   map[string]OutboxHandler
+	mu         sync.RWMutex
+	running    bool
+	wg         sync.WaitGroup
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	pollInterval   time.Duration
+	batchSize      int
+	lease          time.Duration // How long a claimed event stays invisible to other relays
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outboxRepo repositories.OutboxRepository) *OutboxRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &OutboxRelay{
+		outboxRepo:     outboxRepo,
+		handlers:       make(map[string]OutboxHandler),
+		ctx:            ctx,
+		cancel:         cancel,
+		pollInterval:   time.Duration(getEnvInt("OUTBOX_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		batchSize:      getEnvInt("OUTBOX_BATCH_SIZE", 50),
+		lease:          2 * time.Minute,
+		retryBaseDelay: time.Duration(getEnvInt("OUTBOX_RETRY_BASE_SECONDS", 30)) * time.Second,
+		retryMaxDelay:  time.Duration(getEnvInt("OUTBOX_RETRY_MAX_SECONDS", 3600)) * time.Second,
+	}
+}
+
+// RegisterHandler sets the handler for an event type
+func (r *OutboxRelay) RegisterHandler(eventType string, handler OutboxHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = handler
+}
+
+// Start begins polling the outbox
+func (r *OutboxRelay) Start() {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	log.Printf("[OutboxRelay] Starting (poll every %v, batch %d)", r.pollInterval, r.batchSize)
+
+	r.wg.Add(2)
+	go r.pollLoop()
+	go r.cleanupWorker()
+}
+
+// Stop gracefully shuts down the relay, letting the current batch finish
+func (r *OutboxRelay) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	log.Println("[OutboxRelay] Stopping...")
+	r.cancel()
+	r.wg.Wait()
+	log.Println("[OutboxRelay] Stopped")
+}
+
+// pollLoop dispatches due events until the relay is stopped
+func (r *OutboxRelay) pollLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches immediately, then wait for the next tick
+		for r.dispatchBatch() == r.batchSize {
+			if r.ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatchBatch claims and delivers one batch, returning the number of events claimed
+func (r *OutboxRelay) dispatchBatch() int {
+	ctx := context.Background()
+
+	events, err := r.outboxRepo.ClaimBatch(ctx, r.batchSize, r.lease)
+	if err != nil {
+		log.Printf("[OutboxRelay] Failed to claim events: %v", err)
+		return 0
+	}
+
+	for _, event := range events {
+		r.dispatch(ctx, event)
+	}
+	return len(events)
+}
+
+// dispatch runs the handler for one event and records the outcome
+func (r *OutboxRelay) dispatch(ctx context.Context, event *entities.OutboxEvent) {
+	r.mu.RLock()
+	handler, ok := r.handlers[event.EventType]
+	r.mu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for event type %s", event.EventType)
+	} else {
+		err = r.runHandler(ctx, handler, event)
+	}
+
+	if err == nil {
+		if markErr := r.outboxRepo.MarkDispatched(ctx, event.ID); markErr != nil {
+			log.Printf("[OutboxRelay] Failed to mark event %s dispatched: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	if !event.CanRetry() {
+		log.Printf("[OutboxRelay] Event %s (%s) dead after %d attempts: %v", event.ID, event.EventType, event.Attempts, err)
+		if markErr := r.outboxRepo.MarkDead(ctx, event.ID, err.Error()); markErr != nil {
+			log.Printf("[OutboxRelay] Failed to mark event %s dead: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	delay := BackoffWithJitter(r.retryBaseDelay, r.retryMaxDelay, event.Attempts)
+	log.Printf("[OutboxRelay] Event %s (%s) failed, retrying in %v (attempt %d of %d): %v",
+		event.ID, event.EventType, delay.Round(time.Second), event.Attempts, event.MaxAttempts, err)
+	if markErr := r.outboxRepo.MarkFailed(ctx, event.ID, err.Error(), time.Now().Add(delay)); markErr != nil {
+		log.Printf("[OutboxRelay] Failed to mark event %s failed: %v", event.ID, markErr)
+	}
+}
+
+// runHandler invokes a handler, converting panics into errors so one bad event cannot stop the relay
+func (r *OutboxRelay) runHandler(ctx context.Context, handler OutboxHandler, event *entities.OutboxEvent) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("handler panic: %v", rec)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// cleanupWorker periodically removes dispatched events
+func (r *OutboxRelay) cleanupWorker() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Keep dispatched events for 7 days for troubleshooting
+			cutoff := time.Now().AddDate(0, 0, -7)
+			if err := r.outboxRepo.DeleteDispatchedBefore(context.Background(), cutoff); err != nil {
+				log.Printf("[OutboxRelay] Cleanup failed: %v", err)
+			}
+
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitPolicy is a token bucket: clients may burst up to Burst requests,
+// after which they get PerMinute requests spread evenly over each minute
+type RateLimitPolicy struct {
+	Name      string
+	Burst     int // Bucket capacity
+	PerMinute int // Refill rate
+}
+
+// LoadRateLimitPolicy builds a policy from RATE_LIMIT_<NAME>_BURST and RATE_LIMIT_<NAME>_PER_MINUTE
+// A burst of zero or less disables the policy
+func LoadRateLimitPolicy(name string, defaultBurst, defaultPerMinute int) RateLimitPolicy {
+	prefix := "RATE_LIMIT_" + strings.ToUpper(name)
+	policy := RateLimitPolicy{
+		Name:      name,
+		Burst:     getEnvInt(prefix+"_BURST", defaultBurst),
+		PerMinute: getEnvInt(prefix+"_PER_MINUTE", defaultPerMinute),
+	}
+	if policy.PerMinute <= 0 {
+		policy.PerMinute = policy.Burst
+	}
+	return policy
+}
+
+// Enabled reports whether the policy limits anything
+func (p RateLimitPolicy) Enabled() bool {
+	return p.Burst > 0 && p.PerMinute > 0
+}
+
+func (p RateLimitPolicy) refillPerSecond() float64 {
+	return float64(p.PerMinute) / 60
+}
+
+// RateLimitResult reports the outcome of a rate limit check
+type RateLimitResult struct {
+	Policy     RateLimitPolicy
+	Allowed    bool
+	Remaining  int           // Whole tokens left in the bucket
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next request is allowed, zero when allowed
+}
+
+// RateLimiter enforces token bucket policies per client
+// Buckets live in Redis when the cache is enabled so every API instance shares them,
+// otherwise (or when Redis errors) they are kept in process memory
+type RateLimiter struct {
+	cache   *CacheService
+	enabled bool
+
+	mu        sync.Mutex
+	local     map[string]*localBucket
+	lastSweep time.Time
+}
+
+type localBucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time // After this the bucket is full and can be forgotten
+}
+
+// NewRateLimiter creates a rate limiter; RATE_LIMIT_ENABLED=false turns every policy off
+func NewRateLimiter(cache *CacheService) *RateLimiter {
+	l := &RateLimiter{
+		cache:     cache,
+		enabled:   os.Getenv("RATE_LIMIT_ENABLED") != "false",
+		local:     make(map[string]*localBucket),
+		lastSweep: time.Now(),
+	}
+
+	store := "memory"
+	if l.useRedis() {
+		store = "redis"
+	}
+	log.Printf("Rate limiter initialized (enabled: %t, store: %s)", l.enabled, store)
+	return l
+}
+
+// Allow takes a token from the subject's bucket for the policy
+// The subject identifies the client, e.g. "ip:203.0.113.7", "user:<id>" or "key:<id>"
+func (l *RateLimiter) Allow(ctx context.Context, policy RateLimitPolicy, subject string) RateLimitResult {
+	result := RateLimitResult{Policy: policy, Allowed: true, Remaining: policy.Burst}
+	if l == nil || !l.enabled || !policy.Enabled() {
+		return result
+	}
+
+	key := fmt.Sprintf("ratelimit:%s:%s", policy.Name, subject)
+	allowed, tokens := l.take(ctx, key, policy)
+
+	rate := policy.refillPerSecond()
+	result.Allowed = allowed
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = secondsToDuration((float64(policy.Burst) - tokens) / rate)
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+func (l *RateLimiter) useRedis() bool {
+	return l.cache != nil && l.cache.IsEnabled()
+}
+
+func (l *RateLimiter) take(ctx context.Context, key string, policy RateLimitPolicy) (bool, float64) {
+	if l.useRedis() {
+		allowed, tokens, err := l.cache.TakeToken(ctx, key, policy.Burst, policy.refillPerSecond())
+		if err == nil {
+			return allowed, tokens
+		}
+		log.Printf("[RateLimiter] Redis token bucket failed, using memory: %v", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	capacity := float64(policy.Burst)
+	bucket, ok := l.local[key]
+	if !ok {
+		bucket = &localBucket{tokens: capacity, updated: now}
+		l.local[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*policy.refillPerSecond())
+	bucket.updated = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	bucket.fullAt = now.Add(secondsToDuration((capacity - bucket.tokens) / policy.refillPerSecond()))
+	return allowed, bucket.tokens
+}
+
+// sweep drops buckets that have refilled completely; callers hold the lock
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.local {
+		if now.After(bucket.fullAt) {
+			delete(l.local, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
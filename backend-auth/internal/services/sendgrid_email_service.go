@@ -27,49 +27,6 @@ func NewSendGridEmailService() *SendGridEmailService {
 	}
 }
 
-// SendTicketEmail sends an e-ticket via email with PDF attachment
-func (s *SendGridEmailService) SendTicketEmail(
-	toEmail string,
-	toName string,
-	bookingReference string,
-	ticketNumber string,
-	pdfBytes []byte,
-) error {
-	// Skip sending if SendGrid API key not configured
-	if s.apiKey == "" {
-		fmt.Printf("SendGrid API key not configured - skipping email for %s\n", toEmail)
-		return nil
-	}
-
-	from := mail.NewEmail(s.fromName, s.fromEmail)
-	to := mail.NewEmail(toName, toEmail)
-	subject := fmt.Sprintf("Your E-Ticket - Booking %s", bookingReference)
-	htmlContent := s.templates.TicketEmail(toName, bookingReference, ticketNumber)
-
-	message := mail.NewSingleEmail(from, subject, to, "", htmlContent)
-
-	// Add PDF attachment
-	attachment := mail.NewAttachment()
-	encoded := base64.StdEncoding.EncodeToString(pdfBytes)
-	attachment.SetContent(encoded)
-	attachment.SetType("application/pdf")
-	attachment.SetFilename(fmt.Sprintf("ticket-%s.pdf", ticketNumber))
-	attachment.SetDisposition("attachment")
-	message.AddAttachment(attachment)
-
-	response, err := s.client.Send(message)
-	if err != nil {
-		return fmt.Errorf("failed to send email via SendGrid: %w", err)
-	}
-
-	if response.StatusCode >= 300 {
-		return fmt.Errorf("SendGrid API error (status %d): %s", response.StatusCode, response.Body)
-	}
-
-	fmt.Printf("E-ticket sent successfully to %s via SendGrid\n", toEmail)
-	return nil
-}
-
 // SendBookingConfirmationEmail sends a simple confirmation without attachment
 func (s *SendGridEmailService) SendBookingConfirmationEmail(
 	toEmail string,
@@ -109,20 +66,36 @@ func (s *SendGridEmailService) SendHTMLEmail(toEmail, toName, subject, htmlBody
 	return nil
 }
 
-// SendPaymentReceiptEmail sends payment receipt email
-func (s *SendGridEmailService) SendPaymentReceiptEmail(
-	toEmail, toName, bookingRef string,
-	amount float64,
-	transactionID string,
-) error {
+// SendEmail sends an HTML email with its plain-text alternative and attachment, when set
+func (s *SendGridEmailService) SendEmail(message EmailMessage) error {
 	if s.apiKey == "" {
-		fmt.Printf("SendGrid API key not configured - skipping payment receipt for %s\n", toEmail)
+		fmt.Printf("SendGrid API key not configured - skipping email for %s\n", message.ToEmail)
 		return nil
 	}
 
-	subject := fmt.Sprintf("Payment Receipt - Booking %s", bookingRef)
-	htmlBody := s.templates.PaymentReceiptEmail(toName, bookingRef, amount, transactionID)
-	return s.SendHTMLEmail(toEmail, toName, subject, htmlBody)
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(message.ToName, message.ToEmail)
+	email := mail.NewSingleEmail(from, message.Subject, to, message.TextBody, message.HTMLBody)
+
+	if len(message.Attachment) > 0 {
+		attachment := mail.NewAttachment()
+		attachment.SetContent(base64.StdEncoding.EncodeToString(message.Attachment))
+		attachment.SetType("application/pdf")
+		attachment.SetFilename(message.AttachmentName)
+		attachment.SetDisposition("attachment")
+		email.AddAttachment(attachment)
+	}
+
+	response, err := s.client.Send(email)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("SendGrid API error (status %d): %s", response.StatusCode, response.Body)
+	}
+
+	return nil
 }
 
 // SendTripReminderEmail sends trip reminder email
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType decides how a column's values are written, so spreadsheets can sum and sort them
+type ColumnType int
+
+const (
+	ColumnText     ColumnType = iota
+	ColumnInteger             // Whole numbers, e.g. booking counts
+	ColumnDecimal             // Amounts and rates, two decimals
+	ColumnDate                // Calendar date
+	ColumnDateTime            // Date and time of day
+)
+
+// Column describes one column of an exported table
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// TableWriter streams the rows of a single table in an export format
+// Values are matched to columns by position; nil leaves the cell empty
+type TableWriter interface {
+	WriteRow(values ...interface{}) error
+	// Close finishes the file. It does not close the underlying writer
+	Close() error
+}
+
+// Export formats supported by NewTableWriter
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// ExportContentType returns the MIME type of an export format
+func ExportContentType(format string) string {
+	if format == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewTableWriter writes the header row and returns a writer for the given format
+func NewTableWriter(w io.Writer, format, sheetName string, columns []Column) (TableWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVTableWriter(w, columns)
+	case ExportFormatXLSX:
+		return newXLSXTableWriter(w, sheetName, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// csvTableWriter writes RFC 4180 CSV with ISO 8601 dates
+type csvTableWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+func newCSVTableWriter(w io.Writer, columns []Column) (*csvTableWriter, error) {
+	t := &csvTableWriter{w: csv.NewWriter(w), columns: columns}
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return t, t.w.Write(header)
+}
+
+func (t *csvTableWriter) WriteRow(values ...interface{}) error {
+	record := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		if i < len(values) {
+			record[i] = formatCSVValue(col.Type, values[i])
+		}
+	}
+	if err := t.w.Write(record); err != nil {
+		return err
+	}
+	// Flush per row so large exports reach the client as they are produced
+	t.w.Flush()
+	return t.w.Error()
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+func formatCSVValue(colType ColumnType, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if colType == ColumnDate {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatCSVValue(colType, *v)
+	case float64:
+		if colType == ColumnDecimal {
+			return strconv.FormatFloat(v, 'f', 2, 64)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return formatCSVValue(colType, *v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Cell styles defined in xlsxStyles, by index
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleInteger
+	xlsxStyleDecimal
+	xlsxStyleDate
+	xlsxStyleDateTime
+)
+
+// xlsxTableWriter writes a single-sheet workbook. The sheet is streamed into the zip entry row by row;
+// strings are stored inline so no shared string table has to be built up in memory first
+type xlsxTableWriter struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	row     int
+}
+
+func newXLSXTableWriter(w io.Writer, sheetName string, columns []Column) (*xlsxTableWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(xlsxSheetName(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	t := &xlsxTableWriter{zw: zw, sheet: bufio.NewWriter(f), columns: columns}
+
+	t.sheet.WriteString(xml.Header)
+	t.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	t.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	t.sheet.WriteString(`<cols>`)
+	for i, col := range columns {
+		fmt.Fprintf(t.sheet, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, xlsxColumnWidth(col))
+	}
+	t.sheet.WriteString(`</cols><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return t, t.writeRow(header, true)
+}
+
+func (t *xlsxTableWriter) WriteRow(values ...interface{}) error {
+	return t.writeRow(values, false)
+}
+
+func (t *xlsxTableWriter) writeRow(values []interface{}, header bool) error {
+	t.row++
+	fmt.Fprintf(t.sheet, `<row r="%d">`, t.row)
+	for i, col := range t.columns {
+		if i >= len(values) {
+			break
+		}
+		ref := xlsxColumnName(i) + strconv.Itoa(t.row)
+		if header {
+			t.writeString(ref, fmt.Sprint(values[i]), xlsxStyleHeader)
+			continue
+		}
+		t.writeCell(ref, col.Type, values[i])
+	}
+	t.sheet.WriteString(`</row>`)
+	// Keep the buffer from growing with the sheet; the zip entry compresses as it goes
+	if t.sheet.Buffered() > 32*1024 {
+		return t.sheet.Flush()
+	}
+	return nil
+}
+
+func (t *xlsxTableWriter) writeCell(ref string, colType ColumnType, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case *time.Time:
+		if v != nil {
+			t.writeCell(ref, colType, *v)
+		}
+		return
+	case *float64:
+		if v != nil {
+			t.writeCell(ref, colType, *v)
+		}
+		return
+	case time.Time:
+		if v.IsZero() {
+			return
+		}
+		style := xlsxStyleDateTime
+		if colType == ColumnDate {
+			style = xlsxStyleDate
+		}
+		t.writeNumber(ref, excelSerial(v), style)
+	case int:
+		t.writeNumber(ref, float64(v), xlsxNumberStyle(colType))
+	case int64:
+		t.writeNumber(ref, float64(v), xlsxNumberStyle(colType))
+	case float64:
+		t.writeNumber(ref, v, xlsxNumberStyle(colType))
+	default:
+		t.writeString(ref, fmt.Sprint(v), xlsxStyleDefault)
+	}
+}
+
+func (t *xlsxTableWriter) writeNumber(ref string, value float64, style int) {
+	fmt.Fprintf(t.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (t *xlsxTableWriter) writeString(ref, value string, style int) {
+	fmt.Fprintf(t.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(value))
+}
+
+func (t *xlsxTableWriter) Close() error {
+	t.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := t.sheet.Flush(); err != nil {
+		return err
+	}
+	return t.zw.Close()
+}
+
+func xlsxNumberStyle(colType ColumnType) int {
+	if colType == ColumnDecimal {
+		return xlsxStyleDecimal
+	}
+	return xlsxStyleInteger
+}
+
+func xlsxColumnWidth(col Column) int {
+	width := len(col.Name) + 2
+	switch col.Type {
+	case ColumnDate:
+		width = max(width, 12)
+	case ColumnDateTime:
+		width = max(width, 18)
+	case ColumnText:
+		width = max(width, 20)
+	default:
+		width = max(width, 10)
+	}
+	return width
+}
+
+// xlsxColumnName converts a zero-based column index to its letters: 0 is A, 26 is AA
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName trims a sheet name to Excel's 31 characters without the characters it rejects
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+// excelSerial converts a time to Excel's day count since 1899-12-30, keeping the wall clock time
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return wall.Sub(epoch).Hours() / 24
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell formats in the order of the xlsxStyle constants
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
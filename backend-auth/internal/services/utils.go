@@ -1,8 +1,10 @@
 package services
 
 import (
+	"math/rand"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Utility functions for environment variables (shared across services)
@@ -26,3 +28,25 @@ func getEnvFloat32(key string, defaultValue float32) float32 {
 	}
 	return defaultValue
 }
+
+// BackoffWithJitter returns base * 2^(attempt-1) capped at max
+// Full jitter is applied over the upper half of the window to avoid retry storms
+func BackoffWithJitter(base, max time.Duration, attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,239 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+const (
+	// maxBackfillDays bounds a single backfill (and coverage check) to about a year
+	maxBackfillDays = 366
+	// backfillStaleAfter is how long a running backfill may go without progress before another worker resumes it
+	backfillStaleAfter = 10 * time.Minute
+)
+
+// AnalyticsRecomputer rebuilds the stored booking, route and trip aggregates of one day
+type AnalyticsRecomputer interface {
+	RecomputeAnalytics(ctx context.Context, date time.Time) error
+}
+
+// AnalyticsBackfillUsecase recomputes historical analytics on demand
+// The nightly job only covers recent days; this fills gaps and repairs days after an aggregation fix
+type AnalyticsBackfillUsecase struct {
+	backfillRepo repositories.AnalyticsBackfillRepository
+	recomputer   AnalyticsRecomputer
+	analytics    *AnalyticsUsecase
+}
+
+// NewAnalyticsBackfillUsecase creates a new analytics backfill usecase
+func NewAnalyticsBackfillUsecase(
+	backfillRepo repositories.AnalyticsBackfillRepository,
+	recomputer AnalyticsRecomputer,
+	analytics *AnalyticsUsecase,
+) *AnalyticsBackfillUsecase {
+	return &AnalyticsBackfillUsecase{
+		backfillRepo: backfillRepo,
+		recomputer:   recomputer,
+		analytics:    analytics,
+	}
+}
+
+// StartBackfill queues a recomputation of every day from startDate to endDate and runs it in the background
+// Only one backfill runs at a time; today is excluded because its aggregates are computed tomorrow
+func (uc *AnalyticsBackfillUsecase) StartBackfill(ctx context.Context, startDate, endDate time.Time, requestedBy *uuid.UUID) (*entities.AnalyticsBackfill, error) {
+	start, end := startOfDay(startDate), startOfDay(endDate)
+	if end.Before(start) {
+		return nil, errors.New("end_date must not be before start_date")
+	}
+	if !end.Before(startOfDay(time.Now())) {
+		return nil, errors.New("end_date must be before today")
+	}
+	days := daysBetween(start, end) + 1
+	if days > maxBackfillDays {
+		return nil, fmt.Errorf("a backfill can cover at most %d days", maxBackfillDays)
+	}
+
+	active, err := uc.backfillRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check running backfills: %w", err)
+	}
+	if active != nil {
+		return nil, fmt.Errorf("backfill %s is already running", active.ID)
+	}
+
+	// Stored as plain dates; run maps each one back to the local day it names
+	backfill := &entities.AnalyticsBackfill{
+		StartDate:   time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC),
+		Status:      entities.BackfillPending,
+		TotalDays:   days,
+		RequestedBy: requestedBy,
+	}
+	if err := uc.backfillRepo.Create(ctx, backfill); err != nil {
+		// The active-backfill unique index catches a start racing the check above
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, errors.New("another backfill is already running")
+		}
+		return nil, fmt.Errorf("failed to create backfill: %w", err)
+	}
+
+	go uc.run(backfill.ID)
+
+	backfill.SetProgress()
+	return backfill, nil
+}
+
+// GetBackfill returns a backfill with its progress
+func (uc *AnalyticsBackfillUsecase) GetBackfill(ctx context.Context, id uuid.UUID) (*entities.AnalyticsBackfill, error) {
+	backfill, err := uc.backfillRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("backfill not found: %w", err)
+	}
+	backfill.SetProgress()
+	return backfill, nil
+}
+
+// ListBackfills returns a page of backfills, newest first
+func (uc *AnalyticsBackfillUsecase) ListBackfills(ctx context.Context, page, pageSize int) ([]*entities.AnalyticsBackfill, int64, error) {
+	backfills, total, err := uc.backfillRepo.List(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, backfill := range backfills {
+		backfill.SetProgress()
+	}
+	return backfills, total, nil
+}
+
+// CancelBackfill stops a backfill after the day it is working on; days already recomputed stay recomputed
+func (uc *AnalyticsBackfillUsecase) CancelBackfill(ctx context.Context, id uuid.UUID) (*entities.AnalyticsBackfill, error) {
+	cancelled, err := uc.backfillRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel backfill: %w", err)
+	}
+
+	backfill, err := uc.GetBackfill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled && !backfill.IsActive() {
+		return nil, fmt.Errorf("backfill has already %s", backfill.Status)
+	}
+
+	log.Printf("[Analytics] Backfill %s cancelled after %d of %d days", id, backfill.ProcessedDays, backfill.TotalDays)
+	return backfill, nil
+}
+
+// ResumeStalled restarts backfills left behind by a crashed or restarted instance
+// They continue after the last recorded day, which is safe because every day is upserted
+func (uc *AnalyticsBackfillUsecase) ResumeStalled(ctx context.Context) error {
+	stalled, err := uc.backfillRepo.ListStalled(ctx, time.Now().Add(-backfillStaleAfter))
+	if err != nil {
+		return fmt.Errorf("failed to list stalled backfills: %w", err)
+	}
+	for _, backfill := range stalled {
+		log.Printf("[Analytics] Resuming backfill %s at day %d of %d", backfill.ID, backfill.ProcessedDays+1, backfill.TotalDays)
+		go uc.run(backfill.ID)
+	}
+	return nil
+}
+
+// ListMissingDates returns the days in the range whose booking, route or trip aggregates were never stored
+// The range is capped at yesterday, the latest day the nightly job covers
+func (uc *AnalyticsBackfillUsecase) ListMissingDates(ctx context.Context, startDate, endDate time.Time) ([]*entities.MissingAnalyticsDate, error) {
+	start, end := startOfDay(startDate), startOfDay(endDate)
+	if yesterday := startOfDay(time.Now()).AddDate(0, 0, -1); end.After(yesterday) {
+		end = yesterday
+	}
+	if end.Before(start) {
+		return []*entities.MissingAnalyticsDate{}, nil
+	}
+	if daysBetween(start, end)+1 > maxBackfillDays {
+		return nil, fmt.Errorf("a coverage check can cover at most %d days", maxBackfillDays)
+	}
+
+	missing, err := uc.backfillRepo.FindMissingDates(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check analytics coverage: %w", err)
+	}
+	return missing, nil
+}
+
+// run processes the backfill's remaining days oldest first, stopping as soon as it is cancelled
+func (uc *AnalyticsBackfillUsecase) run(id uuid.UUID) {
+	ctx := context.Background()
+
+	claimed, err := uc.backfillRepo.Claim(ctx, id, time.Now().Add(-backfillStaleAfter))
+	if err != nil {
+		log.Printf("[Analytics] Failed to start backfill %s: %v", id, err)
+		return
+	}
+	if !claimed {
+		return // Cancelled, finished or picked up by another instance
+	}
+
+	backfill, err := uc.backfillRepo.GetByID(ctx, id)
+	if err != nil {
+		log.Printf("[Analytics] Failed to load backfill %s: %v", id, err)
+		return
+	}
+	log.Printf("[Analytics] Backfill %s running from %s to %s", id,
+		backfill.StartDate.Format("2006-01-02"), backfill.EndDate.Format("2006-01-02"))
+
+	// Reports cached before the backfill would hide the recomputed rows
+	defer func() {
+		if err := uc.analytics.InvalidateCache(ctx); err != nil {
+			log.Printf("[Analytics] Failed to invalidate cache after backfill %s: %v", id, err)
+		}
+	}()
+
+	for i := backfill.ProcessedDays; i < backfill.TotalDays; i++ {
+		day := backfill.StartDate.AddDate(0, 0, i)
+		if err := uc.recomputer.RecomputeAnalytics(ctx, day); err != nil {
+			uc.fail(ctx, id, fmt.Errorf("%s: %w", day.Format("2006-01-02"), err))
+			return
+		}
+
+		running, err := uc.backfillRepo.RecordProgress(ctx, id, i+1, day)
+		if err != nil {
+			uc.fail(ctx, id, fmt.Errorf("failed to record progress: %w", err))
+			return
+		}
+		if !running {
+			log.Printf("[Analytics] Backfill %s stopped at %s", id, day.Format("2006-01-02"))
+			return
+		}
+	}
+
+	if err := uc.backfillRepo.Finish(ctx, id, entities.BackfillCompleted, nil); err != nil {
+		log.Printf("[Analytics] Failed to complete backfill %s: %v", id, err)
+		return
+	}
+	log.Printf("[Analytics] Backfill %s completed: %d days recomputed", id, backfill.TotalDays)
+}
+
+func (uc *AnalyticsBackfillUsecase) fail(ctx context.Context, id uuid.UUID, cause error) {
+	log.Printf("[Analytics] Backfill %s failed: %v", id, cause)
+	msg := cause.Error()
+	if err := uc.backfillRepo.Finish(ctx, id, entities.BackfillFailed, &msg); err != nil {
+		log.Printf("[Analytics] Failed to record failure of backfill %s: %v", id, err)
+	}
+}
+
+// startOfDay returns local midnight of t's calendar day, the key the nightly aggregation stores rows under
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// daysBetween counts calendar days from start to end, rounding away DST shifts
+func daysBetween(start, end time.Time) int {
+	return int((end.Sub(start).Hours() + 12) / 24)
+}
@@ -4,8 +4,12 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
 	"github.com/yourusername/bus-booking-auth/internal/services"
@@ -17,9 +21,13 @@ type AnalyticsUsecase struct {
 	bookingRepo          repositories.BookingRepository
 	bookingAnalyticsRepo repositories.BookingAnalyticsRepository
 	routeAnalyticsRepo   repositories.RouteAnalyticsRepository
+	tripAnalyticsRepo    repositories.TripAnalyticsRepository
+	funnelEventRepo      repositories.FunnelEventRepository
 	tripRepo             repositories.TripRepository
 	routeRepo            repositories.RouteRepository
 	cacheService         *services.CacheService
+	emailService         services.EmailProvider
+	reportRecipients     []string // Admin addresses that get the weekly summary
 }
 
 // NewAnalyticsUsecase creates a new analytics usecase
@@ -27,18 +35,48 @@ func NewAnalyticsUsecase(
 	bookingRepo repositories.BookingRepository,
 	bookingAnalyticsRepo repositories.BookingAnalyticsRepository,
 	routeAnalyticsRepo repositories.RouteAnalyticsRepository,
+	tripAnalyticsRepo repositories.TripAnalyticsRepository,
+	funnelEventRepo repositories.FunnelEventRepository,
 	tripRepo repositories.TripRepository,
 	routeRepo repositories.RouteRepository,
 	cacheService *services.CacheService,
+	emailService services.EmailProvider,
 ) *AnalyticsUsecase {
+	var recipients []string
+	for _, addr := range strings.Split(os.Getenv("ANALYTICS_REPORT_RECIPIENTS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+
 	return &AnalyticsUsecase{
 		bookingRepo:          bookingRepo,
 		bookingAnalyticsRepo: bookingAnalyticsRepo,
 		routeAnalyticsRepo:   routeAnalyticsRepo,
+		tripAnalyticsRepo:    tripAnalyticsRepo,
+		funnelEventRepo:      funnelEventRepo,
 		tripRepo:             tripRepo,
 		routeRepo:            routeRepo,
 		cacheService:         cacheService,
+		emailService:         emailService,
+		reportRecipients:     recipients,
+	}
+}
+
+// analyticsCacheKey keeps cached reports of each operator apart from the platform-wide ones
+func analyticsCacheKey(ctx context.Context, key string) string {
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		return key + ":op:" + operatorID.String()
 	}
+	return key
+}
+
+// InvalidateCache drops cached analytics so the next request reflects new bookings
+func (u *AnalyticsUsecase) InvalidateCache(ctx context.Context) error {
+	if u.cacheService == nil {
+		return nil
+	}
+	return u.cacheService.Invalidate(ctx, "analytics:*")
 }
 
 // BookingTrendData represents booking trends over time
@@ -53,7 +91,7 @@ type BookingTrendData struct {
 // GetBookingTrends returns booking trends for a date range
 // Used for trend charts showing bookings over time
 func (u *AnalyticsUsecase) GetBookingTrends(ctx context.Context, startDate, endDate time.Time) ([]BookingTrendData, error) {
-	cacheKey := fmt.Sprintf("analytics:trends:%s:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	cacheKey := analyticsCacheKey(ctx, fmt.Sprintf("analytics:trends:%s:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
 
 	// Try cache first
 	if u.cacheService != nil && u.cacheService.IsEnabled() {
@@ -99,7 +137,7 @@ type RevenueSummary struct {
 
 // GetRevenueSummary returns revenue summary for a date range
 func (u *AnalyticsUsecase) GetRevenueSummary(ctx context.Context, startDate, endDate time.Time) (*RevenueSummary, error) {
-	cacheKey := fmt.Sprintf("analytics:revenue:%s:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	cacheKey := analyticsCacheKey(ctx, fmt.Sprintf("analytics:revenue:%s:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
 
 	// Try cache first
 	if u.cacheService != nil && u.cacheService.IsEnabled() {
@@ -191,21 +229,89 @@ func (u *AnalyticsUsecase) GetConversionRate(ctx context.Context, startDate, end
 	}, nil
 }
 
+// FunnelStep is one step of a route's booking funnel
+type FunnelStep struct {
+	Step       entities.FunnelEventType `json:"step"`
+	Sessions   int                      `json:"sessions"`
+	DropOff    float64                  `json:"drop_off"`   // % of the previous step's sessions that did not reach this one
+	Conversion float64                  `json:"conversion"` // % of searching sessions that reached this step
+}
+
+// RouteFunnelData represents the search-to-payment funnel of a route
+type RouteFunnelData struct {
+	RouteID        uuid.UUID    `json:"route_id"`
+	Origin         string       `json:"origin"`
+	Destination    string       `json:"destination"`
+	Steps          []FunnelStep `json:"steps"`
+	ConversionRate float64      `json:"conversion_rate"` // Searching sessions that completed a payment
+}
+
+// GetRouteFunnels returns the search-to-payment drop-off per route
+// Unlike GetConversionRate it counts visitor sessions, so searches that never became a booking are included
+func (u *AnalyticsUsecase) GetRouteFunnels(ctx context.Context, startDate, endDate time.Time) ([]RouteFunnelData, error) {
+	funnels, err := u.funnelEventRepo.GetRouteFunnels(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funnel events: %w", err)
+	}
+
+	result := make([]RouteFunnelData, len(funnels))
+	for i, f := range funnels {
+		counts := []struct {
+			step     entities.FunnelEventType
+			sessions int
+		}{
+			{entities.FunnelSearch, f.Searches},
+			{entities.FunnelTripView, f.TripViews},
+			{entities.FunnelSeatsReserved, f.SeatsReserved},
+			{entities.FunnelBookingCreated, f.BookingsCreated},
+			{entities.FunnelPaymentStarted, f.PaymentsStarted},
+			{entities.FunnelPaymentCompleted, f.PaymentsCompleted},
+		}
+
+		steps := make([]FunnelStep, len(counts))
+		for j, c := range counts {
+			steps[j] = FunnelStep{Step: c.step, Sessions: c.sessions, Conversion: percentOf(c.sessions, f.Searches)}
+			// Visitors can enter mid-funnel (e.g. from a shared trip link), so a step may outnumber the one before it
+			if j > 0 && counts[j-1].sessions > c.sessions {
+				steps[j].DropOff = percentOf(counts[j-1].sessions-c.sessions, counts[j-1].sessions)
+			}
+		}
+
+		result[i] = RouteFunnelData{
+			RouteID:        f.RouteID,
+			Origin:         f.Origin,
+			Destination:    f.Destination,
+			Steps:          steps,
+			ConversionRate: percentOf(f.PaymentsCompleted, f.Searches),
+		}
+	}
+	return result, nil
+}
+
+// GetUnmetDemand returns the searches that found no trips, grouped by origin, destination and travel date
+func (u *AnalyticsUsecase) GetUnmetDemand(ctx context.Context, startDate, endDate time.Time, limit int) ([]*entities.UnmetDemand, error) {
+	demand, err := u.funnelEventRepo.GetUnmetDemand(ctx, startDate, endDate, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmet demand: %w", err)
+	}
+	return demand, nil
+}
+
 // PopularRouteData represents route popularity metrics
 type PopularRouteData struct {
-	RouteID       uint    `json:"route_id"`
-	RouteName     string  `json:"route_name"`
-	Origin        string  `json:"origin"`
-	Destination   string  `json:"destination"`
-	TotalBookings int     `json:"total_bookings"`
-	TotalRevenue  float64 `json:"total_revenue"`
-	AvgOccupancy  float64 `json:"avg_occupancy"`
+	RouteID       uuid.UUID `json:"route_id"`
+	RouteName     string    `json:"route_name"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	TotalBookings int       `json:"total_bookings"`
+	TotalRevenue  float64   `json:"total_revenue"`
+	AvgOccupancy  float64   `json:"avg_occupancy"`
 }
 
 // GetPopularRoutes returns top routes by revenue or booking count
 // orderBy can be "revenue" or "bookings"
 func (u *AnalyticsUsecase) GetPopularRoutes(ctx context.Context, startDate, endDate time.Time, limit int, orderBy string) ([]PopularRouteData, error) {
-	cacheKey := fmt.Sprintf("analytics:popular:%s:%s:%d:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), limit, orderBy)
+	cacheKey := analyticsCacheKey(ctx, fmt.Sprintf("analytics:popular:%s:%s:%d:%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), limit, orderBy))
 
 	// Try cache first
 	if u.cacheService != nil && u.cacheService.IsEnabled() {
@@ -238,7 +344,7 @@ func (u *AnalyticsUsecase) GetPopularRoutes(ctx context.Context, startDate, endD
 		}
 
 		results = append(results, PopularRouteData{
-			RouteID:       0, // Note: RouteID is UUID in entities, uint here - type mismatch
+			RouteID:       route.ID,
 			RouteName:     route.Origin + " - " + route.Destination,
 			Origin:        route.Origin,
 			Destination:   route.Destination,
@@ -351,29 +457,177 @@ func (u *AnalyticsUsecase) GetDashboardSummary(ctx context.Context) (*DashboardS
 
 // RoutePerformanceData represents detailed route performance metrics
 type RoutePerformanceData struct {
-	RouteID          uint                  `json:"route_id"`
-	RouteName        string                `json:"route_name"`
-	Origin           string                `json:"origin"`
-	Destination      string                `json:"destination"`
-	TotalBookings    int                   `json:"total_bookings"`
-	TotalRevenue     float64               `json:"total_revenue"`
-	AvgOccupancyRate float64               `json:"avg_occupancy_rate"`
-	DailyTrends      []RoutePerformanceDay `json:"daily_trends"`
+	RouteID           uuid.UUID                       `json:"route_id"`
+	RouteName         string                          `json:"route_name"`
+	Origin            string                          `json:"origin"`
+	Destination       string                          `json:"destination"`
+	StartDate         time.Time                       `json:"start_date"`
+	EndDate           time.Time                       `json:"end_date"`
+	TotalTrips        int                             `json:"total_trips"`
+	TotalBookings     int                             `json:"total_bookings"`
+	CancelledBookings int                             `json:"cancelled_bookings"`
+	CancellationRate  float64                         `json:"cancellation_rate"`
+	TotalRevenue      float64                         `json:"total_revenue"`
+	AvgOccupancyRate  float64                         `json:"avg_occupancy_rate"`
+	AverageRating     float64                         `json:"average_rating"`
+	RatingCount       int                             `json:"rating_count"`
+	DailyTrends       []RoutePerformanceDay           `json:"daily_trends"`
+	DepartureTimes    []RouteDepartureTimePerformance `json:"departure_times"`
 }
 
 // RoutePerformanceDay represents daily metrics for a route
 type RoutePerformanceDay struct {
-	Date          time.Time `json:"date"`
-	Bookings      int       `json:"bookings"`
-	Revenue       float64   `json:"revenue"`
-	OccupancyRate float64   `json:"occupancy_rate"`
+	Date             time.Time `json:"date"`
+	Trips            int       `json:"trips"`
+	Bookings         int       `json:"bookings"`
+	Revenue          float64   `json:"revenue"`
+	OccupancyRate    float64   `json:"occupancy_rate"`
+	CancellationRate float64   `json:"cancellation_rate"`
+	AverageRating    float64   `json:"average_rating"`
+	RatingCount      int       `json:"rating_count"`
+}
+
+// RouteDepartureTimePerformance represents the metrics of one departure time slot on a route
+type RouteDepartureTimePerformance struct {
+	DepartureTime    string  `json:"departure_time"` // HH:MM
+	Trips            int     `json:"trips"`
+	Bookings         int     `json:"bookings"`
+	Revenue          float64 `json:"revenue"`
+	RevenuePerTrip   float64 `json:"revenue_per_trip"`
+	OccupancyRate    float64 `json:"occupancy_rate"`
+	OccupancyVsRoute float64 `json:"occupancy_vs_route"` // Percentage points above (+) or below (-) the route average
+	CancellationRate float64 `json:"cancellation_rate"`
+	AverageRating    float64 `json:"average_rating"`
+	RatingCount      int     `json:"rating_count"`
 }
 
 // GetRoutePerformance returns detailed performance metrics for a specific route
-func (u *AnalyticsUsecase) GetRoutePerformance(ctx context.Context, routeID uint, startDate, endDate time.Time) (*RoutePerformanceData, error) {
-	// Note: routeID is uint but entities use UUID - need conversion
-	// For now, simplify this method since ID type mismatch makes it complex
-	return nil, fmt.Errorf("GetRoutePerformance temporarily disabled due to ID type mismatch")
+// Daily trends come from the route analytics aggregates; departure times are computed from the trips in the range
+func (u *AnalyticsUsecase) GetRoutePerformance(ctx context.Context, routeID uuid.UUID, startDate, endDate time.Time) (*RoutePerformanceData, error) {
+	cacheKey := analyticsCacheKey(ctx, fmt.Sprintf("analytics:route:%s:%s:%s", routeID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+
+	// Try cache first
+	if u.cacheService != nil && u.cacheService.IsEnabled() {
+		var cached RoutePerformanceData
+		if err := u.cacheService.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	route, err := u.routeRepo.GetByID(ctx, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("route not found")
+	}
+
+	daily, err := u.routeAnalyticsRepo.GetByRouteIDAndDateRange(ctx, routeID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route analytics: %w", err)
+	}
+
+	departures, err := u.routeAnalyticsRepo.GetDepartureBreakdown(ctx, routeID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departure breakdown: %w", err)
+	}
+
+	result := &RoutePerformanceData{
+		RouteID:        route.ID,
+		RouteName:      route.Origin + " - " + route.Destination,
+		Origin:         route.Origin,
+		Destination:    route.Destination,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		DailyTrends:    make([]RoutePerformanceDay, len(daily)),
+		DepartureTimes: make([]RouteDepartureTimePerformance, len(departures)),
+	}
+
+	var seatsBooked, seatsAvailable int
+	var ratingSum float64
+	for i, a := range daily {
+		result.DailyTrends[i] = RoutePerformanceDay{
+			Date:             a.Date,
+			Trips:            a.TotalTrips,
+			Bookings:         a.TotalBookings,
+			Revenue:          a.TotalRevenue,
+			OccupancyRate:    a.AverageOccupancyRate,
+			CancellationRate: percentOf(a.CancelledBookings, a.TotalBookings),
+			AverageRating:    a.AverageRating,
+			RatingCount:      a.RatingCount,
+		}
+
+		result.TotalTrips += a.TotalTrips
+		result.TotalBookings += a.TotalBookings
+		result.CancelledBookings += a.CancelledBookings
+		result.TotalRevenue += a.TotalRevenue
+		result.RatingCount += a.RatingCount
+		ratingSum += a.AverageRating * float64(a.RatingCount)
+		seatsBooked += a.TotalSeatsBooked
+		seatsAvailable += a.TotalSeatsAvailable
+	}
+
+	// Weight by seats and reviews so quiet days don't skew the totals
+	result.CancellationRate = percentOf(result.CancelledBookings, result.TotalBookings)
+	result.AvgOccupancyRate = percentOf(seatsBooked, seatsAvailable)
+	if result.RatingCount > 0 {
+		result.AverageRating = ratingSum / float64(result.RatingCount)
+	}
+
+	// Compare each slot against the whole range, not only the days that have aggregates yet
+	var slotSeatsBooked, slotSeatsAvailable int
+	for _, d := range departures {
+		slotSeatsBooked += d.TotalSeatsBooked
+		slotSeatsAvailable += d.TotalSeatsAvailable
+	}
+	routeOccupancy := percentOf(slotSeatsBooked, slotSeatsAvailable)
+
+	for i, d := range departures {
+		occupancy := percentOf(d.TotalSeatsBooked, d.TotalSeatsAvailable)
+		revenuePerTrip := 0.0
+		if d.TotalTrips > 0 {
+			revenuePerTrip = d.TotalRevenue / float64(d.TotalTrips)
+		}
+		result.DepartureTimes[i] = RouteDepartureTimePerformance{
+			DepartureTime:    d.DepartureTime,
+			Trips:            d.TotalTrips,
+			Bookings:         d.TotalBookings,
+			Revenue:          d.TotalRevenue,
+			RevenuePerTrip:   revenuePerTrip,
+			OccupancyRate:    occupancy,
+			OccupancyVsRoute: occupancy - routeOccupancy,
+			CancellationRate: percentOf(d.CancelledBookings, d.TotalBookings),
+			AverageRating:    d.AverageRating,
+			RatingCount:      d.RatingCount,
+		}
+	}
+
+	// Cache the result
+	if u.cacheService != nil && u.cacheService.IsEnabled() {
+		_ = u.cacheService.Set(ctx, cacheKey, result, "analytics")
+	}
+
+	return result, nil
+}
+
+// percentOf returns part as a percentage of total, or 0 when total is 0
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// ListTripPerformance returns load factor and yield per departure, newest first
+// Rows are computed nightly, so trips appear the day after they depart
+func (u *AnalyticsUsecase) ListTripPerformance(ctx context.Context, filter repositories.TripAnalyticsFilter, page, pageSize int) ([]*entities.TripAnalytics, int64, error) {
+	trips, total, err := u.tripAnalyticsRepo.List(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get trip analytics: %w", err)
+	}
+	return trips, total, nil
+}
+
+// ExportTripPerformance calls fn for every departure matching the filter, latest first
+func (u *AnalyticsUsecase) ExportTripPerformance(ctx context.Context, filter repositories.TripAnalyticsFilter, fn func(*entities.TripAnalytics) error) error {
+	return u.tripAnalyticsRepo.Each(ctx, filter, fn)
 }
 
 // RevenueByTimeOfDay represents revenue distribution by time periods
@@ -479,3 +733,47 @@ func (u *AnalyticsUsecase) ComparePeriods(ctx context.Context, currentStart, cur
 
 	return comparison, nil
 }
+
+// SendWeeklySummary emails last week's key metrics to the configured report recipients
+// The week ends yesterday, so the Monday run covers Monday to Sunday
+func (u *AnalyticsUsecase) SendWeeklySummary(ctx context.Context) error {
+	if len(u.reportRecipients) == 0 || u.emailService == nil {
+		return nil
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart, weekEnd := today.AddDate(0, 0, -7), today.AddDate(0, 0, -1)
+
+	comparison, err := u.ComparePeriods(ctx, weekStart, weekEnd, weekStart.AddDate(0, 0, -7), weekStart.AddDate(0, 0, -1))
+	if err != nil {
+		return fmt.Errorf("failed to compare weeks: %w", err)
+	}
+
+	topRoutes, err := u.GetPopularRoutes(ctx, weekStart, weekEnd, 5, "revenue")
+	if err != nil {
+		return fmt.Errorf("failed to get top routes: %w", err)
+	}
+	routes := make([]services.WeeklyReportRoute, len(topRoutes))
+	for i, route := range topRoutes {
+		routes[i] = services.WeeklyReportRoute{Name: route.RouteName, Bookings: route.TotalBookings, Revenue: route.TotalRevenue}
+	}
+
+	current := comparison.Current
+	body := services.NewEmailTemplates().WeeklyAnalyticsEmail(weekStart, weekEnd,
+		current.TotalBookings, current.ConfirmedBookings, current.TotalRevenue, current.ConversionRate,
+		comparison.Changes.BookingsChange, comparison.Changes.RevenueChange, routes)
+	subject := fmt.Sprintf("Weekly summary %s to %s", weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+
+	var failed int
+	for _, recipient := range u.reportRecipients {
+		if err := u.emailService.SendHTMLEmail(recipient, "Admin", subject, body); err != nil {
+			log.Printf("[Analytics] Failed to send weekly summary to %s: %v", recipient, err)
+			failed++
+		}
+	}
+	if failed == len(u.reportRecipients) {
+		return fmt.Errorf("weekly summary could not be sent to any recipient")
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const (
+	apiKeyPrefix           = "bbk_" // Lets partners and secret scanners recognise our keys
+	apiKeyDisplayLength    = 12     // Characters kept in KeyPrefix
+	apiKeyDefaultRateLimit = 60     // Requests per minute
+	apiKeyMaxRotationGrace = 7 * 24 * time.Hour
+	apiKeyTouchInterval    = time.Minute // Minimum gap between last-used updates
+)
+
+// APIKeyUsecase manages partner API keys and authenticates partner requests
+type APIKeyUsecase struct {
+	apiKeyRepo repositories.APIKeyRepository
+	limiter    *services.RateLimiter
+}
+
+// NewAPIKeyUsecase creates a new API key usecase
+func NewAPIKeyUsecase(apiKeyRepo repositories.APIKeyRepository, limiter *services.RateLimiter) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		limiter:    limiter,
+	}
+}
+
+// IssueAPIKeyInput describes a new partner key
+type IssueAPIKeyInput struct {
+	Name               string
+	PartnerName        string
+	Scopes             []entities.APIKeyScope
+	RateLimitPerMinute int      // Defaults to 60
+	AllowedIPs         []string // IPs or CIDR ranges; empty allows any address
+}
+
+// IssuedAPIKey is a newly created key together with its secret, which is only ever returned once
+type IssuedAPIKey struct {
+	Key    *entities.APIKey `json:"key"`
+	Secret string           `json:"secret"`
+}
+
+// hashAPIKey returns the value stored for a key; the raw key is only known to the partner
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueKey creates a new API key for a partner
+func (u *APIKeyUsecase) IssueKey(ctx context.Context, input IssueAPIKeyInput, actorID *uuid.UUID) (*IssuedAPIKey, error) {
+	if strings.TrimSpace(input.Name) == "" || strings.TrimSpace(input.PartnerName) == "" {
+		return nil, errors.New("invalid API key: name and partner name are required")
+	}
+	if len(input.Scopes) == 0 {
+		return nil, errors.New("invalid API key: at least one scope is required")
+	}
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("invalid API key scope %q", scope)
+		}
+		scopes = append(scopes, string(scope))
+	}
+	allowedIPs, err := normalizeAllowedIPs(input.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit := input.RateLimitPerMinute
+	if rateLimit <= 0 {
+		rateLimit = apiKeyDefaultRateLimit
+	}
+
+	key := &entities.APIKey{
+		Name:               strings.TrimSpace(input.Name),
+		PartnerName:        strings.TrimSpace(input.PartnerName),
+		Scopes:             strings.Join(scopes, ","),
+		RateLimitPerMinute: rateLimit,
+		AllowedIPs:         allowedIPs,
+		CreatedBy:          actorID,
+	}
+	return u.createWithSecret(ctx, key)
+}
+
+// ListKeys returns partner keys, optionally for one partner and including revoked ones
+func (u *APIKeyUsecase) ListKeys(ctx context.Context, partnerName string, includeRevoked bool) ([]*entities.APIKey, error) {
+	keys, err := u.apiKeyRepo.List(ctx, partnerName, includeRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetKey returns a partner key by ID
+func (u *APIKeyUsecase) GetKey(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	key, err := u.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("API key not found")
+	}
+	return key, nil
+}
+
+// RotateKey issues a replacement with the same partner, scopes and limits
+// The old key keeps working for the grace period so the partner can deploy the new one; zero revokes it immediately
+func (u *APIKeyUsecase) RotateKey(ctx context.Context, id uuid.UUID, grace time.Duration, actorID *uuid.UUID) (*IssuedAPIKey, error) {
+	old, err := u.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !old.IsActive() {
+		return nil, errors.New("cannot rotate a revoked or expired API key")
+	}
+	if grace < 0 || grace > apiKeyMaxRotationGrace {
+		return nil, fmt.Errorf("invalid grace period, must be between 0 and %v", apiKeyMaxRotationGrace)
+	}
+
+	replacement := &entities.APIKey{
+		Name:               old.Name,
+		PartnerName:        old.PartnerName,
+		Scopes:             old.Scopes,
+		RateLimitPerMinute: old.RateLimitPerMinute,
+		AllowedIPs:         old.AllowedIPs,
+		CreatedBy:          actorID,
+		RotatedFromID:      &old.ID,
+	}
+	issued, err := u.createWithSecret(ctx, replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if grace == 0 {
+		old.RevokedAt = &now
+	} else {
+		expiresAt := now.Add(grace)
+		old.ExpiresAt = &expiresAt
+	}
+	if err := u.apiKeyRepo.Update(ctx, old); err != nil {
+		return nil, fmt.Errorf("failed to retire the old API key: %w", err)
+	}
+
+	log.Printf("[APIKey] Key %s of %s rotated to %s by %v (grace %v)", old.ID, old.PartnerName, issued.Key.ID, actorID, grace)
+	return issued, nil
+}
+
+// RevokeKey permanently disables a partner key
+func (u *APIKeyUsecase) RevokeKey(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) (*entities.APIKey, error) {
+	key, err := u.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := u.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	log.Printf("[APIKey] Key %s of %s revoked by %v", key.ID, key.PartnerName, actorID)
+	return key, nil
+}
+
+// Authenticate resolves the key sent by a partner and checks it may be used from the address
+func (u *APIKeyUsecase) Authenticate(ctx context.Context, rawKey, ip string) (*entities.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, errors.New("invalid API key")
+	}
+	key, err := u.apiKeyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return nil, errors.New("invalid API key")
+	}
+	if !key.IsActive() {
+		return nil, errors.New("API key has been revoked or has expired")
+	}
+	if !key.AllowsIP(ip) {
+		return nil, errors.New("API key is not allowed from this IP address")
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := u.apiKeyRepo.TouchLastUsed(ctx, key.ID, ip, now); err != nil {
+			log.Printf("[APIKey] Failed to record use of key %s: %v", key.ID, err)
+		}
+	}
+	return key, nil
+}
+
+// CheckRateLimit counts a request against the key's per-minute limit
+// Each key may burst up to its full minute's allowance
+func (u *APIKeyUsecase) CheckRateLimit(ctx context.Context, key *entities.APIKey) services.RateLimitResult {
+	policy := services.RateLimitPolicy{
+		Name:      "partner",
+		Burst:     key.RateLimitPerMinute,
+		PerMinute: key.RateLimitPerMinute,
+	}
+	return u.limiter.Allow(ctx, policy, "key:"+key.ID.String())
+}
+
+// createWithSecret generates the key material and stores the key
+func (u *APIKeyUsecase) createWithSecret(ctx context.Context, key *entities.APIKey) (*IssuedAPIKey, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key.KeyPrefix = secret[:apiKeyDisplayLength]
+	key.KeyHash = hashAPIKey(secret)
+	if err := u.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return &IssuedAPIKey{Key: key, Secret: secret}, nil
+}
+
+// normalizeAllowedIPs validates an allowlist and joins it for storage
+func normalizeAllowedIPs(entries []string) (*string, error) {
+	var cleaned []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, fmt.Errorf("invalid IP allowlist entry %q", entry)
+			}
+		} else if net.ParseIP(entry) == nil {
+			return nil, fmt.Errorf("invalid IP allowlist entry %q", entry)
+		}
+		cleaned = append(cleaned, entry)
+	}
+	if len(cleaned) == 0 {
+		return nil, nil
+	}
+	joined := strings.Join(cleaned, ",")
+	return &joined, nil
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+// auditIgnoredFields change on every write and would otherwise show up in every diff
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditLogUsecase records and queries the back-office audit log
+type AuditLogUsecase struct {
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewAuditLogUsecase creates a new audit log usecase
+func NewAuditLogUsecase(auditLogRepo repositories.AuditLogRepository) *AuditLogUsecase {
+	return &AuditLogUsecase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// AuditActor identifies the back-office user performing an action
+type AuditActor struct {
+	UserID *uuid.UUID
+	Email  string
+	Role   string
+}
+
+// AuditEntry describes a single mutating action
+// Before is nil for creations and After is nil for deletions
+type AuditEntry struct {
+	Action     entities.AuditAction
+	EntityType string
+	EntityID   string
+	Before     interface{}
+	After      interface{}
+}
+
+// Record appends an entry to the audit log
+// Failures are logged rather than returned so an audit outage never blocks the action itself
+func (u *AuditLogUsecase) Record(ctx context.Context, actor AuditActor, entry AuditEntry) {
+	before, err := auditSnapshot(entry.Before)
+	if err != nil {
+		log.Printf("[Audit] Failed to snapshot %s %s before %s: %v", entry.EntityType, entry.EntityID, entry.Action, err)
+	}
+	after, err := auditSnapshot(entry.After)
+	if err != nil {
+		log.Printf("[Audit] Failed to snapshot %s %s after %s: %v", entry.EntityType, entry.EntityID, entry.Action, err)
+	}
+
+	client := clientInfoFrom(ctx)
+	record := &entities.AuditLog{
+		ActorID:    actor.UserID,
+		ActorEmail: actor.Email,
+		ActorRole:  actor.Role,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     encodeAuditJSON(before),
+		After:      encodeAuditJSON(after),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	}
+
+	if before != nil && after != nil {
+		record.Changes = encodeAuditJSON(auditDiff(before, after))
+	}
+
+	if err := u.auditLogRepo.Create(ctx, record); err != nil {
+		log.Printf("[Audit] Failed to record %s of %s %s by %v: %v", entry.Action, entry.EntityType, entry.EntityID, actor.UserID, err)
+	}
+}
+
+// ListAuditLogs returns a page of audit log entries, newest first
+func (u *AuditLogUsecase) ListAuditLogs(ctx context.Context, filter repositories.AuditLogFilter, page, pageSize int) ([]*entities.AuditLog, int64, error) {
+	entries, total, err := u.auditLogRepo.List(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	return entries, total, nil
+}
+
+// ExportAuditLogs calls fn for every matching entry, newest first, so exports can be streamed
+func (u *AuditLogUsecase) ExportAuditLogs(ctx context.Context, filter repositories.AuditLogFilter, fn func(*entities.AuditLog) error) error {
+	if err := u.auditLogRepo.Each(ctx, filter, fn); err != nil {
+		return fmt.Errorf("failed to export audit logs: %w", err)
+	}
+	return nil
+}
+
+// auditSnapshot converts an entity to its JSON object form, honouring json tags so secrets stay out
+func auditSnapshot(value interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// auditDiff returns the top-level fields that differ as {"field": {"from": old, "to": new}}
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	changes := make(map[string]interface{})
+	for field, old := range before {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if updated, ok := after[field]; !ok || !reflect.DeepEqual(old, updated) {
+			changes[field] = map[string]interface{}{"from": old, "to": after[field]}
+		}
+	}
+	for field, updated := range after {
+		if _, ok := before[field]; !ok && !auditIgnoredFields[field] {
+			changes[field] = map[string]interface{}{"from": nil, "to": updated}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func encodeAuditJSON(value map[string]interface{}) *string {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,28 +15,111 @@ import (
 
 	"github.com/yourusername/bus-booking-auth/internal/entities"
 	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
 )
 
 type AuthUsecase struct {
 	userRepo           repositories.UserRepository
 	refreshTokenRepo   repositories.RefreshTokenRepository
-	jwtSecret          string
+	sessionRepo        repositories.SessionRepository
+	passwordResetRepo  repositories.PasswordResetTokenRepository
+	mfaRecoveryRepo    repositories.MFARecoveryCodeRepository
+	securityEventRepo  repositories.SecurityEventRepository
+	operatorRepo       repositories.OperatorRepository
+	identityRepo       repositories.UserIdentityRepository
+	tokenKeys          *services.JWTKeySet // Signs and verifies access and refresh tokens
+	sessionStates      *sessionStateCache  // Recent session lookups for access token checks
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+
+	// Email verification
+	emailService      services.EmailProvider
+	frontendURL       string
+	verificationTTL   time.Duration
+	verificationKey   []byte // Separate from tokenKeys so verification tokens are never accepted as access tokens
+	resendMinInterval time.Duration
+
+	// Password reset
+	passwordResetTTL time.Duration
+
+	// Two-factor authentication
+	mfaRequiredForAdmins bool
+	mfaIssuer            string
+	mfaChallengeTTL      time.Duration
+	mfaKey               []byte
+	mfaSecretKeys        *services.MFAKeyring // Encrypts stored TOTP secrets
+	legacyMFASecretKey   []byte               // Reads secrets encrypted before the keyring existed
+	mfaAttempts          *mfaAttemptTracker
+
+	// Brute-force protection
+	loginThrottle *services.LoginThrottle
 }
 
 func NewAuthUsecase(
 	userRepo repositories.UserRepository,
 	refreshTokenRepo repositories.RefreshTokenRepository,
+	sessionRepo repositories.SessionRepository,
+	passwordResetRepo repositories.PasswordResetTokenRepository,
+	mfaRecoveryRepo repositories.MFARecoveryCodeRepository,
+	securityEventRepo repositories.SecurityEventRepository,
+	operatorRepo repositories.OperatorRepository,
+	identityRepo repositories.UserIdentityRepository,
 	jwtSecret string,
+	tokenKeys *services.JWTKeySet,
+	mfaSecretKeys *services.MFAKeyring,
 	accessTokenExpiry, refreshTokenExpiry time.Duration,
+	emailService services.EmailProvider,
+	loginThrottle *services.LoginThrottle,
 ) *AuthUsecase {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:5173"
+	}
+
+	verificationTTL, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL"))
+	if err != nil || verificationTTL <= 0 {
+		verificationTTL = 24 * time.Hour
+	}
+
+	passwordResetTTL, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL"))
+	if err != nil || passwordResetTTL <= 0 {
+		passwordResetTTL = time.Hour
+	}
+
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "Bus Booking"
+	}
+
 	return &AuthUsecase{
 		userRepo:           userRepo,
 		refreshTokenRepo:   refreshTokenRepo,
-		jwtSecret:          jwtSecret,
+		sessionRepo:        sessionRepo,
+		passwordResetRepo:  passwordResetRepo,
+		mfaRecoveryRepo:    mfaRecoveryRepo,
+		securityEventRepo:  securityEventRepo,
+		operatorRepo:       operatorRepo,
+		identityRepo:       identityRepo,
+		tokenKeys:          tokenKeys,
+		sessionStates:      newSessionStateCache(),
 		accessTokenExpiry:  accessTokenExpiry,
 		refreshTokenExpiry: refreshTokenExpiry,
+		emailService:       emailService,
+		frontendURL:        strings.TrimRight(frontendURL, "/"),
+		verificationTTL:    verificationTTL,
+		verificationKey:    derivePurposeKey(jwtSecret, emailVerificationPurpose),
+		resendMinInterval:  time.Minute,
+		passwordResetTTL:   passwordResetTTL,
+
+		mfaRequiredForAdmins: os.Getenv("MFA_REQUIRED_FOR_ADMINS") == "true",
+		mfaIssuer:            mfaIssuer,
+		mfaChallengeTTL:      5 * time.Minute,
+		mfaKey:               derivePurposeKey(jwtSecret, mfaChallengePurpose),
+		mfaSecretKeys:        mfaSecretKeys,
+		legacyMFASecretKey:   derivePurposeKey(jwtSecret, mfaSecretPurpose),
+		mfaAttempts:          newMFAAttemptTracker(),
+
+		loginThrottle: loginThrottle,
 	}
 }
 
@@ -51,16 +136,21 @@ type LoginInput struct {
 }
 
 type OAuthUserInput struct {
-	Email    string
-	Name     string
-	Provider string
-	Avatar   string
+	Email          string
+	Name           string
+	Provider       string
+	ProviderUserID string // The provider's stable account ID
+	Avatar         string
 }
 
 type AuthTokens struct {
 	AccessToken  string
 	RefreshToken string
 	User         *entities.User
+
+	// Set instead of the tokens above when the login needs a second factor
+	MFAToken              string
+	MFAEnrollmentRequired bool
 }
 
 // Register creates a new user account
@@ -94,37 +184,28 @@ func (uc *AuthUsecase) Register(ctx context.Context, input RegisterInput) (*Auth
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate tokens
-	accessToken, err := uc.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := uc.generateRefreshToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store refresh token in database
-	if err := uc.storeRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
-	}
-
-	// Remove password from response
-	user.PasswordHash = ""
+	// Send verification email asynchronously; the user can request another one if it is lost
+	go func(user entities.User) {
+		if err := uc.sendVerificationEmail(context.Background(), &user); err != nil {
+			log.Printf("[Auth] Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}(*user)
 
-	return &AuthTokens{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	// Each login starts a new device session
+	return uc.issueTokens(ctx, user)
 }
 
 // Login authenticates a user
 func (uc *AuthUsecase) Login(ctx context.Context, input LoginInput) (*AuthTokens, error) {
+	// Locked accounts and blocked IPs are rejected before the password is checked
+	if err := uc.checkLoginAllowed(ctx, input.Email); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := uc.userRepo.GetByEmail(ctx, input.Email)
 	if err != nil {
+		uc.recordLoginFailure(ctx, input.Email, nil)
 		return nil, errors.New("invalid email or password")
 	}
 
@@ -134,33 +215,19 @@ func (uc *AuthUsecase) Login(ctx context.Context, input LoginInput) (*AuthTokens
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		uc.recordLoginFailure(ctx, input.Email, user)
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate tokens
-	accessToken, err := uc.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := uc.generateRefreshToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store refresh token in database
-	if err := uc.storeRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	// Accounts with two-factor authentication finish signing in with VerifyMFA
+	// Failure history is kept until the second factor passes so code guesses count against the account
+	if challenge, err := uc.mfaChallenge(user); err != nil || challenge != nil {
+		return challenge, err
 	}
+	uc.recordLoginSuccess(ctx, input.Email)
 
-	// Remove password from response
-	user.PasswordHash = ""
-
-	return &AuthTokens{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	// Each login starts a new device session
+	return uc.issueTokens(ctx, user)
 }
 
 // Logout revokes all refresh tokens for the user
@@ -168,105 +235,49 @@ func (uc *AuthUsecase) Logout(ctx context.Context, userID uuid.UUID) error {
 	return uc.refreshTokenRepo.RevokeAllForUser(ctx, userID)
 }
 
-// LogoutAll revokes a specific refresh token
+// RevokeToken signs out the session a refresh token belongs to
 func (uc *AuthUsecase) RevokeToken(ctx context.Context, refreshToken string) error {
+	storedToken, err := uc.refreshTokenRepo.FindByToken(ctx, refreshToken)
+	if err == nil && storedToken.SessionID != nil {
+		uc.sessionStates.set(*storedToken.SessionID, false)
+		return uc.sessionRepo.Revoke(ctx, *storedToken.SessionID, entities.SessionRevokedLogout)
+	}
 	return uc.refreshTokenRepo.Revoke(ctx, refreshToken)
 }
 
-// RefreshAccessToken generates a new access token from refresh token
+// RefreshAccessToken rotates the refresh token and issues a new access token
+// Presenting a token that was already rotated means it was copied, so its whole session is revoked
 func (uc *AuthUsecase) RefreshAccessToken(ctx context.Context, refreshToken string) (*AuthTokens, error) {
-	// Validate refresh token in database
-	storedToken, err := uc.refreshTokenRepo.GetByToken(ctx, refreshToken)
-	if err != nil {
-		return nil, errors.New("invalid or expired refresh token")
-	}
-
-	if !storedToken.IsValid() {
-		return nil, errors.New("refresh token is revoked or expired")
-	}
-
 	// Parse and validate refresh token JWT
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(uc.jwtSecret), nil
-	})
-
+	token, err := uc.tokenKeys.Parse(refreshToken)
 	if err != nil || !token.Valid {
 		return nil, errors.New("invalid refresh token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
-	}
-
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return nil, errors.New("invalid user ID in token")
-	}
-
-	userID, err := uuid.Parse(userIDStr)
+	// Look up the token in any state so reuse of a rotated token can be detected
+	storedToken, err := uc.refreshTokenRepo.FindByToken(ctx, refreshToken)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, errors.New("invalid or expired refresh token")
 	}
 
-	// Get user
-	user, err := uc.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return nil, errors.New("user not found")
+	if storedToken.RotatedAt != nil {
+		return uc.handleRotatedRefreshToken(ctx, storedToken)
 	}
 
-	if !user.IsActive {
-		return nil, errors.New("account is inactive")
+	if !storedToken.IsValid() {
+		return nil, errors.New("refresh token is revoked or expired")
 	}
 
-	// Generate new access token (always)
-	newAccessToken, err := uc.generateAccessToken(user)
+	user, err := uc.getSessionUser(ctx, storedToken.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Only generate new refresh token if current one is expiring soon (< 1 minute)
-	// This prevents duplicate token errors when multiple refresh calls happen at once
-	timeUntilExpiry := storedToken.ExpiresAt.Sub(time.Now())
-	shouldRotateRefreshToken := timeUntilExpiry < 1*time.Minute
-
-	log.Printf("[Auth] RefreshAccessToken for user %s, refresh token expires in %v, should rotate: %v",
-		userID, timeUntilExpiry, shouldRotateRefreshToken)
-
-	var newRefreshToken string
-	if shouldRotateRefreshToken {
-		// Token is expiring soon, generate a new one
-		newRefreshToken, err = uc.generateRefreshToken(user)
-		if err != nil {
-			return nil, err
-		}
-
-		// Store new refresh token in database
-		if err := uc.storeRefreshToken(ctx, user.ID, newRefreshToken); err != nil {
-			return nil, fmt.Errorf("failed to store new refresh token: %w", err)
-		}
-	} else {
-		// Token still has plenty of time, reuse the existing one
-		// But don't include it in the response - client will keep using the cookie
-		log.Printf("[Auth] Reusing existing refresh token (expires in %v)", timeUntilExpiry)
-		newRefreshToken = refreshToken
-	}
-
-	// Remove password from response
-	user.PasswordHash = ""
-
-	return &AuthTokens{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		User:         user,
-	}, nil
+	return uc.rotateRefreshToken(ctx, user, storedToken)
 }
 
 // generateAccessToken creates a JWT access token
-func (uc *AuthUsecase) generateAccessToken(user *entities.User) (string, error) {
+func (uc *AuthUsecase) generateAccessToken(user *entities.User, sessionID uuid.UUID) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"email":   user.Email,
@@ -274,39 +285,28 @@ func (uc *AuthUsecase) generateAccessToken(user *entities.User) (string, error)
 		"exp":     time.Now().Add(uc.accessTokenExpiry).Unix(),
 		"iat":     time.Now().Unix(),
 	}
+	if sessionID != uuid.Nil {
+		claims["sid"] = sessionID.String()
+	}
+	// Operator staff only ever see their own company's data
+	if user.OperatorID != nil && user.Role.IsStaff() {
+		claims["operator_id"] = user.OperatorID.String()
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(uc.jwtSecret))
+	return uc.tokenKeys.Sign(claims)
 }
 
 // generateRefreshToken creates a JWT refresh token
 func (uc *AuthUsecase) generateRefreshToken(user *entities.User) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
+		"jti":     uuid.New().String(), // Tokens issued in the same second must still differ
+		"typ":     "refresh",           // AuthMiddleware rejects refresh tokens used as access tokens
 		"exp":     time.Now().Add(uc.refreshTokenExpiry).Unix(),
 		"iat":     time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(uc.jwtSecret))
-}
-
-// storeRefreshToken saves a refresh token to the database
-func (uc *AuthUsecase) storeRefreshToken(ctx context.Context, userID uuid.UUID, tokenString string) error {
-	expiresAt := time.Now().Add(uc.refreshTokenExpiry)
-	log.Printf("[Auth] Storing refresh token for user %s, expires_at: %v (now: %v, expiry duration: %v)",
-		userID, expiresAt, time.Now(), uc.refreshTokenExpiry)
-
-	refreshToken := &entities.RefreshToken{
-		ID:        uuid.New(),
-		UserID:    userID,
-		Token:     tokenString,
-		ExpiresAt: expiresAt,
-		IsRevoked: false,
-		CreatedAt: time.Now(),
-	}
-
-	return uc.refreshTokenRepo.Create(ctx, refreshToken)
+	return uc.tokenKeys.Sign(claims)
 }
 
 // GetUserByEmail gets user by email
@@ -334,35 +334,22 @@ func (uc *AuthUsecase) RegisterOAuth(ctx context.Context, input OAuthUserInput)
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
+	// The OAuth provider has already verified the address
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
 
 	if err := uc.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-
-	// Generate tokens
-	accessToken, err := uc.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := uc.generateRefreshToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store refresh token in database
-	if err := uc.storeRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	if input.ProviderUserID != "" {
+		if _, err := uc.linkIdentity(ctx, user, input); err != nil {
+			log.Printf("[Auth] Failed to link %s identity to new user %s: %v", input.Provider, user.ID, err)
+		}
 	}
 
-	// Remove password from response
-	user.PasswordHash = ""
-
-	return &AuthTokens{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	// Each login starts a new device session
+	return uc.issueTokens(ctx, user)
 }
 
 // LoginOAuth logs in an existing OAuth user
@@ -377,38 +364,31 @@ func (uc *AuthUsecase) LoginOAuth(ctx context.Context, userID uuid.UUID) (*AuthT
 		return nil, errors.New("account is inactive")
 	}
 
-	// Generate tokens
-	accessToken, err := uc.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := uc.generateRefreshToken(user)
-	if err != nil {
-		return nil, err
+	// Signing in through the OAuth provider proves ownership of the address
+	if !user.EmailVerified {
+		if err := uc.markEmailVerified(ctx, user); err != nil {
+			log.Printf("[Auth] Failed to mark email verified for user %s: %v", user.ID, err)
+		}
 	}
 
-	// Store refresh token in database
-	if err := uc.storeRefreshToken(ctx, user.ID, refreshToken); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	if challenge, err := uc.mfaChallenge(user); err != nil || challenge != nil {
+		return challenge, err
 	}
 
-	// Remove password from response
-	user.PasswordHash = ""
-
-	return &AuthTokens{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	// Each login starts a new device session
+	return uc.issueTokens(ctx, user)
 }
 
-// CreateAdminInput holds data for creating an admin user
+// CreateAdminInput holds data for creating an admin or staff user
 type CreateAdminInput struct {
 	Name     string
 	Email    string
 	Password string
 	Phone    string
+	Role     entities.Role // Defaults to admin
+
+	// Operator the account works for, nil for platform staff
+	OperatorID *uuid.UUID
 }
 
 // UpdateUserInput holds data for updating a user
@@ -458,8 +438,27 @@ func (uc *AuthUsecase) GetUsersByRole(ctx context.Context, role entities.Role) (
 	return users, nil
 }
 
-// CreateAdminUser creates a new admin user
+// CreateAdminUser creates a new admin or back-office staff user
 func (uc *AuthUsecase) CreateAdminUser(ctx context.Context, input CreateAdminInput) (*entities.User, error) {
+	role := input.Role
+	if role == "" {
+		role = entities.RoleAdmin
+	}
+	if !role.IsStaff() {
+		return nil, fmt.Errorf("invalid staff role %q", role)
+	}
+
+	// Operator admins can only add staff to their own company
+	operatorID := input.OperatorID
+	if scoped, ok := repositories.OperatorScope(ctx); ok {
+		operatorID = &scoped
+	}
+	if operatorID != nil {
+		if _, err := uc.operatorRepo.GetByID(ctx, *operatorID); err != nil {
+			return nil, errors.New("operator not found")
+		}
+	}
+
 	// Check if email already exists
 	existing, _ := uc.userRepo.GetByEmail(ctx, input.Email)
 	if existing != nil {
@@ -479,7 +478,8 @@ func (uc *AuthUsecase) CreateAdminUser(ctx context.Context, input CreateAdminInp
 		Email:        input.Email,
 		PasswordHash: string(hashedPassword),
 		Phone:        input.Phone,
-		Role:         entities.RoleAdmin,
+		Role:         role,
+		OperatorID:   operatorID,
 		IsActive:     true,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -496,9 +496,9 @@ func (uc *AuthUsecase) CreateAdminUser(ctx context.Context, input CreateAdminInp
 
 // GetUserByID returns a user by ID
 func (uc *AuthUsecase) GetUserByID(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
-	user, err := uc.userRepo.GetByID(ctx, userID)
+	user, err := uc.getManagedUser(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, err
 	}
 	user.PasswordHash = ""
 	return user, nil
@@ -506,9 +506,9 @@ func (uc *AuthUsecase) GetUserByID(ctx context.Context, userID uuid.UUID) (*enti
 
 // UpdateUser updates user information
 func (uc *AuthUsecase) UpdateUser(ctx context.Context, userID uuid.UUID, input UpdateUserInput) (*entities.User, error) {
-	user, err := uc.userRepo.GetByID(ctx, userID)
+	user, err := uc.getManagedUser(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, err
 	}
 
 	// Update fields
@@ -536,6 +536,9 @@ func (uc *AuthUsecase) UpdateUser(ctx context.Context, userID uuid.UUID, input U
 
 // DeactivateUser deactivates a user account
 func (uc *AuthUsecase) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := uc.getManagedUser(ctx, userID); err != nil {
+		return err
+	}
 	if err := uc.userRepo.SetActive(ctx, userID, false); err != nil {
 		return fmt.Errorf("failed to deactivate user: %w", err)
 	}
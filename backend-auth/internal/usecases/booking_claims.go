@@ -0,0 +1,206 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const (
+	bookingCodeLength      = 6
+	bookingCodeTTL         = 15 * time.Minute
+	bookingCodeMaxAttempts = 5
+	bookingCodeResendAfter = time.Minute
+)
+
+// BookingClaimChallenge describes a claim code that has been sent
+type BookingClaimChallenge struct {
+	Destination  string    `json:"destination"` // Masked address the code was sent to
+	BookingCount int       `json:"booking_count"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// GetClaimableBookings lists guest bookings made with the user's email that can be added to the account
+// Only verified addresses qualify, otherwise anyone could register with someone else's email to see their trips
+func (uc *BookingUsecase) GetClaimableBookings(ctx context.Context, userID uuid.UUID) ([]*entities.Booking, error) {
+	user, err := uc.verifiedClaimant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.bookingRepo.GetClaimableByEmail(ctx, user.Email)
+}
+
+// RequestBookingClaim emails a one-time code to the contact address of the guest bookings being claimed
+// With no booking IDs every claimable booking is included
+func (uc *BookingUsecase) RequestBookingClaim(ctx context.Context, userID uuid.UUID, bookingIDs []uuid.UUID) (*BookingClaimChallenge, error) {
+	if uc.emailService == nil {
+		return nil, errors.New("email service is not configured")
+	}
+
+	user, err := uc.verifiedClaimant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest, err := uc.accessCodeRepo.GetLatestForUser(ctx, userID, entities.BookingAccessClaim); err == nil &&
+		latest.UsedAt == nil && time.Since(latest.CreatedAt) < bookingCodeResendAfter {
+		return nil, errors.New("a code was sent recently, please wait before requesting another")
+	}
+
+	claimable, err := uc.bookingRepo.GetClaimableByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guest bookings: %w", err)
+	}
+	selected, err := selectClaimableBookings(claimable, bookingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := generateBookingCode()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(selected))
+	for i, booking := range selected {
+		ids[i] = booking.ID.String()
+	}
+	destination := selected[0].ContactEmail
+	accessCode := &entities.BookingAccessCode{
+		Purpose:     entities.BookingAccessClaim,
+		UserID:      &userID,
+		BookingIDs:  strings.Join(ids, ","),
+		Destination: destination,
+		CodeHash:    hashRecoveryCode(code),
+		ExpiresAt:   time.Now().Add(bookingCodeTTL),
+	}
+	if err := uc.accessCodeRepo.Create(ctx, accessCode); err != nil {
+		return nil, fmt.Errorf("failed to store claim code: %w", err)
+	}
+
+	body := services.NewEmailTemplates().BookingClaimCodeEmail(user.Name, code, len(selected), bookingCodeTTL)
+	go func() {
+		if err := uc.emailService.SendHTMLEmail(destination, user.Name, "Your booking claim code", body); err != nil {
+			log.Printf("[Booking] Failed to send claim code to user %s: %v", userID, err)
+		}
+	}()
+
+	return &BookingClaimChallenge{
+		Destination:  maskEmail(destination),
+		BookingCount: len(selected),
+		ExpiresAt:    accessCode.ExpiresAt,
+	}, nil
+}
+
+// ConfirmBookingClaim checks the emailed code and attaches the guest bookings to the user
+func (uc *BookingUsecase) ConfirmBookingClaim(ctx context.Context, userID uuid.UUID, code string) ([]*entities.Booking, error) {
+	user, err := uc.verifiedClaimant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessCode, err := uc.accessCodeRepo.GetLatestForUser(ctx, userID, entities.BookingAccessClaim)
+	if err != nil || !accessCode.IsUsable(bookingCodeMaxAttempts) {
+		return nil, errors.New("invalid or expired code, please request a new one")
+	}
+	if hashRecoveryCode(strings.TrimSpace(code)) != accessCode.CodeHash {
+		accessCode.Attempts++
+		if err := uc.accessCodeRepo.Update(ctx, accessCode); err != nil {
+			log.Printf("[Booking] Failed to record claim attempt for user %s: %v", userID, err)
+		}
+		return nil, errors.New("invalid or expired code, please request a new one")
+	}
+
+	used, err := uc.accessCodeRepo.MarkUsed(ctx, accessCode.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem code: %w", err)
+	}
+	if !used {
+		return nil, errors.New("invalid or expired code, please request a new one")
+	}
+
+	bookingIDs := accessCode.BookingIDList()
+	claimed, err := uc.bookingRepo.ClaimForUser(ctx, userID, user.Email, bookingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim bookings: %w", err)
+	}
+	log.Printf("[Booking] User %s claimed %d guest booking(s)", userID, claimed)
+
+	bookings := make([]*entities.Booking, 0, len(bookingIDs))
+	for _, id := range bookingIDs {
+		booking, err := uc.bookingRepo.GetByID(ctx, id)
+		if err == nil && booking.UserID != nil && *booking.UserID == userID {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+// verifiedClaimant returns the user if their email address is verified
+func (uc *BookingUsecase) verifiedClaimant(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.EmailVerified {
+		return nil, errors.New("email address is not verified, please verify your email first")
+	}
+	return user, nil
+}
+
+// selectClaimableBookings picks the requested bookings from the claimable ones, or all of them
+func selectClaimableBookings(claimable []*entities.Booking, bookingIDs []uuid.UUID) ([]*entities.Booking, error) {
+	if len(claimable) == 0 {
+		return nil, errors.New("no guest bookings found for your email address")
+	}
+	if len(bookingIDs) == 0 {
+		return claimable, nil
+	}
+
+	byID := make(map[uuid.UUID]*entities.Booking, len(claimable))
+	for _, booking := range claimable {
+		byID[booking.ID] = booking
+	}
+	selected := make([]*entities.Booking, 0, len(bookingIDs))
+	for _, id := range bookingIDs {
+		booking, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("booking %s not found among your guest bookings", id)
+		}
+		selected = append(selected, booking)
+	}
+	return selected, nil
+}
+
+// generateBookingCode returns a random numeric one-time code
+func generateBookingCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(bookingCodeLength), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", bookingCodeLength, n), nil
+}
+
+// maskEmail hides most of the local part, e.g. "jo***@example.com"
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	visible := 2
+	if at < visible {
+		visible = at
+	}
+	return email[:visible] + "***" + email[at:]
+}
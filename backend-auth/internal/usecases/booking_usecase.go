@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,8 +21,14 @@ type BookingUsecase struct {
 	tripRepo         repositories.TripRepository
 	seatMapRepo      repositories.SeatMapRepository
 	notificationRepo repositories.NotificationRepository
+	userRepo         repositories.UserRepository
+	accessCodeRepo   repositories.BookingAccessCodeRepository
 	ticketService    *services.TicketService
-	emailService     *services.EmailService
+	emailService     services.EmailProvider // Sends guest access and claim codes
+	templateEngine   *services.NotificationTemplateEngine
+	verification     *EmailVerificationPolicy
+	guestTokenKey    []byte // Signs booking-scoped guest access tokens
+	funnel           *FunnelTracker
 }
 
 func NewBookingUsecase(
@@ -32,6 +39,13 @@ func NewBookingUsecase(
 	tripRepo repositories.TripRepository,
 	seatMapRepo repositories.SeatMapRepository,
 	notificationRepo repositories.NotificationRepository,
+	userRepo repositories.UserRepository,
+	accessCodeRepo repositories.BookingAccessCodeRepository,
+	emailService services.EmailProvider,
+	templateEngine *services.NotificationTemplateEngine,
+	verification *EmailVerificationPolicy,
+	jwtSecret string,
+	funnel *FunnelTracker,
 ) *BookingUsecase {
 	return &BookingUsecase{
 		bookingRepo:      bookingRepo,
@@ -41,8 +55,14 @@ func NewBookingUsecase(
 		tripRepo:         tripRepo,
 		seatMapRepo:      seatMapRepo,
 		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		accessCodeRepo:   accessCodeRepo,
 		ticketService:    services.NewTicketService(),
-		emailService:     services.NewEmailService(),
+		emailService:     emailService,
+		templateEngine:   templateEngine,
+		verification:     verification,
+		guestTokenKey:    derivePurposeKey(jwtSecret, guestBookingPurpose),
+		funnel:           funnel,
 	}
 }
 
@@ -53,7 +73,12 @@ type CreateBookingInput struct {
 	ContactPhone string           `json:"contact_phone"`
 	ContactName  string           `json:"contact_name"`
 	Passengers   []PassengerInput `json:"passengers"`
-	SessionID    string           `json:"session_id"` // For seat reservation
+	SessionID    string           `json:"session_id"`       // For seat reservation
+	Locale       string           `json:"locale,omitempty"` // Language for emails; defaults to the request's Accept-Language
+
+	// Partner API key the booking is made through; set by the partner API, never from the request body
+	APIKeyID    *uuid.UUID `json:"-"`
+	PartnerName *string    `json:"-"`
 }
 
 type PassengerInput struct {
@@ -77,6 +102,7 @@ type BookingResponse struct {
 	Booking    *entities.Booking     `json:"booking"`
 	Passengers []*entities.Passenger `json:"passengers"`
 	Tickets    []*entities.Ticket    `json:"tickets"`
+	Access     *BookingAccessToken   `json:"access,omitempty"` // Set when the booking is created
 }
 
 // ReserveSeats temporarily locks seats for checkout
@@ -104,6 +130,7 @@ func (uc *BookingUsecase) ReserveSeats(ctx context.Context, input ReserveSeatInp
 		}
 	}
 
+	uc.funnel.TrackSeatsReserved(ctx, input.SessionID, input.TripID, len(input.SeatIDs))
 	return nil
 }
 
@@ -119,6 +146,10 @@ func (uc *BookingUsecase) CreateBooking(ctx context.Context, input CreateBooking
 		return nil, errors.New("at least one passenger is required")
 	}
 
+	if err := uc.verification.CheckBooking(ctx, input.UserID); err != nil {
+		return nil, err
+	}
+
 	// Get trip details
 	trip, err := uc.tripRepo.GetByID(ctx, input.TripID)
 	if err != nil {
@@ -183,6 +214,10 @@ func (uc *BookingUsecase) CreateBooking(ctx context.Context, input CreateBooking
 		PaymentStatus:    entities.PaymentStatusPending,
 		IsGuestBooking:   input.UserID == nil,
 		ExpiresAt:        &expiresAt,
+		OperatorID:       trip.OperatorID,
+		APIKeyID:         input.APIKeyID,
+		PartnerName:      input.PartnerName,
+		Locale:           services.NormalizeLocale(input.Locale),
 	}
 
 	if err := uc.bookingRepo.Create(ctx, booking); err != nil {
@@ -248,10 +283,19 @@ func (uc *BookingUsecase) CreateBooking(ctx context.Context, input CreateBooking
 		_ = uc.reservationRepo.DeleteBySessionID(ctx, input.SessionID)
 	}
 
+	// Lets the person who just booked open it again without an account (e.g. after payment)
+	access, err := uc.IssueBookingAccessToken(booking.ID)
+	if err != nil {
+		log.Printf("[Booking] Failed to issue access token for booking %s: %v", booking.ID, err)
+	}
+
+	uc.funnel.TrackBooking(ctx, entities.FunnelBookingCreated, booking, input.SessionID)
+
 	return &BookingResponse{
 		Booking:    booking,
 		Passengers: passengers,
 		Tickets:    tickets,
+		Access:     access,
 	}, nil
 }
 
@@ -273,32 +317,54 @@ func (uc *BookingUsecase) ConfirmBooking(ctx context.Context, bookingID uuid.UUI
 	booking.PaymentReference = &paymentReference
 	booking.ConfirmedAt = &now
 
-	if err := uc.bookingRepo.Update(ctx, booking); err != nil {
-		return err
+	// Ticket emails, the in-app notification and analytics are recorded in the outbox in the
+	// same transaction as the status change and delivered by the outbox relay
+	payload := entities.OutboxPayload{BookingID: booking.ID, TripID: &booking.TripID}
+	specs := []struct{ eventType, dedupKey string }{
+		{entities.OutboxEventTicketEmail, fmt.Sprintf("booking:%s:confirmed:ticket_email", booking.ID)},
+		{entities.OutboxEventBookingInApp, fmt.Sprintf("booking:%s:confirmed:in_app", booking.ID)},
+		{entities.OutboxEventAnalyticsRefresh, fmt.Sprintf("booking:%s:confirmed:analytics", booking.ID)},
+	}
+	events := make([]*entities.OutboxEvent, 0, len(specs))
+	for _, spec := range specs {
+		event, err := entities.NewOutboxEvent(spec.eventType, "booking", booking.ID, spec.dedupKey, payload)
+		if err != nil {
+			return fmt.Errorf("failed to build outbox event: %w", err)
+		}
+		events = append(events, event)
 	}
 
-	// Send confirmation email with tickets
-	go func() {
-		// Use background context for async email sending
-		bgCtx := context.Background()
-		if err := uc.sendTicketEmails(bgCtx, bookingID); err != nil {
-			fmt.Printf("Failed to send ticket emails for booking %s: %v\n", bookingID, err)
-		}
+	return uc.bookingRepo.UpdateWithOutbox(ctx, booking, events)
+}
+
+// CreateConfirmationNotification creates the in-app booking confirmation notification
+// Safe to call more than once: nothing is created if the booking already has one
+func (uc *BookingUsecase) CreateConfirmationNotification(ctx context.Context, bookingID uuid.UUID) error {
+	booking, err := uc.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
 
-		// Create in-app notification for booking confirmation
-		inAppNotification := &entities.Notification{
-			UserID:    booking.UserID,
-			BookingID: &booking.ID,
-			Type:      entities.NotificationTypeBookingConfirmation,
-			Channel:   entities.NotificationChannelInApp,
-			Status:    entities.NotificationStatusSent,
-			Subject:   "Booking Confirmed",
-			Body:      fmt.Sprintf("Your booking %s has been confirmed", booking.BookingReference),
+	existing, err := uc.notificationRepo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking notifications: %w", err)
+	}
+	for _, n := range existing {
+		if n.Type == entities.NotificationTypeBookingConfirmation && n.Channel == entities.NotificationChannelInApp {
+			return nil
 		}
-		uc.notificationRepo.Create(bgCtx, inAppNotification)
-	}()
+	}
 
-	return nil
+	inAppNotification := &entities.Notification{
+		UserID:    booking.UserID,
+		BookingID: &booking.ID,
+		Type:      entities.NotificationTypeBookingConfirmation,
+		Channel:   entities.NotificationChannelInApp,
+		Status:    entities.NotificationStatusSent,
+		Subject:   "Booking Confirmed",
+		Body:      fmt.Sprintf("Your booking %s has been confirmed", booking.BookingReference),
+	}
+	return uc.notificationRepo.Create(ctx, inAppNotification)
 }
 
 // CancelBooking cancels a booking
@@ -351,11 +417,6 @@ func (uc *BookingUsecase) GetUserBookings(ctx context.Context, userID uuid.UUID,
 	return uc.bookingRepo.GetByUserID(ctx, userID, page, pageSize)
 }
 
-// GetGuestBookings retrieves guest bookings by contact info
-func (uc *BookingUsecase) GetGuestBookings(ctx context.Context, email, phone string) ([]*entities.Booking, error) {
-	return uc.bookingRepo.GetByGuestContact(ctx, email, phone)
-}
-
 // GetAvailableSeats gets available seats for a trip
 func (uc *BookingUsecase) GetAvailableSeats(ctx context.Context, tripID uuid.UUID) ([]*entities.Seat, error) {
 	// Get trip
@@ -557,13 +618,11 @@ func (uc *BookingUsecase) sendTicketEmails(ctx context.Context, bookingID uuid.U
 		}
 
 		// Send email with PDF attachment
-		if err := uc.emailService.SendTicketEmail(
-			booking.ContactEmail,
-			booking.ContactName,
-			booking.BookingReference,
-			ticket.TicketNumber,
-			pdfBytes,
-		); err != nil {
+		if err := uc.templateEngine.SendTicketEmail(booking.Locale, booking.ContactEmail, services.TicketEmailData{
+			RecipientName:    booking.ContactName,
+			BookingReference: booking.BookingReference,
+			TicketNumber:     ticket.TicketNumber,
+		}, pdfBytes); err != nil {
 			fmt.Printf("Failed to send email for ticket %s: %v\n", ticket.TicketNumber, err)
 		}
 	}
@@ -1036,6 +1095,11 @@ type TripPassengerInfo struct {
 
 // GetTripPassengers retrieves all passengers for a specific trip with their check-in status
 func (uc *BookingUsecase) GetTripPassengers(ctx context.Context, tripID uuid.UUID) ([]*TripPassengerInfo, error) {
+	// Operator staff may only see manifests of their own trips
+	if _, err := uc.tripRepo.GetByID(ctx, tripID); err != nil {
+		return nil, errors.New("trip not found")
+	}
+
 	// Get all confirmed bookings for this trip
 	bookings, err := uc.bookingRepo.GetByTripID(ctx, tripID)
 	if err != nil {
@@ -1101,41 +1165,17 @@ func (uc *BookingUsecase) GetTripPassengers(ctx context.Context, tripID uuid.UUI
 
 // CheckInPassenger marks a passenger as checked in by marking their ticket as used
 func (uc *BookingUsecase) CheckInPassenger(ctx context.Context, tripID, passengerID uuid.UUID) error {
-	// Get the ticket for this passenger and trip
-	tickets, err := uc.ticketRepo.GetByBookingID(ctx, uuid.Nil)
-	if err != nil {
-		// Need to find ticket by passenger ID directly
-		// Get all bookings for the trip first
-		bookings, err := uc.bookingRepo.GetByTripID(ctx, tripID)
-		if err != nil {
-			return fmt.Errorf("failed to get bookings: %w", err)
-		}
+	if _, err := uc.tripRepo.GetByID(ctx, tripID); err != nil {
+		return errors.New("trip not found")
+	}
 
-		for _, booking := range bookings {
-			tickets, err := uc.ticketRepo.GetByBookingID(ctx, booking.ID)
-			if err != nil {
-				continue
-			}
-			for _, ticket := range tickets {
-				if ticket.PassengerID == passengerID {
-					if ticket.IsUsed {
-						return errors.New("passenger already checked in")
-					}
-					return uc.ticketRepo.MarkAsUsed(ctx, ticket.TicketNumber)
-				}
-			}
-		}
+	ticket, err := uc.ticketRepo.GetByTripAndPassenger(ctx, tripID, passengerID)
+	if err != nil {
 		return errors.New("ticket not found for passenger")
 	}
-
-	for _, ticket := range tickets {
-		if ticket.PassengerID == passengerID && ticket.TripID == tripID {
-			if ticket.IsUsed {
-				return errors.New("passenger already checked in")
-			}
-			return uc.ticketRepo.MarkAsUsed(ctx, ticket.TicketNumber)
-		}
+	if ticket.IsUsed {
+		return errors.New("passenger already checked in")
 	}
 
-	return errors.New("ticket not found for passenger")
+	return uc.ticketRepo.MarkAsUsed(ctx, ticket.TicketNumber)
 }
@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const emailVerificationPurpose = "email_verification"
+
+// derivePurposeKey derives a signing key for single-purpose tokens from the JWT secret
+// Tokens signed with it fail AuthMiddleware, which only accepts the session signing keys
+func derivePurposeKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// SendVerificationEmail sends a fresh verification link to the user
+func (uc *AuthUsecase) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerified {
+		return errors.New("email is already verified")
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < uc.resendMinInterval {
+		return errors.New("verification email was sent recently, please wait before requesting another")
+	}
+	return uc.sendVerificationEmail(ctx, user)
+}
+
+// ResendVerificationEmail sends a verification link to an address if it belongs to an unverified account
+// Unknown, verified or throttled addresses are silently ignored so the endpoint cannot be used to probe accounts
+func (uc *AuthUsecase) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil || user.EmailVerified {
+		return nil
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < uc.resendMinInterval {
+		return nil
+	}
+	return uc.sendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail validates a verification token and marks the account's email as verified
+// Verifying an already verified account succeeds, so a second click on the link is harmless
+func (uc *AuthUsecase) VerifyEmail(ctx context.Context, tokenString string) (*entities.User, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return uc.verificationKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != emailVerificationPurpose {
+		return nil, errors.New("invalid verification token")
+	}
+
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, errors.New("invalid verification token")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// A token issued before an email change must not verify the new address
+	if email, _ := claims["email"].(string); !strings.EqualFold(email, user.Email) {
+		return nil, errors.New("verification token does not match the account email")
+	}
+
+	if !user.EmailVerified {
+		if err := uc.markEmailVerified(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to verify email: %w", err)
+		}
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// sendVerificationEmail issues a verification token and emails the link
+func (uc *AuthUsecase) sendVerificationEmail(ctx context.Context, user *entities.User) error {
+	if uc.emailService == nil {
+		return errors.New("email service is not configured")
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"purpose": emailVerificationPurpose,
+		"user_id": user.ID.String(),
+		"email":   user.Email,
+		"exp":     now.Add(uc.verificationTTL).Unix(),
+		"iat":     now.Unix(),
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uc.verificationKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign verification token: %w", err)
+	}
+
+	verifyURL := fmt.Sprintf("%s/verify-email?token=%s", uc.frontendURL, url.QueryEscape(tokenString))
+	body := services.NewEmailTemplates().EmailVerificationEmail(user.Name, verifyURL, uc.verificationTTL)
+	if err := uc.emailService.SendHTMLEmail(user.Email, user.Name, "Verify your email address", body); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	user.VerificationSentAt = &now
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		log.Printf("[Auth] Failed to record verification email for user %s: %v", user.ID, err)
+	}
+
+	log.Printf("[Auth] Verification email sent to user %s", user.ID)
+	return nil
+}
+
+// markEmailVerified records that the user owns their email address
+func (uc *AuthUsecase) markEmailVerified(ctx context.Context, user *entities.User) error {
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	return uc.userRepo.Update(ctx, user)
+}
+
+// EmailVerificationPolicy decides which actions require a verified email address
+// Guest checkouts (no user account) are not affected
+type EmailVerificationPolicy struct {
+	userRepo          repositories.UserRepository
+	requireForBooking bool
+	requireForPayment bool
+}
+
+// NewEmailVerificationPolicy creates a policy from a comma-separated list of actions ("booking", "payment")
+func NewEmailVerificationPolicy(userRepo repositories.UserRepository, requiredFor string) *EmailVerificationPolicy {
+	policy := &EmailVerificationPolicy{userRepo: userRepo}
+	for _, action := range strings.Split(requiredFor, ",") {
+		switch strings.TrimSpace(strings.ToLower(action)) {
+		case "booking":
+			policy.requireForBooking = true
+		case "payment":
+			policy.requireForPayment = true
+		}
+	}
+	return policy
+}
+
+// CheckBooking returns an error if the account may not create bookings yet
+func (p *EmailVerificationPolicy) CheckBooking(ctx context.Context, userID *uuid.UUID) error {
+	if p == nil || !p.requireForBooking {
+		return nil
+	}
+	return p.check(ctx, userID)
+}
+
+// CheckPayment returns an error if the account may not pay for bookings yet
+func (p *EmailVerificationPolicy) CheckPayment(ctx context.Context, userID *uuid.UUID) error {
+	if p == nil || !p.requireForPayment {
+		return nil
+	}
+	return p.check(ctx, userID)
+}
+
+func (p *EmailVerificationPolicy) check(ctx context.Context, userID *uuid.UUID) error {
+	if userID == nil {
+		return nil
+	}
+	user, err := p.userRepo.GetByID(ctx, *userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.EmailVerified {
+		return errors.New("email address is not verified, please verify your email first")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+// Funnel events are kept for FUNNEL_EVENT_RETENTION_DAYS, 180 by default
+const defaultFunnelRetentionDays = 180
+
+type funnelSessionKey struct{}
+
+// WithFunnelSession attributes funnel events recorded with the context to a visitor session
+func WithFunnelSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, funnelSessionKey{}, sessionID)
+}
+
+// FunnelSession returns the visitor session the context belongs to, or "" when it is untracked
+func FunnelSession(ctx context.Context) string {
+	sessionID, _ := ctx.Value(funnelSessionKey{}).(string)
+	return sessionID
+}
+
+// FunnelTracker records the search-to-booking funnel
+// Events are written in the background so tracking never slows down or fails the request it observes
+type FunnelTracker struct {
+	funnelRepo repositories.FunnelEventRepository
+	tripRepo   repositories.TripRepository
+	retention  time.Duration
+}
+
+// NewFunnelTracker creates a new funnel tracker
+func NewFunnelTracker(funnelRepo repositories.FunnelEventRepository, tripRepo repositories.TripRepository) *FunnelTracker {
+	days := defaultFunnelRetentionDays
+	if v, err := strconv.Atoi(os.Getenv("FUNNEL_EVENT_RETENTION_DAYS")); err == nil && v > 0 {
+		days = v
+	}
+	return &FunnelTracker{
+		funnelRepo: funnelRepo,
+		tripRepo:   tripRepo,
+		retention:  time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+// TrackSearch records a search with what was asked for and how many trips it found
+// Only the first page counts, so paging through results is not mistaken for new searches
+func (t *FunnelTracker) TrackSearch(ctx context.Context, opts repositories.TripSearchOptions, resultCount int64) {
+	if t == nil || opts.Page > 1 {
+		return
+	}
+	count := int(resultCount)
+	event := &entities.FunnelEvent{
+		EventType:   entities.FunnelSearch,
+		Origin:      strings.TrimSpace(opts.Origin),
+		Destination: strings.TrimSpace(opts.Destination),
+		ResultCount: &count,
+	}
+	if !opts.Date.IsZero() {
+		date := time.Date(opts.Date.Year(), opts.Date.Month(), opts.Date.Day(), 0, 0, 0, 0, time.UTC)
+		event.TravelDate = &date
+	}
+	t.record(ctx, event)
+}
+
+// TrackTripView records a visitor opening a trip's details
+func (t *FunnelTracker) TrackTripView(ctx context.Context, trip *entities.Trip) {
+	if t == nil {
+		return
+	}
+	t.record(ctx, &entities.FunnelEvent{
+		EventType: entities.FunnelTripView,
+		RouteID:   &trip.RouteID,
+		TripID:    &trip.ID,
+	})
+}
+
+// TrackSeatsReserved records a seat hold; sessionID is the reservation session, used when the request carries none
+func (t *FunnelTracker) TrackSeatsReserved(ctx context.Context, sessionID string, tripID uuid.UUID, seats int) {
+	if t == nil {
+		return
+	}
+	t.record(ctx, &entities.FunnelEvent{
+		SessionID: sessionID,
+		EventType: entities.FunnelSeatsReserved,
+		TripID:    &tripID,
+		SeatCount: seats,
+	})
+}
+
+// TrackBooking records a booking step: created, payment started or payment completed
+// Steps observed outside the visitor's requests, such as payment webhooks, inherit the session the booking was created in
+func (t *FunnelTracker) TrackBooking(ctx context.Context, eventType entities.FunnelEventType, booking *entities.Booking, sessionID string) {
+	if t == nil {
+		return
+	}
+	t.record(ctx, &entities.FunnelEvent{
+		SessionID: sessionID,
+		EventType: eventType,
+		UserID:    booking.UserID,
+		TripID:    &booking.TripID,
+		BookingID: &booking.ID,
+		SeatCount: booking.TotalSeats,
+	})
+}
+
+// CleanupExpired removes funnel events older than the retention period
+func (t *FunnelTracker) CleanupExpired(ctx context.Context) error {
+	return t.funnelRepo.DeleteBefore(ctx, time.Now().Add(-t.retention))
+}
+
+func (t *FunnelTracker) record(ctx context.Context, event *entities.FunnelEvent) {
+	if session := FunnelSession(ctx); session != "" {
+		event.SessionID = session
+	}
+
+	go func() {
+		bgCtx := context.Background()
+		if event.SessionID == "" && event.BookingID != nil {
+			session, err := t.funnelRepo.SessionForBooking(bgCtx, *event.BookingID)
+			if err != nil {
+				log.Printf("[Funnel] Failed to find session for booking %s: %v", *event.BookingID, err)
+			}
+			event.SessionID = session
+		}
+		if event.SessionID == "" {
+			// Requests without a visitor session (partner API, scripts) are not part of the funnel
+			return
+		}
+		if event.RouteID == nil && event.TripID != nil {
+			if trip, err := t.tripRepo.GetByID(bgCtx, *event.TripID); err == nil {
+				event.RouteID = &trip.RouteID
+			}
+		}
+		if err := t.funnelRepo.Create(bgCtx, event); err != nil {
+			log.Printf("[Funnel] Failed to record %s event: %v", event.EventType, err)
+		}
+	}()
+}
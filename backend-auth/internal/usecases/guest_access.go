@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const (
+	guestBookingPurpose = "guest_booking"
+	guestAccessTokenTTL = 30 * time.Minute
+)
+
+// Booking access scopes, each opening one group of guest booking routes
+const (
+	BookingScopeView       = "view"
+	BookingScopeDownload   = "download"
+	BookingScopePassengers = "passengers"
+	BookingScopeCancel     = "cancel"
+
+	guestBookingScope = BookingScopeView + " " + BookingScopeDownload + " " + BookingScopePassengers + " " + BookingScopeCancel
+)
+
+// GuestAccessRequest identifies a booking by its reference and its contact email
+type GuestAccessRequest struct {
+	Reference string
+	Email     string
+}
+
+// BookingAccessToken grants access to a single booking without an account
+type BookingAccessToken struct {
+	Token     string    `json:"token"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GuestBookingAccess is returned once a guest has proven they own the booking
+type GuestBookingAccess struct {
+	Access  *BookingAccessToken `json:"access"`
+	Booking *BookingResponse    `json:"booking"`
+}
+
+// RequestGuestAccess emails a one-time code to the booking's contact email
+// The caller must know the reference and the email it is sent to. Unknown or mismatching
+// details are silently ignored so the endpoint cannot be used to probe bookings
+func (uc *BookingUsecase) RequestGuestAccess(ctx context.Context, req GuestAccessRequest) error {
+	// Checked before the lookup so the outcome does not depend on whether the booking exists
+	if uc.emailService == nil {
+		return errors.New("email service is not configured")
+	}
+
+	booking, err := uc.bookingRepo.GetByReference(ctx, strings.TrimSpace(req.Reference))
+	if err != nil || booking.UserID != nil {
+		return nil
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" || !strings.EqualFold(email, booking.ContactEmail) {
+		return nil
+	}
+
+	if latest, err := uc.accessCodeRepo.GetLatestForBooking(ctx, booking.ID, entities.BookingAccessGuest); err == nil &&
+		latest.UsedAt == nil && time.Since(latest.CreatedAt) < bookingCodeResendAfter {
+		return nil
+	}
+
+	code, err := generateBookingCode()
+	if err != nil {
+		return err
+	}
+
+	accessCode := &entities.BookingAccessCode{
+		Purpose:     entities.BookingAccessGuest,
+		BookingIDs:  booking.ID.String(),
+		Destination: booking.ContactEmail,
+		CodeHash:    hashRecoveryCode(code),
+		ExpiresAt:   time.Now().Add(bookingCodeTTL),
+	}
+	if err := uc.accessCodeRepo.Create(ctx, accessCode); err != nil {
+		return fmt.Errorf("failed to store access code: %w", err)
+	}
+
+	body := services.NewEmailTemplates().GuestAccessCodeEmail(booking.ContactName, booking.BookingReference, code, bookingCodeTTL)
+	go func() {
+		if err := uc.emailService.SendHTMLEmail(booking.ContactEmail, booking.ContactName, "Your booking access code", body); err != nil {
+			log.Printf("[Booking] Failed to send access code for booking %s: %v", booking.ID, err)
+		}
+	}()
+	return nil
+}
+
+// VerifyGuestAccess checks the one-time code and returns a booking-scoped access token
+func (uc *BookingUsecase) VerifyGuestAccess(ctx context.Context, reference, code string) (*GuestBookingAccess, error) {
+	invalid := errors.New("invalid or expired code, please request a new one")
+
+	booking, err := uc.bookingRepo.GetByReference(ctx, strings.TrimSpace(reference))
+	if err != nil || booking.UserID != nil {
+		return nil, invalid
+	}
+
+	accessCode, err := uc.accessCodeRepo.GetLatestForBooking(ctx, booking.ID, entities.BookingAccessGuest)
+	if err != nil || !accessCode.IsUsable(bookingCodeMaxAttempts) {
+		return nil, invalid
+	}
+	if hashRecoveryCode(strings.TrimSpace(code)) != accessCode.CodeHash {
+		accessCode.Attempts++
+		if err := uc.accessCodeRepo.Update(ctx, accessCode); err != nil {
+			log.Printf("[Booking] Failed to record access attempt for booking %s: %v", booking.ID, err)
+		}
+		return nil, invalid
+	}
+
+	used, err := uc.accessCodeRepo.MarkUsed(ctx, accessCode.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem code: %w", err)
+	}
+	if !used {
+		return nil, invalid
+	}
+
+	access, err := uc.IssueBookingAccessToken(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := uc.GetBookingDetails(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &GuestBookingAccess{Access: access, Booking: details}, nil
+}
+
+// IssueBookingAccessToken signs a short-lived token that grants access to one booking
+func (uc *BookingUsecase) IssueBookingAccessToken(bookingID uuid.UUID) (*BookingAccessToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(guestAccessTokenTTL)
+	claims := jwt.MapClaims{
+		"purpose":    guestBookingPurpose,
+		"booking_id": bookingID.String(),
+		"scope":      guestBookingScope,
+		"exp":        expiresAt.Unix(),
+		"iat":        now.Unix(),
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uc.guestTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return &BookingAccessToken{Token: tokenString, Scope: guestBookingScope, ExpiresAt: expiresAt}, nil
+}
+
+// ParseBookingAccessToken returns the booking a guest access token was issued for
+// The token must carry the requested scope, and stops working once the booking is claimed into an account
+func (uc *BookingUsecase) ParseBookingAccessToken(ctx context.Context, tokenString, scope string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return uc.guestTokenKey, nil
+	})
+	if err != nil || !token.Valid {
+		return uuid.Nil, errors.New("invalid or expired booking access token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != guestBookingPurpose {
+		return uuid.Nil, errors.New("invalid booking access token")
+	}
+	bookingIDStr, _ := claims["booking_id"].(string)
+	bookingID, err := uuid.Parse(bookingIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid booking access token")
+	}
+	granted, _ := claims["scope"].(string)
+	if !hasScope(granted, scope) {
+		return uuid.Nil, errors.New("booking access token does not allow this action")
+	}
+
+	booking, err := uc.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid booking access token")
+	}
+	if booking.UserID != nil {
+		return uuid.Nil, errors.New("booking has been added to an account, sign in to manage it")
+	}
+	return bookingID, nil
+}
+
+// AuthorizeBookingOwner checks that a booking belongs to the user's account
+// Other users' and guest bookings are reported as not found so their IDs cannot be probed
+func (uc *BookingUsecase) AuthorizeBookingOwner(ctx context.Context, userID, bookingID uuid.UUID) error {
+	booking, err := uc.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil || booking.UserID == nil || *booking.UserID != userID {
+		return errors.New("booking not found")
+	}
+	return nil
+}
+
+// TicketBookingID returns the booking a ticket was issued on
+func (uc *BookingUsecase) TicketBookingID(ctx context.Context, ticketID uuid.UUID) (uuid.UUID, error) {
+	ticket, err := uc.ticketRepo.GetByID(ctx, ticketID)
+	if err != nil {
+		return uuid.Nil, errors.New("ticket not found")
+	}
+	return ticket.BookingID, nil
+}
+
+// GetBookingDetails retrieves a booking with its passengers and tickets
+func (uc *BookingUsecase) GetBookingDetails(ctx context.Context, bookingID uuid.UUID) (*BookingResponse, error) {
+	booking, err := uc.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	passengers, err := uc.passengerRepo.GetByBookingID(ctx, booking.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passengers: %w", err)
+	}
+
+	tickets, err := uc.ticketRepo.GetByBookingID(ctx, booking.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	return &BookingResponse{
+		Booking:    booking,
+		Passengers: passengers,
+		Tickets:    tickets,
+	}, nil
+}
+
+// hasScope reports whether a space-separated scope list contains scope
+func hasScope(granted, scope string) bool {
+	for _, s := range strings.Fields(granted) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
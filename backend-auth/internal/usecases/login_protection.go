@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// LoginThrottledError is returned while an account or IP address must wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, please try again in %d seconds", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// checkLoginAllowed rejects attempts for locked accounts or blocked IPs before any password work is done
+// Locks are keyed by the submitted email, so unknown addresses behave exactly like real ones
+func (uc *AuthUsecase) checkLoginAllowed(ctx context.Context, email string) error {
+	if uc.loginThrottle == nil {
+		return nil
+	}
+	if wait := uc.loginThrottle.Check(ctx, email, clientInfoFrom(ctx).IPAddress); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and audits and notifies when it triggers a lockout
+func (uc *AuthUsecase) recordLoginFailure(ctx context.Context, email string, user *entities.User) {
+	if uc.loginThrottle == nil {
+		return
+	}
+	ip := clientInfoFrom(ctx).IPAddress
+	result := uc.loginThrottle.RecordFailure(ctx, email, ip)
+
+	if result.AccountLocked {
+		var userID *uuid.UUID
+		if user != nil {
+			userID = &user.ID
+		}
+		details := fmt.Sprintf("locked until %s after %d failed attempts", result.LockedUntil.Format(time.RFC3339), result.AccountFailures)
+		uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+			EventType: entities.SecurityEventAccountLocked,
+			UserID:    userID,
+			Email:     email,
+			IPAddress: ip,
+			Details:   &details,
+		})
+		log.Printf("[Auth] Account %s locked after %d failed login attempts (IP %s)", email, result.AccountFailures, ip)
+
+		if user != nil {
+			uc.sendAccountLockedEmail(user, result.LockedUntil, ip)
+		}
+	}
+
+	if result.IPBlocked {
+		details := fmt.Sprintf("blocked after %d failed attempts", result.IPFailures)
+		uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+			EventType: entities.SecurityEventIPBlocked,
+			Email:     email,
+			IPAddress: ip,
+			Details:   &details,
+		})
+		log.Printf("[Auth] IP %s blocked after %d failed login attempts", ip, result.IPFailures)
+	}
+}
+
+// recordLoginSuccess clears the account's failure history
+func (uc *AuthUsecase) recordLoginSuccess(ctx context.Context, email string) {
+	if uc.loginThrottle != nil {
+		uc.loginThrottle.RecordSuccess(ctx, email)
+	}
+}
+
+// UnlockAccount lifts a login lockout on behalf of an admin
+func (uc *AuthUsecase) UnlockAccount(ctx context.Context, userID uuid.UUID, actorID *uuid.UUID) error {
+	user, err := uc.getManagedUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if uc.loginThrottle == nil {
+		return nil
+	}
+
+	status := uc.loginThrottle.Status(ctx, user.Email)
+	uc.loginThrottle.Unlock(ctx, user.Email)
+
+	details := "failure history cleared"
+	if status.Locked {
+		details = "lockout lifted"
+	}
+	uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+		EventType: entities.SecurityEventAccountUnlocked,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		ActorID:   actorID,
+		Details:   &details,
+	})
+	log.Printf("[Auth] Account %s unlocked by %v", user.ID, actorID)
+	return nil
+}
+
+// GetLoginLockStatus returns the lockout state of a user's account
+func (uc *AuthUsecase) GetLoginLockStatus(ctx context.Context, userID uuid.UUID) (*services.LoginLockStatus, error) {
+	user, err := uc.getManagedUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if uc.loginThrottle == nil {
+		return &services.LoginLockStatus{}, nil
+	}
+	status := uc.loginThrottle.Status(ctx, user.Email)
+	return &status, nil
+}
+
+// ListSecurityEvents returns lockout and unlock audit records
+func (uc *AuthUsecase) ListSecurityEvents(ctx context.Context, filter repositories.SecurityEventFilter, page, pageSize int) ([]*entities.SecurityEvent, int64, error) {
+	events, total, err := uc.securityEventRepo.List(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get security events: %w", err)
+	}
+	return events, total, nil
+}
+
+func (uc *AuthUsecase) recordSecurityEvent(ctx context.Context, event *entities.SecurityEvent) {
+	if err := uc.securityEventRepo.Create(ctx, event); err != nil {
+		log.Printf("[Auth] Failed to record security event %s: %v", event.EventType, err)
+	}
+}
+
+func (uc *AuthUsecase) sendAccountLockedEmail(user *entities.User, lockedUntil time.Time, ip string) {
+	if uc.emailService == nil {
+		return
+	}
+	if ip == "" {
+		ip = "unknown"
+	}
+	resetURL := uc.frontendURL + "/forgot-password"
+	body := services.NewEmailTemplates().AccountLockedEmail(user.Name, lockedUntil, ip, resetURL)
+	go func(toEmail, toName string, userID uuid.UUID) {
+		if err := uc.emailService.SendHTMLEmail(toEmail, toName, "Your account has been temporarily locked", body); err != nil {
+			log.Printf("[Auth] Failed to send lockout email to user %s: %v", userID, err)
+		}
+	}(user.Email, user.Name, user.ID)
+}
@@ -0,0 +1,555 @@
+package usecases
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const (
+	mfaChallengePurpose  = "mfa_challenge"  // Password accepted, second factor pending
+	mfaEnrollmentPurpose = "mfa_enrollment" // Password accepted, account must enroll before signing in
+	mfaSecretPurpose     = "mfa_secret"     // Derives the legacy key for TOTP secrets
+
+	mfaSecretPrefix      = "enc:" // Marks an encrypted secret as enc:<key id>:<data>; older rows hold the plain secret
+	mfaLegacySecretKeyID = "v1"   // Secrets encrypted with a key derived from JWT_SECRET before MFA_ENCRYPTION_KEY existed
+
+	mfaMaxAttempts        = 5
+	mfaRecoveryCodeCount  = 10
+	mfaRecoveryCodeLength = 10
+	mfaRecoveryAlphabet   = "abcdefghjkmnpqrstuvwxyz23456789" // No 0/o, 1/l/i to avoid transcription mistakes
+)
+
+// MFASetup is the enrollment material shown to the user once
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          string `json:"qr_code"` // PNG as a data URL
+}
+
+// MFAStatus describes the two-factor state of an account
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// mfaAttemptTracker limits guesses per challenge token so a 6-digit code cannot be brute forced
+type mfaAttemptTracker struct {
+	mu       sync.Mutex
+	attempts map[string]mfaAttempt
+}
+
+type mfaAttempt struct {
+	count     int
+	expiresAt time.Time
+}
+
+func newMFAAttemptTracker() *mfaAttemptTracker {
+	return &mfaAttemptTracker{attempts: make(map[string]mfaAttempt)}
+}
+
+// exhausted reports whether the challenge has no attempts left
+func (t *mfaAttemptTracker) exhausted(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attempts[id].count >= mfaMaxAttempts
+}
+
+func (t *mfaAttemptTracker) fail(id string, expiresAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for key, attempt := range t.attempts {
+		if now.After(attempt.expiresAt) {
+			delete(t.attempts, key)
+		}
+	}
+	attempt := t.attempts[id]
+	attempt.count++
+	attempt.expiresAt = expiresAt
+	t.attempts[id] = attempt
+}
+
+// mfaRequired reports whether policy forces two-factor authentication for the account
+// The admin policy covers every back-office role
+func (uc *AuthUsecase) mfaRequired(user *entities.User) bool {
+	return uc.mfaRequiredForAdmins && user.Role.IsStaff()
+}
+
+// mfaChallenge returns a challenge instead of tokens when the account needs a second factor
+// Returns nil when the login can complete with the password alone
+func (uc *AuthUsecase) mfaChallenge(user *entities.User) (*AuthTokens, error) {
+	purpose := ""
+	switch {
+	case user.MFAEnabled:
+		purpose = mfaChallengePurpose
+	case uc.mfaRequired(user):
+		purpose = mfaEnrollmentPurpose
+	default:
+		return nil, nil
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"purpose": purpose,
+		"user_id": user.ID.String(),
+		"jti":     uuid.New().String(),
+		"exp":     now.Add(uc.mfaChallengeTTL).Unix(),
+		"iat":     now.Unix(),
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uc.mfaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign MFA challenge: %w", err)
+	}
+
+	user.PasswordHash = ""
+	return &AuthTokens{
+		User:                  user,
+		MFAToken:              tokenString,
+		MFAEnrollmentRequired: purpose == mfaEnrollmentPurpose,
+	}, nil
+}
+
+// parseMFAToken validates a challenge token for the expected purpose
+func (uc *AuthUsecase) parseMFAToken(ctx context.Context, tokenString, purpose string) (*entities.User, string, time.Time, error) {
+	invalid := errors.New("invalid or expired MFA token, please log in again")
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return uc.mfaKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, "", time.Time{}, invalid
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose {
+		return nil, "", time.Time{}, invalid
+	}
+	jti, _ := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if jti == "" || err != nil || exp == nil {
+		return nil, "", time.Time{}, invalid
+	}
+	if uc.mfaAttempts.exhausted(jti) {
+		return nil, "", time.Time{}, errors.New("too many invalid codes, please log in again")
+	}
+
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, "", time.Time{}, invalid
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", time.Time{}, invalid
+	}
+	if !user.IsActive {
+		return nil, "", time.Time{}, errors.New("account is inactive")
+	}
+	return user, jti, exp.Time, nil
+}
+
+// VerifyMFA completes a two-step login with an authenticator or recovery code
+func (uc *AuthUsecase) VerifyMFA(ctx context.Context, mfaToken, code string) (*AuthTokens, error) {
+	user, jti, expiresAt, err := uc.parseMFAToken(ctx, mfaToken, mfaChallengePurpose)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkLoginAllowed(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	ok, err := uc.verifySecondFactor(ctx, user, code, true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.mfaAttempts.fail(jti, expiresAt)
+		uc.recordLoginFailure(ctx, user.Email, user)
+		log.Printf("[Auth] Invalid MFA code for user %s", user.ID)
+		return nil, errors.New("invalid two-factor code")
+	}
+	uc.recordLoginSuccess(ctx, user.Email)
+
+	return uc.issueTokens(ctx, user)
+}
+
+// SetupMFAEnrollment starts enrollment for an account that policy blocks from signing in without MFA
+func (uc *AuthUsecase) SetupMFAEnrollment(ctx context.Context, mfaToken string) (*MFASetup, error) {
+	user, _, _, err := uc.parseMFAToken(ctx, mfaToken, mfaEnrollmentPurpose)
+	if err != nil {
+		return nil, err
+	}
+	return uc.setupMFA(ctx, user)
+}
+
+// ConfirmMFAEnrollment enables MFA from the enrollment challenge and completes the login
+func (uc *AuthUsecase) ConfirmMFAEnrollment(ctx context.Context, mfaToken, code string) (*AuthTokens, []string, error) {
+	user, jti, expiresAt, err := uc.parseMFAToken(ctx, mfaToken, mfaEnrollmentPurpose)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := uc.checkLoginAllowed(ctx, user.Email); err != nil {
+		return nil, nil, err
+	}
+
+	recoveryCodes, err := uc.enableMFA(ctx, user, code)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid two-factor code") {
+			uc.mfaAttempts.fail(jti, expiresAt)
+			uc.recordLoginFailure(ctx, user.Email, user)
+		}
+		return nil, nil, err
+	}
+	uc.recordLoginSuccess(ctx, user.Email)
+
+	tokens, err := uc.issueTokens(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, recoveryCodes, nil
+}
+
+// GetMFAStatus returns the two-factor state of the user's account
+func (uc *AuthUsecase) GetMFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &MFAStatus{
+		Enabled:   user.MFAEnabled,
+		EnabledAt: user.MFAEnabledAt,
+		Required:  uc.mfaRequired(user),
+	}
+	if user.MFAEnabled {
+		remaining, err := uc.mfaRecoveryRepo.CountUnused(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+	return status, nil
+}
+
+// SetupMFA generates a new authenticator secret for the signed-in user
+// MFA stays disabled until EnableMFA confirms a code from the app
+func (uc *AuthUsecase) SetupMFA(ctx context.Context, userID uuid.UUID) (*MFASetup, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return uc.setupMFA(ctx, user)
+}
+
+// EnableMFA confirms enrollment with a code from the authenticator app and returns recovery codes
+func (uc *AuthUsecase) EnableMFA(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return uc.enableMFA(ctx, user, code)
+}
+
+// DisableMFA turns off two-factor authentication after checking a current code
+func (uc *AuthUsecase) DisableMFA(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.MFAEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if uc.mfaRequired(user) {
+		return errors.New("two-factor authentication is required for admin accounts and cannot be disabled")
+	}
+
+	ok, err := uc.verifySecondFactor(ctx, user, code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid two-factor code")
+	}
+
+	user.MFAEnabled = false
+	user.MFAEnabledAt = nil
+	user.MFASecret = ""
+	user.MFALastUsedStep = 0
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	if err := uc.mfaRecoveryRepo.DeleteForUser(ctx, user.ID); err != nil {
+		log.Printf("[Auth] Failed to delete recovery codes for user %s: %v", user.ID, err)
+	}
+
+	log.Printf("[Auth] Two-factor authentication disabled for user %s", user.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking an authenticator code
+func (uc *AuthUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	ok, err := uc.verifySecondFactor(ctx, user, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+	return uc.replaceRecoveryCodes(ctx, user.ID)
+}
+
+func (uc *AuthUsecase) setupMFA(ctx context.Context, user *entities.User) (*MFASetup, error) {
+	if user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := services.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.MFASecret, err = uc.sealMFASecret(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %w", err)
+	}
+
+	uri := services.TOTPProvisioningURI(uc.mfaIssuer, user.Email, secret)
+	png, err := services.TOTPQRCode(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFASetup{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (uc *AuthUsecase) enableMFA(ctx context.Context, user *entities.User, code string) ([]string, error) {
+	if user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.MFASecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	secret, err := uc.openMFASecret(user)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := services.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	now := time.Now()
+	user.MFAEnabled = true
+	user.MFAEnabledAt = &now
+	user.MFALastUsedStep = step
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	recoveryCodes, err := uc.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Auth] Two-factor authentication enabled for user %s", user.ID)
+	return recoveryCodes, nil
+}
+
+// sealMFASecret encrypts a TOTP secret for storage with AES-256-GCM under the current key
+// The user ID is bound as associated data so a sealed secret cannot be copied to another account
+func (uc *AuthUsecase) sealMFASecret(userID uuid.UUID, secret string) (string, error) {
+	keyID, key := uc.mfaSecretKeys.Current()
+	gcm, err := mfaSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), userID[:])
+	return mfaSecretPrefix + keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// openMFASecret returns the user's TOTP secret in plain form
+// Secrets saved before encryption at rest are returned as stored
+func (uc *AuthUsecase) openMFASecret(user *entities.User) (string, error) {
+	rest, ok := strings.CutPrefix(user.MFASecret, mfaSecretPrefix)
+	if !ok {
+		return user.MFASecret, nil
+	}
+	keyID, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("failed to read two-factor secret")
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.New("failed to read two-factor secret")
+	}
+	key, err := uc.mfaSecretKeys.Key(keyID)
+	if err != nil && keyID == mfaLegacySecretKeyID {
+		key, err = uc.legacyMFASecretKey, nil
+	}
+	if err != nil {
+		log.Printf("[Auth] Two-factor secret for user %s uses unknown key %q", user.ID, keyID)
+		return "", errors.New("failed to read two-factor secret")
+	}
+	gcm, err := mfaSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("failed to read two-factor secret")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, ciphertext, user.ID[:])
+	if err != nil {
+		return "", errors.New("failed to read two-factor secret")
+	}
+	return string(secret), nil
+}
+
+// mfaSecretCurrent reports whether a stored secret is encrypted with the current key
+func (uc *AuthUsecase) mfaSecretCurrent(stored string) bool {
+	keyID, _ := uc.mfaSecretKeys.Current()
+	return strings.HasPrefix(stored, mfaSecretPrefix+keyID+":")
+}
+
+func mfaSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up two-factor secret encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// verifySecondFactor checks an authenticator code, or a recovery code when allowed
+func (uc *AuthUsecase) verifySecondFactor(ctx context.Context, user *entities.User, code string, allowRecovery bool) (bool, error) {
+	secret, err := uc.openMFASecret(user)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := services.ValidateTOTP(secret, code, time.Now()); ok {
+		// A code stays valid for its whole window; accepting it once stops replay by an observer
+		if step <= user.MFALastUsedStep {
+			return false, nil
+		}
+		user.MFALastUsedStep = step
+		if !uc.mfaSecretCurrent(user.MFASecret) {
+			// Re-encrypt plain secrets and secrets under a retired key on their next use
+			if user.MFASecret, err = uc.sealMFASecret(user.ID, secret); err != nil {
+				return false, err
+			}
+		}
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return false, fmt.Errorf("failed to record two-factor code: %w", err)
+		}
+		return true, nil
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != mfaRecoveryCodeLength {
+		return false, nil
+	}
+	consumed, err := uc.mfaRecoveryRepo.Consume(ctx, user.ID, hashRecoveryCode(normalized))
+	if err != nil {
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if consumed {
+		log.Printf("[Auth] Recovery code used for user %s", user.ID)
+	}
+	return consumed, nil
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes, invalidating the old ones
+func (uc *AuthUsecase) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	records := make([]*entities.MFARecoveryCode, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:mfaRecoveryCodeLength/2]+"-"+code[mfaRecoveryCodeLength/2:])
+		records = append(records, &entities.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	if err := uc.mfaRecoveryRepo.ReplaceForUser(ctx, userID, records); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	const maxByte = 256 - 256%len(mfaRecoveryAlphabet) // Rejection sampling keeps the alphabet uniform
+	code := make([]byte, 0, mfaRecoveryCodeLength)
+	buf := make([]byte, mfaRecoveryCodeLength*2)
+	for len(code) < mfaRecoveryCodeLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= maxByte || len(code) == mfaRecoveryCodeLength {
+				continue
+			}
+			code = append(code, mfaRecoveryAlphabet[int(b)%len(mfaRecoveryAlphabet)])
+		}
+	}
+	return string(code), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
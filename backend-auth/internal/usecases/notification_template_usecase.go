@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// NotificationTemplateUsecase handles admin management of stored notification templates
+type NotificationTemplateUsecase struct {
+	templateRepo   repositories.NotificationTemplateRepository
+	templateEngine *services.NotificationTemplateEngine
+}
+
+// NewNotificationTemplateUsecase creates a new notification template usecase
+func NewNotificationTemplateUsecase(
+	templateRepo repositories.NotificationTemplateRepository,
+	templateEngine *services.NotificationTemplateEngine,
+) *NotificationTemplateUsecase {
+	return &NotificationTemplateUsecase{
+		templateRepo:   templateRepo,
+		templateEngine: templateEngine,
+	}
+}
+
+// NotificationTemplateInput represents template content submitted by an admin
+type NotificationTemplateInput struct {
+	Type     entities.NotificationType
+	Locale   string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// NotificationTemplateUpdate holds the fields an admin changes when editing a template
+// Empty Subject and HTMLBody keep the current values; a nil TextBody keeps it and an empty one clears it
+type NotificationTemplateUpdate struct {
+	Subject  string
+	HTMLBody string
+	TextBody *string
+}
+
+// templatableTypes lists notification types that have render data and a compiled-in fallback
+var templatableTypes = map[entities.NotificationType]bool{
+	entities.NotificationTypeBookingConfirmation: true,
+	entities.NotificationTypePaymentReceipt:      true,
+	entities.NotificationTypeTripReminder:        true,
+	entities.NotificationTypeCancellation:        true,
+	entities.NotificationTypeETicket:             true,
+}
+
+// ListTemplates returns the active template for each type and locale
+func (u *NotificationTemplateUsecase) ListTemplates(ctx context.Context, notifType *entities.NotificationType) ([]*entities.NotificationTemplate, error) {
+	return u.templateRepo.GetAllActive(ctx, notifType)
+}
+
+// GetTemplate retrieves a template version by ID
+func (u *NotificationTemplateUsecase) GetTemplate(ctx context.Context, id uuid.UUID) (*entities.NotificationTemplate, error) {
+	tmpl, err := u.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("template not found: %w", err)
+	}
+	return tmpl, nil
+}
+
+// GetTemplateVersions returns every version of the template family the given version belongs to
+func (u *NotificationTemplateUsecase) GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*entities.NotificationTemplate, error) {
+	tmpl, err := u.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.templateRepo.GetVersions(ctx, tmpl.Type, tmpl.Locale)
+}
+
+// CreateTemplate stores a new template version for a type and locale and makes it active
+func (u *NotificationTemplateUsecase) CreateTemplate(ctx context.Context, input NotificationTemplateInput, createdBy *uuid.UUID) (*entities.NotificationTemplate, error) {
+	if !templatableTypes[input.Type] {
+		return nil, fmt.Errorf("invalid notification type: %s", input.Type)
+	}
+	input.Locale = services.NormalizeLocale(input.Locale)
+	if input.Locale == "" {
+		input.Locale = u.templateEngine.DefaultLocale()
+	}
+
+	tmpl := &entities.NotificationTemplate{
+		Type:      input.Type,
+		Locale:    input.Locale,
+		Subject:   input.Subject,
+		HTMLBody:  input.HTMLBody,
+		TextBody:  input.TextBody,
+		CreatedBy: createdBy,
+	}
+
+	if err := u.validate(tmpl); err != nil {
+		return nil, err
+	}
+
+	if err := u.templateRepo.CreateVersion(ctx, tmpl); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("template version conflict: another version was saved at the same time, please retry")
+		}
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// UpdateTemplate creates a new version based on an existing one
+// Previous versions are kept for history and can be re-activated
+func (u *NotificationTemplateUsecase) UpdateTemplate(ctx context.Context, id uuid.UUID, input NotificationTemplateUpdate, createdBy *uuid.UUID) (*entities.NotificationTemplate, error) {
+	existing, err := u.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	next := NotificationTemplateInput{
+		Type:     existing.Type,
+		Locale:   existing.Locale,
+		Subject:  existing.Subject,
+		HTMLBody: existing.HTMLBody,
+		TextBody: existing.TextBody,
+	}
+	if input.Subject != "" {
+		next.Subject = input.Subject
+	}
+	if input.HTMLBody != "" {
+		next.HTMLBody = input.HTMLBody
+	}
+	if input.TextBody != nil {
+		next.TextBody = *input.TextBody
+	}
+
+	return u.CreateTemplate(ctx, next, createdBy)
+}
+
+// ActivateVersion makes a specific template version the active one (rollback)
+func (u *NotificationTemplateUsecase) ActivateVersion(ctx context.Context, id uuid.UUID) (*entities.NotificationTemplate, error) {
+	if _, err := u.GetTemplate(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.templateRepo.Activate(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to activate template: %w", err)
+	}
+	return u.GetTemplate(ctx, id)
+}
+
+// DeleteTemplate deactivates all versions of a template family so the compiled-in template is used again
+func (u *NotificationTemplateUsecase) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	tmpl, err := u.GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	return u.templateRepo.Deactivate(ctx, tmpl.Type, tmpl.Locale)
+}
+
+// PreviewTemplate renders template content against sample data for its type
+// overrides, if provided, is a JSON object whose fields replace the sample values
+func (u *NotificationTemplateUsecase) PreviewTemplate(tmpl *entities.NotificationTemplate, overrides json.RawMessage) (*services.RenderedTemplate, error) {
+	if !templatableTypes[tmpl.Type] {
+		return nil, fmt.Errorf("invalid notification type: %s", tmpl.Type)
+	}
+
+	data, err := previewData(tmpl.Type, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.RenderTemplate(tmpl, data)
+}
+
+// PreviewStoredTemplate renders a stored template version against sample data
+func (u *NotificationTemplateUsecase) PreviewStoredTemplate(ctx context.Context, id uuid.UUID, overrides json.RawMessage) (*services.RenderedTemplate, error) {
+	tmpl, err := u.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.PreviewTemplate(tmpl, overrides)
+}
+
+// validate checks required fields and template syntax
+func (u *NotificationTemplateUsecase) validate(tmpl *entities.NotificationTemplate) error {
+	if tmpl.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if tmpl.HTMLBody == "" {
+		return fmt.Errorf("html_body is required")
+	}
+	if err := services.ValidateTemplate(tmpl); err != nil {
+		return err
+	}
+
+	// Render against sample data so references to unknown fields are caught before saving
+	if _, err := u.PreviewTemplate(tmpl, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// previewData builds sample render data for a type, applying JSON overrides
+func previewData(notifType entities.NotificationType, overrides json.RawMessage) (interface{}, error) {
+	sample := services.SampleTemplateData(notifType)
+	if len(overrides) == 0 {
+		return sample, nil
+	}
+
+	switch data := sample.(type) {
+	case services.BookingConfirmationData:
+		if err := json.Unmarshal(overrides, &data); err != nil {
+			return nil, fmt.Errorf("invalid preview data: %w", err)
+		}
+		return data, nil
+	case services.PaymentReceiptData:
+		if err := json.Unmarshal(overrides, &data); err != nil {
+			return nil, fmt.Errorf("invalid preview data: %w", err)
+		}
+		return data, nil
+	case services.TripReminderData:
+		if err := json.Unmarshal(overrides, &data); err != nil {
+			return nil, fmt.Errorf("invalid preview data: %w", err)
+		}
+		return data, nil
+	case services.CancellationData:
+		if err := json.Unmarshal(overrides, &data); err != nil {
+			return nil, fmt.Errorf("invalid preview data: %w", err)
+		}
+		return data, nil
+	case services.TicketEmailData:
+		if err := json.Unmarshal(overrides, &data); err != nil {
+			return nil, fmt.Errorf("invalid preview data: %w", err)
+		}
+		return data, nil
+	default:
+		return sample, nil
+	}
+}
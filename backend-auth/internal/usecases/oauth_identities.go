@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+)
+
+// LinkedIdentities lists a user's sign-in methods
+type LinkedIdentities struct {
+	Identities  []*entities.UserIdentity `json:"identities"`
+	HasPassword bool                     `json:"has_password"`
+}
+
+// LoginWithOAuth signs in with a verified OAuth identity
+// The identity is looked up across all linked accounts first. An unknown identity whose verified
+// email matches an existing user is linked to that user, otherwise a new account is created
+func (uc *AuthUsecase) LoginWithOAuth(ctx context.Context, input OAuthUserInput) (*AuthTokens, error) {
+	provider := entities.OAuthProvider(input.Provider)
+	if !provider.IsValid() || input.ProviderUserID == "" {
+		return nil, errors.New("invalid OAuth identity")
+	}
+
+	identity, err := uc.identityRepo.GetByProviderUserID(ctx, provider, input.ProviderUserID)
+	if err == nil {
+		if err := uc.identityRepo.TouchLastLogin(ctx, identity.ID, time.Now()); err != nil {
+			log.Printf("[Auth] Failed to record %s login for user %s: %v", provider, identity.UserID, err)
+		}
+		return uc.LoginOAuth(ctx, identity.UserID)
+	}
+
+	existing, _ := uc.userRepo.GetByEmail(ctx, input.Email)
+	if existing == nil {
+		return uc.RegisterOAuth(ctx, input)
+	}
+
+	if _, err := uc.linkIdentity(ctx, existing, input); err != nil {
+		return nil, err
+	}
+	return uc.LoginOAuth(ctx, existing.ID)
+}
+
+// ListIdentities returns the OAuth providers linked to a user and whether they have a password
+func (uc *AuthUsecase) ListIdentities(ctx context.Context, userID uuid.UUID) (*LinkedIdentities, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	identities, err := uc.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked accounts: %w", err)
+	}
+	return &LinkedIdentities{Identities: identities, HasPassword: user.PasswordHash != ""}, nil
+}
+
+// LinkIdentity attaches a verified OAuth identity to a signed-in user
+func (uc *AuthUsecase) LinkIdentity(ctx context.Context, userID uuid.UUID, input OAuthUserInput) (*entities.UserIdentity, error) {
+	provider := entities.OAuthProvider(input.Provider)
+	if !provider.IsValid() || input.ProviderUserID == "" {
+		return nil, errors.New("invalid OAuth identity")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return uc.linkIdentity(ctx, user, input)
+}
+
+// UnlinkIdentity removes an OAuth provider from a user
+// The user must keep another way to sign in: a password or another linked provider
+func (uc *AuthUsecase) UnlinkIdentity(ctx context.Context, userID uuid.UUID, provider entities.OAuthProvider) error {
+	linked, err := uc.ListIdentities(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var target *entities.UserIdentity
+	for _, identity := range linked.Identities {
+		if identity.Provider == provider {
+			target = identity
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no %s account is linked", provider)
+	}
+	if !linked.HasPassword && len(linked.Identities) == 1 {
+		return errors.New("cannot unlink your only sign-in method, set a password or link another provider first")
+	}
+
+	if err := uc.identityRepo.Delete(ctx, userID, provider); err != nil {
+		return fmt.Errorf("failed to unlink %s account: %w", provider, err)
+	}
+
+	details := string(provider)
+	uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+		EventType: entities.SecurityEventIdentityUnlinked,
+		UserID:    &userID,
+		Email:     target.Email,
+		IPAddress: clientInfoFrom(ctx).IPAddress,
+		Details:   &details,
+	})
+	log.Printf("[Auth] %s account unlinked from user %s", provider, userID)
+	return nil
+}
+
+// linkIdentity stores the identity for the user unless it belongs to someone else
+func (uc *AuthUsecase) linkIdentity(ctx context.Context, user *entities.User, input OAuthUserInput) (*entities.UserIdentity, error) {
+	provider := entities.OAuthProvider(input.Provider)
+
+	if existing, err := uc.identityRepo.GetByProviderUserID(ctx, provider, input.ProviderUserID); err == nil {
+		if existing.UserID == user.ID {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("this %s account is already linked to another user", provider)
+	}
+
+	identities, err := uc.identityRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked accounts: %w", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return nil, fmt.Errorf("a different %s account is already linked, unlink it first", provider)
+		}
+	}
+
+	now := time.Now()
+	identity := &entities.UserIdentity{
+		UserID:         user.ID,
+		Provider:       provider,
+		ProviderUserID: input.ProviderUserID,
+		Email:          strings.ToLower(strings.TrimSpace(input.Email)),
+		LastLoginAt:    &now,
+	}
+	if err := uc.identityRepo.Create(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link %s account: %w", provider, err)
+	}
+
+	details := string(provider)
+	uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+		EventType: entities.SecurityEventIdentityLinked,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		IPAddress: clientInfoFrom(ctx).IPAddress,
+		Details:   &details,
+	})
+	log.Printf("[Auth] %s account linked to user %s", provider, user.ID)
+	return identity, nil
+}
@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+)
+
+// getManagedUser loads a user for an admin action
+// Operator admins only see their own company's staff; anyone else is reported as not found
+func (uc *AuthUsecase) getManagedUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if operatorID, ok := repositories.OperatorScope(ctx); ok {
+		if user.OperatorID == nil || *user.OperatorID != operatorID {
+			return nil, errors.New("user not found")
+		}
+	}
+	return user, nil
+}
+
+// AssignOperator moves a staff account to an operator, or back to the platform when operatorID is nil
+// The user's sessions are signed out so their next token carries the new scope
+func (uc *AuthUsecase) AssignOperator(ctx context.Context, userID uuid.UUID, operatorID *uuid.UUID, actorID *uuid.UUID) (*entities.User, error) {
+	user, err := uc.getManagedUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entities.SameOperator(user.OperatorID, operatorID) {
+		user.PasswordHash = ""
+		return user, nil
+	}
+	if actorID != nil && *actorID == user.ID {
+		return nil, errors.New("cannot change your own operator")
+	}
+
+	target := "platform"
+	if operatorID != nil {
+		operator, err := uc.operatorRepo.GetByID(ctx, *operatorID)
+		if err != nil {
+			return nil, errors.New("operator not found")
+		}
+		if !operator.IsActive {
+			return nil, errors.New("operator is inactive")
+		}
+		target = operator.Code
+	}
+
+	user.OperatorID = operatorID
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update operator: %w", err)
+	}
+
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		log.Printf("[Auth] Failed to revoke sessions for user %s after operator change: %v", user.ID, err)
+	}
+
+	details := "moved to " + target
+	uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+		EventType: entities.SecurityEventOperatorChanged,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		ActorID:   actorID,
+		Details:   &details,
+	})
+	log.Printf("[Auth] User %s moved to %s by %v", user.ID, target, actorID)
+
+	user.PasswordHash = ""
+	return user, nil
+}
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// OutboxEventHandlers delivers the booking and payment side effects recorded in the outbox
+type OutboxEventHandlers struct {
+	bookingUsecase   *BookingUsecase
+	paymentUsecase   *PaymentUsecase
+	analyticsUsecase *AnalyticsUsecase
+}
+
+// NewOutboxEventHandlers creates the outbox event handlers
+func NewOutboxEventHandlers(
+	bookingUsecase *BookingUsecase,
+	paymentUsecase *PaymentUsecase,
+	analyticsUsecase *AnalyticsUsecase,
+) *OutboxEventHandlers {
+	return &OutboxEventHandlers{
+		bookingUsecase:   bookingUsecase,
+		paymentUsecase:   paymentUsecase,
+		analyticsUsecase: analyticsUsecase,
+	}
+}
+
+// Register wires every handled event type into the relay
+func (h *OutboxEventHandlers) Register(relay *services.OutboxRelay) {
+	relay.RegisterHandler(entities.OutboxEventTicketEmail, h.handleTicketEmail)
+	relay.RegisterHandler(entities.OutboxEventBookingInApp, h.handleBookingInApp)
+	relay.RegisterHandler(entities.OutboxEventPaymentReceipt, h.handlePaymentReceipt)
+	relay.RegisterHandler(entities.OutboxEventAnalyticsRefresh, h.handleAnalyticsRefresh)
+}
+
+func (h *OutboxEventHandlers) handleTicketEmail(ctx context.Context, event *entities.OutboxEvent) error {
+	payload, err := event.DecodePayload()
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return h.paymentUsecase.SendTicketEmails(ctx, payload.BookingID)
+}
+
+func (h *OutboxEventHandlers) handleBookingInApp(ctx context.Context, event *entities.OutboxEvent) error {
+	payload, err := event.DecodePayload()
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return h.bookingUsecase.CreateConfirmationNotification(ctx, payload.BookingID)
+}
+
+func (h *OutboxEventHandlers) handlePaymentReceipt(ctx context.Context, event *entities.OutboxEvent) error {
+	payload, err := event.DecodePayload()
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	if payload.PaymentID == nil {
+		return errors.New("payment receipt event has no payment_id")
+	}
+	return h.paymentUsecase.SendPaymentReceipt(ctx, *payload.PaymentID)
+}
+
+func (h *OutboxEventHandlers) handleAnalyticsRefresh(ctx context.Context, event *entities.OutboxEvent) error {
+	return h.analyticsUsecase.InvalidateCache(ctx)
+}
@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// hashResetToken returns the value stored for a reset token; the raw token only ever exists in the email
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset emails a single-use reset link if the address belongs to an active account
+// Unknown, inactive or throttled addresses are silently ignored so the endpoint cannot be used to probe accounts.
+// Failures after the account lookup are logged rather than returned for the same reason.
+func (uc *AuthUsecase) RequestPasswordReset(ctx context.Context, email, ipAddress string) error {
+	// Checked before the lookup so the outcome does not depend on whether the account exists
+	if uc.emailService == nil {
+		return errors.New("email service is not configured")
+	}
+
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil || !user.IsActive {
+		return nil
+	}
+
+	if latest, err := uc.passwordResetRepo.GetLatestForUser(ctx, user.ID); err == nil &&
+		time.Since(latest.CreatedAt) < uc.resendMinInterval {
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Printf("[Auth] Failed to generate reset token for user %s: %v", user.ID, err)
+		return nil
+	}
+	tokenString := base64.RawURLEncoding.EncodeToString(raw)
+
+	// Only the newest link works
+	if err := uc.passwordResetRepo.InvalidateAllForUser(ctx, user.ID); err != nil {
+		log.Printf("[Auth] Failed to invalidate previous reset tokens for user %s: %v", user.ID, err)
+		return nil
+	}
+
+	resetToken := &entities.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(tokenString),
+		ExpiresAt: time.Now().Add(uc.passwordResetTTL),
+		RequestIP: ipAddress,
+	}
+	if err := uc.passwordResetRepo.Create(ctx, resetToken); err != nil {
+		log.Printf("[Auth] Failed to store reset token for user %s: %v", user.ID, err)
+		return nil
+	}
+
+	// Send in the background so response time does not reveal whether the account exists
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", uc.frontendURL, url.QueryEscape(tokenString))
+	body := services.NewEmailTemplates().PasswordResetEmail(user.Name, resetURL, uc.passwordResetTTL)
+	go func(userID, toEmail, toName string) {
+		if err := uc.emailService.SendHTMLEmail(toEmail, toName, "Reset your password", body); err != nil {
+			log.Printf("[Auth] Failed to send password reset email to user %s: %v", userID, err)
+			return
+		}
+		log.Printf("[Auth] Password reset email sent to user %s", userID)
+	}(user.ID.String(), user.Email, user.Name)
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and revokes all existing sessions
+func (uc *AuthUsecase) ResetPassword(ctx context.Context, tokenString, newPassword string) error {
+	resetToken, err := uc.passwordResetRepo.GetByHash(ctx, hashResetToken(tokenString))
+	if err != nil || !resetToken.IsValid() {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, resetToken.UserID)
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+	if !user.IsActive {
+		return errors.New("account is inactive")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Consume the token before changing anything so it cannot be replayed concurrently
+	consumed, err := uc.passwordResetRepo.MarkUsed(ctx, resetToken.ID)
+	if err != nil {
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+	if !consumed {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user.PasswordHash = string(hashedPassword)
+	// Following the emailed link proves ownership of the address
+	if !user.EmailVerified {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("password updated but failed to revoke sessions: %w", err)
+	}
+
+	log.Printf("[Auth] Password reset for user %s", user.ID)
+	return nil
+}
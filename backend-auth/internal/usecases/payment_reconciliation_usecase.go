@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+// PaymentReconciliationUsecase compares pending payments with the payment provider
+// It catches payments whose webhook was lost and flags states that need manual review
+type PaymentReconciliationUsecase struct {
+	paymentRepo        repositories.PaymentRepository
+	bookingRepo        repositories.BookingRepository
+	reconciliationRepo repositories.PaymentReconciliationRepository
+	paymentProvider    services.PaymentProvider
+	paymentUsecase     *PaymentUsecase
+
+	running sync.Mutex // Scheduled and manual runs never overlap
+}
+
+// NewPaymentReconciliationUsecase creates a new payment reconciliation usecase
+func NewPaymentReconciliationUsecase(
+	paymentRepo repositories.PaymentRepository,
+	bookingRepo repositories.BookingRepository,
+	reconciliationRepo repositories.PaymentReconciliationRepository,
+	paymentProvider services.PaymentProvider,
+	paymentUsecase *PaymentUsecase,
+) *PaymentReconciliationUsecase {
+	return &PaymentReconciliationUsecase{
+		paymentRepo:        paymentRepo,
+		bookingRepo:        bookingRepo,
+		reconciliationRepo: reconciliationRepo,
+		paymentProvider:    paymentProvider,
+		paymentUsecase:     paymentUsecase,
+	}
+}
+
+// ReconciliationRunResult summarizes one reconciliation run
+type ReconciliationRunResult struct {
+	Checked  int `json:"checked"`
+	Skipped  int `json:"skipped"` // Payments without a gateway order code
+	Changed  int `json:"changed"`
+	Flagged  int `json:"flagged"`
+	Errors   int `json:"errors"`
+	Recorded int `json:"recorded"`
+}
+
+// ReconciliationReport is the reconciliation activity for one day
+type ReconciliationReport struct {
+	Date       string                            `json:"date"`
+	Completed  int                               `json:"completed"`
+	Failed     int                               `json:"failed"`
+	Cancelled  int                               `json:"cancelled"`
+	Expired    int                               `json:"expired"`
+	Flagged    int                               `json:"flagged"`
+	Errors     int                               `json:"errors"`
+	Mismatches int                               `json:"mismatches"`
+	Entries    []*entities.PaymentReconciliation `json:"entries"`
+}
+
+// amountTolerance absorbs rounding between our float amounts and the provider's integer amounts
+const amountTolerance = 0.5
+
+// ReconcilePendingPayments polls the provider for every pending payment and applies the
+// same transitions as webhook processing
+func (uc *PaymentReconciliationUsecase) ReconcilePendingPayments(ctx context.Context) (*ReconciliationRunResult, error) {
+	if !uc.running.TryLock() {
+		return nil, errors.New("reconciliation is already running")
+	}
+	defer uc.running.Unlock()
+
+	payments, err := uc.paymentRepo.GetPendingPayments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending payments: %w", err)
+	}
+
+	result := &ReconciliationRunResult{}
+	for _, payment := range payments {
+		if payment.ExternalOrderCode == nil || *payment.ExternalOrderCode == "" {
+			result.Skipped++
+			continue
+		}
+		result.Checked++
+
+		entry := uc.reconcilePayment(ctx, payment)
+		if entry == nil {
+			continue
+		}
+
+		switch {
+		case entry.Action == entities.ReconciliationActionError:
+			result.Errors++
+		case entry.Action == entities.ReconciliationActionFlagged:
+			result.Flagged++
+		case entry.Action != entities.ReconciliationActionNone:
+			result.Changed++
+		}
+		if entry.Mismatch && entry.Action != entities.ReconciliationActionFlagged {
+			result.Flagged++
+		}
+
+		if err := uc.reconciliationRepo.Record(ctx, entry); err != nil {
+			log.Printf("[Reconciliation] Failed to record result for payment %s: %v", payment.ID, err)
+			continue
+		}
+		result.Recorded++
+	}
+
+	if result.Checked > 0 {
+		log.Printf("[Reconciliation] Checked %d pending payments: %d changed, %d flagged, %d errors",
+			result.Checked, result.Changed, result.Flagged, result.Errors)
+	}
+	return result, nil
+}
+
+// reconcilePayment checks one payment against the provider
+// Returns nil when both sides agree and nothing needs recording
+func (uc *PaymentReconciliationUsecase) reconcilePayment(ctx context.Context, payment *entities.Payment) *entities.PaymentReconciliation {
+	entry := &entities.PaymentReconciliation{
+		PaymentID:   payment.ID,
+		BookingID:   payment.BookingID,
+		OrderCode:   *payment.ExternalOrderCode,
+		LocalStatus: string(payment.Status),
+		Amount:      payment.Amount,
+		CheckedAt:   time.Now(),
+		CheckCount:  1,
+	}
+
+	booking, err := uc.bookingRepo.GetByID(ctx, payment.BookingID)
+	if err != nil {
+		return withNote(entry, entities.ReconciliationActionError, false, fmt.Sprintf("booking lookup failed: %v", err))
+	}
+	entry.BookingStatus = string(booking.Status)
+
+	status, err := uc.paymentProvider.GetPaymentStatus(entry.OrderCode)
+	if err != nil {
+		return withNote(entry, entities.ReconciliationActionError, false, fmt.Sprintf("provider lookup failed: %v", err))
+	}
+	entry.ProviderStatus = status.Status
+	entry.ProviderAmount = status.Amount
+
+	bookingClosed := booking.Status == entities.BookingStatusExpired || booking.Status == entities.BookingStatusCancelled
+	amountMismatch := status.Amount > 0 && math.Abs(status.Amount-payment.Amount) > amountTolerance
+
+	switch status.Status {
+	case "PAID":
+		if bookingClosed {
+			// Seats may already be resold, so the booking is not revived. The payment is
+			// recorded as completed so it leaves the pending set and can be refunded.
+			now := time.Now()
+			payment.Status = entities.PaymentTransactionCompleted
+			payment.CompletedAt = &now
+			if err := uc.paymentRepo.Update(ctx, payment); err != nil {
+				return withNote(entry, entities.ReconciliationActionError, true, fmt.Sprintf("failed to record completed payment: %v", err))
+			}
+			return withNote(entry, entities.ReconciliationActionFlagged, true,
+				fmt.Sprintf("booking %s but payment completed at provider; refund or rebook required", booking.Status))
+		}
+		if amountMismatch {
+			return withNote(entry, entities.ReconciliationActionFlagged, true,
+				fmt.Sprintf("amount mismatch: local %.2f, provider %.2f; booking left pending", payment.Amount, status.Amount))
+		}
+		return uc.apply(ctx, entry, payment, status.Status, entities.ReconciliationActionCompleted, booking.Status == entities.BookingStatusConfirmed)
+
+	case "FAILED":
+		return uc.apply(ctx, entry, payment, status.Status, entities.ReconciliationActionFailed, booking.Status == entities.BookingStatusConfirmed)
+	case "CANCELLED":
+		return uc.apply(ctx, entry, payment, status.Status, entities.ReconciliationActionCancelled, booking.Status == entities.BookingStatusConfirmed)
+	case "EXPIRED":
+		return uc.apply(ctx, entry, payment, status.Status, entities.ReconciliationActionExpired, booking.Status == entities.BookingStatusConfirmed)
+
+	case "PENDING", "PROCESSING":
+		if booking.Status == entities.BookingStatusConfirmed {
+			return withNote(entry, entities.ReconciliationActionFlagged, true, "booking confirmed but payment still pending at provider")
+		}
+		return nil
+
+	default:
+		return withNote(entry, entities.ReconciliationActionFlagged, true, fmt.Sprintf("unknown provider status %q", status.Status))
+	}
+}
+
+// apply runs the webhook transition for the provider status and records the outcome
+func (uc *PaymentReconciliationUsecase) apply(ctx context.Context, entry *entities.PaymentReconciliation, payment *entities.Payment, providerStatus string, action entities.ReconciliationAction, mismatch bool) *entities.PaymentReconciliation {
+	if err := uc.paymentUsecase.applyPaymentEvent(ctx, payment, providerStatus); err != nil {
+		return withNote(entry, entities.ReconciliationActionError, mismatch, fmt.Sprintf("failed to apply %s: %v", providerStatus, err))
+	}
+
+	note := "webhook missing; status applied from provider"
+	if mismatch {
+		note = fmt.Sprintf("booking was %s while payment was pending; %s", entry.BookingStatus, note)
+	}
+	log.Printf("[Reconciliation] Payment %s: %s -> %s", payment.ID, entry.LocalStatus, providerStatus)
+	return withNote(entry, action, mismatch, note)
+}
+
+func withNote(entry *entities.PaymentReconciliation, action entities.ReconciliationAction, mismatch bool, note string) *entities.PaymentReconciliation {
+	entry.Action = action
+	entry.Mismatch = mismatch
+	entry.Note = &note
+	return entry
+}
+
+// GetDailyReport returns the reconciliation activity recorded on the given day
+func (uc *PaymentReconciliationUsecase) GetDailyReport(ctx context.Context, date time.Time, mismatchOnly bool) (*ReconciliationReport, error) {
+	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endDate := startDate.AddDate(0, 0, 1)
+
+	entries, err := uc.reconciliationRepo.GetByDateRange(ctx, startDate, endDate, mismatchOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation entries: %w", err)
+	}
+
+	report := &ReconciliationReport{
+		Date:    startDate.Format("2006-01-02"),
+		Entries: entries,
+	}
+	for _, entry := range entries {
+		switch entry.Action {
+		case entities.ReconciliationActionCompleted:
+			report.Completed++
+		case entities.ReconciliationActionFailed:
+			report.Failed++
+		case entities.ReconciliationActionCancelled:
+			report.Cancelled++
+		case entities.ReconciliationActionExpired:
+			report.Expired++
+		case entities.ReconciliationActionFlagged:
+			report.Flagged++
+		case entities.ReconciliationActionError:
+			report.Errors++
+		}
+		if entry.Mismatch {
+			report.Mismatches++
+		}
+	}
+	return report, nil
+}
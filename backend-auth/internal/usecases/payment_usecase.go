@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -27,6 +28,8 @@ type PaymentUsecase struct {
 	tripRepo      repositories.TripRepository
 	ticketService *services.TicketService
 	emailService  services.EmailProvider
+	verification  *EmailVerificationPolicy
+	funnel        *FunnelTracker
 }
 
 func NewPaymentUsecase(
@@ -42,6 +45,8 @@ func NewPaymentUsecase(
 	tripRepo repositories.TripRepository,
 	ticketService *services.TicketService,
 	emailService services.EmailProvider,
+	verification *EmailVerificationPolicy,
+	funnel *FunnelTracker,
 ) *PaymentUsecase {
 	return &PaymentUsecase{
 		paymentRepo:       paymentRepo,
@@ -56,6 +61,8 @@ func NewPaymentUsecase(
 		tripRepo:          tripRepo,
 		ticketService:     ticketService,
 		emailService:      emailService,
+		verification:      verification,
+		funnel:            funnel,
 	}
 }
 
@@ -92,6 +99,10 @@ func (uc *PaymentUsecase) CreatePayment(ctx context.Context, req CreatePaymentRe
 		return nil, fmt.Errorf("booking already paid")
 	}
 
+	if err := uc.verification.CheckPayment(ctx, booking.UserID); err != nil {
+		return nil, err
+	}
+
 	// Use booking's amount if not provided
 	amount := req.Amount
 	if amount == 0 {
@@ -124,6 +135,7 @@ func (uc *PaymentUsecase) CreatePayment(ctx context.Context, req CreatePaymentRe
 		Description:       req.Description,
 		InitiatedAt:       now,
 		ExpiresAt:         &expiresAt,
+		OperatorID:        booking.OperatorID,
 	}
 
 	// Save payment to DB first
@@ -171,6 +183,8 @@ func (uc *PaymentUsecase) CreatePayment(ctx context.Context, req CreatePaymentRe
 		log.Printf("Warning: Failed to update payment with external ID: %v", err)
 	}
 
+	uc.funnel.TrackBooking(ctx, entities.FunnelPaymentStarted, booking, "")
+
 	// AUTO-SUCCESS IN MOCK MODE: Immediately trigger payment success webhook
 	// This simulates instant payment success for testing
 	useMockPayment := os.Getenv("USE_MOCK_PAYMENT") == "true"
@@ -194,6 +208,14 @@ func (uc *PaymentUsecase) CreatePayment(ctx context.Context, req CreatePaymentRe
 	}, nil
 }
 
+// Webhook retry policy: failed logs are retried with exponential backoff
+// starting at webhookRetryBaseDelay and capped at webhookRetryMaxDelay
+const (
+	webhookMaxRetries     = 5
+	webhookRetryBaseDelay = time.Minute
+	webhookRetryMaxDelay  = time.Hour
+)
+
 // ProcessWebhook processes payment webhook from gateway
 // Handles PayOS webhook events and updates payment/booking status accordingly
 func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID, eventType, rawPayload, signature string) error {
@@ -205,7 +227,7 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 		EventType:         eventType,
 		RawPayload:        rawPayload,
 		Signature:         &signature,
-		ProcessedStatus:   "pending",
+		ProcessedStatus:   entities.WebhookStatusPending,
 	}
 
 	if err := uc.webhookLogRepo.Create(ctx, webhookLog); err != nil {
@@ -215,13 +237,91 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 
 	log.Printf("[Webhook] Webhook logged with ID: %s", webhookLog.ID)
 
+	return uc.processWebhookLog(ctx, webhookLog, true)
+}
+
+// RetryPendingWebhooks re-processes failed or stuck pending webhook logs whose backoff has elapsed
+// Run periodically by the background job scheduler
+func (uc *PaymentUsecase) RetryPendingWebhooks(ctx context.Context) error {
+	// Leave logs from the last minute alone, they may still be processing in a request
+	logs, err := uc.webhookLogRepo.GetPendingLogs(ctx, webhookMaxRetries, time.Now().Add(-time.Minute), 100)
+	if err != nil {
+		return fmt.Errorf("failed to get pending webhook logs: %w", err)
+	}
+
+	retried, failed := 0, 0
+	for _, webhookLog := range logs {
+		log.Printf("[Webhook] Retrying webhook log %s (attempt %d of %d)", webhookLog.ID, webhookLog.RetryCount+1, webhookMaxRetries)
+		if err := uc.processWebhookLog(ctx, webhookLog, true); err != nil {
+			failed++
+			continue
+		}
+		retried++
+	}
+
+	if len(logs) > 0 {
+		log.Printf("[Webhook] Retry run finished: %d processed, %d still failing", retried, failed)
+	}
+	return nil
+}
+
+// ListWebhookLogs returns a page of webhook logs matching the filter, newest first
+func (uc *PaymentUsecase) ListWebhookLogs(ctx context.Context, filter repositories.WebhookLogFilter, page, pageSize int) ([]*entities.PaymentWebhookLog, int64, error) {
+	return uc.webhookLogRepo.List(ctx, filter, page, pageSize)
+}
+
+// GetWebhookLog retrieves a webhook log by ID
+func (uc *PaymentUsecase) GetWebhookLog(ctx context.Context, id uuid.UUID) (*entities.PaymentWebhookLog, error) {
+	webhookLog, err := uc.webhookLogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook log not found: %w", err)
+	}
+	return webhookLog, nil
+}
+
+// ReplayWebhook re-runs a stored webhook payload through the event handlers
+// Logs rejected for a bad signature are never replayed. Processed and failed logs passed the
+// signature check on receipt and skip it, since provider signatures may no longer validate after
+// key rotation; pending and duplicate logs never reached the check, so it runs for them
+func (uc *PaymentUsecase) ReplayWebhook(ctx context.Context, id uuid.UUID) (*entities.PaymentWebhookLog, error) {
+	webhookLog, err := uc.GetWebhookLog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhookLog.ProcessedStatus == entities.WebhookStatusRejected {
+		return nil, errors.New("webhook failed signature verification and cannot be replayed")
+	}
+	verified := webhookLog.ProcessedStatus == entities.WebhookStatusProcessed ||
+		webhookLog.ProcessedStatus == entities.WebhookStatusFailed
+
+	log.Printf("[Webhook] Replaying webhook log %s (status: %s, event: %s)", webhookLog.ID, webhookLog.ProcessedStatus, webhookLog.EventType)
+
+	webhookLog.ProcessedStatus = entities.WebhookStatusPending
+	webhookLog.ErrorMessage = nil
+	webhookLog.NextRetryAt = nil
+	if err := uc.processWebhookLog(ctx, webhookLog, !verified); err != nil {
+		return webhookLog, err
+	}
+	return webhookLog, nil
+}
+
+// processWebhookLog runs a stored webhook log through duplicate detection, signature
+// verification and the event handlers, recording the outcome on the log
+func (uc *PaymentUsecase) processWebhookLog(ctx context.Context, webhookLog *entities.PaymentWebhookLog, verifySignature bool) error {
+	externalPaymentID := webhookLog.ExternalPaymentID
+	eventType := webhookLog.EventType
+
 	// 2. Check for duplicate webhooks
 	existingLogs, err := uc.webhookLogRepo.GetByExternalPaymentID(ctx, externalPaymentID)
 	if err == nil && len(existingLogs) > 1 {
 		for _, existingLog := range existingLogs {
-			if existingLog.ProcessedStatus == "processed" && existingLog.EventType == eventType {
+			if existingLog.ID == webhookLog.ID {
+				continue
+			}
+			if existingLog.ProcessedStatus == entities.WebhookStatusProcessed && existingLog.EventType == eventType {
 				log.Printf("[Webhook] Duplicate webhook detected for %s (event: %s), skipping", externalPaymentID, eventType)
-				webhookLog.ProcessedStatus = "duplicate"
+				webhookLog.ProcessedStatus = entities.WebhookStatusDuplicate
+				webhookLog.NextRetryAt = nil
 				uc.webhookLogRepo.Update(ctx, webhookLog)
 				return nil
 			}
@@ -231,15 +331,22 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 	// 3. Verify webhook signature
 	// Skip signature verification if signature is empty (development/mock mode)
 	// In production with real PayOS, signature will always be present
-	if signature != "" && signature != "mock-signature" {
-		verified, err := uc.paymentProvider.VerifyWebhookSignature([]byte(rawPayload), signature)
+	signature := ""
+	if webhookLog.Signature != nil {
+		signature = *webhookLog.Signature
+	}
+	if !verifySignature {
+		log.Printf("[Webhook] Signature verification skipped for replay of webhook log %s", webhookLog.ID)
+	} else if signature != "" && signature != "mock-signature" {
+		verified, err := uc.paymentProvider.VerifyWebhookSignature([]byte(webhookLog.RawPayload), signature)
 		if err != nil || !verified {
 			errMsg := "webhook signature verification failed"
 			log.Printf("[Webhook] Signature verification failed for payment %s: verified=%v, err=%v", externalPaymentID, verified, err)
-			webhookLog.ProcessedStatus = "failed"
+			webhookLog.ProcessedStatus = entities.WebhookStatusRejected
 			webhookLog.ErrorMessage = &errMsg
+			webhookLog.NextRetryAt = nil
 			uc.webhookLogRepo.Update(ctx, webhookLog)
-			return fmt.Errorf(errMsg)
+			return errors.New(errMsg)
 		}
 		log.Printf("[Webhook] Signature verified successfully for payment %s", externalPaymentID)
 	} else {
@@ -251,10 +358,8 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 	if err != nil {
 		errMsg := fmt.Sprintf("payment not found: %v", err)
 		log.Printf("[Webhook] Payment lookup failed for order code %s: %v", externalPaymentID, err)
-		webhookLog.ProcessedStatus = "failed"
-		webhookLog.ErrorMessage = &errMsg
-		uc.webhookLogRepo.Update(ctx, webhookLog)
-		return fmt.Errorf(errMsg)
+		uc.markWebhookFailed(ctx, webhookLog, errMsg)
+		return errors.New(errMsg)
 	}
 
 	log.Printf("[Webhook] Found payment %s for order code %s", payment.ID, externalPaymentID)
@@ -266,6 +371,31 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 	// 5. Process webhook based on event type
 	log.Printf("[Webhook] Processing event type: %s", eventType)
 
+	err = uc.applyPaymentEvent(ctx, payment, eventType)
+
+	// 6. Update webhook log status
+	if err != nil {
+		log.Printf("[Webhook] Error processing webhook: %v", err)
+		uc.markWebhookFailed(ctx, webhookLog, err.Error())
+		return err
+	}
+
+	webhookLog.ProcessedStatus = entities.WebhookStatusProcessed
+	now := time.Now()
+	webhookLog.ProcessedAt = &now
+	webhookLog.ErrorMessage = nil
+	webhookLog.NextRetryAt = nil
+	log.Printf("[Webhook] Webhook processed successfully for payment %s", payment.ID)
+
+	uc.webhookLogRepo.Update(ctx, webhookLog)
+
+	return nil
+}
+
+// applyPaymentEvent applies the payment/booking transition for a gateway event or status
+// Shared by webhook processing and provider reconciliation so both follow the same rules
+func (uc *PaymentUsecase) applyPaymentEvent(ctx context.Context, payment *entities.Payment, eventType string) error {
+	var err error
 	switch eventType {
 	case "payment.completed", "payment.success", "PAID":
 		err = uc.handlePaymentSuccess(ctx, payment)
@@ -288,24 +418,24 @@ func (uc *PaymentUsecase) ProcessWebhook(ctx context.Context, externalPaymentID,
 			err = nil
 		}
 	}
+	return err
+}
 
-	// 6. Update webhook log status
-	if err != nil {
-		log.Printf("[Webhook] Error processing webhook: %v", err)
-		webhookLog.ProcessedStatus = "failed"
-		errMsg := err.Error()
-		webhookLog.ErrorMessage = &errMsg
-		webhookLog.RetryCount++
+// markWebhookFailed records a processing failure and schedules the next retry with backoff
+func (uc *PaymentUsecase) markWebhookFailed(ctx context.Context, webhookLog *entities.PaymentWebhookLog, errMsg string) {
+	webhookLog.ProcessedStatus = entities.WebhookStatusFailed
+	webhookLog.ErrorMessage = &errMsg
+	webhookLog.RetryCount++
+
+	if webhookLog.RetryCount < webhookMaxRetries {
+		nextRetry := time.Now().Add(services.BackoffWithJitter(webhookRetryBaseDelay, webhookRetryMaxDelay, webhookLog.RetryCount))
+		webhookLog.NextRetryAt = &nextRetry
 	} else {
-		webhookLog.ProcessedStatus = "processed"
-		now := time.Now()
-		webhookLog.ProcessedAt = &now
-		log.Printf("[Webhook] Webhook processed successfully for payment %s", payment.ID)
+		webhookLog.NextRetryAt = nil
+		log.Printf("[Webhook] Webhook log %s gave up after %d attempts", webhookLog.ID, webhookLog.RetryCount)
 	}
 
 	uc.webhookLogRepo.Update(ctx, webhookLog)
-
-	return err
 }
 
 // handlePaymentSuccess handles successful payment webhook
@@ -325,14 +455,6 @@ func (uc *PaymentUsecase) handlePaymentSuccess(ctx context.Context, payment *ent
 	payment.WebhookReceivedAt = &now
 	payment.WebhookProcessedAt = &now
 
-	if err := uc.paymentRepo.Update(ctx, payment); err != nil {
-		log.Printf("[Payment] Error updating payment status: %v", err)
-		return fmt.Errorf("failed to update payment: %w", err)
-	}
-
-	log.Printf("[Payment] Payment status updated to completed")
-
-	// Update booking status
 	booking, err := uc.bookingRepo.GetByID(ctx, payment.BookingID)
 	if err != nil {
 		log.Printf("[Payment] Error fetching booking: %v", err)
@@ -347,20 +469,20 @@ func (uc *PaymentUsecase) handlePaymentSuccess(ctx context.Context, payment *ent
 	externalID := payment.ExternalPaymentID
 	booking.PaymentReference = &externalID
 
-	if err := uc.bookingRepo.Update(ctx, booking); err != nil {
-		log.Printf("[Payment] Error updating booking status: %v", err)
-		return fmt.Errorf("failed to update booking: %w", err)
+	// Receipt, tickets and analytics are recorded in the outbox and committed together with
+	// the status change, so they are delivered even if the process dies right after this call
+	events, err := paymentSuccessOutboxEvents(booking, payment)
+	if err != nil {
+		return fmt.Errorf("failed to build outbox events: %w", err)
 	}
 
-	log.Printf("[Payment] Booking %s status updated to confirmed", booking.BookingReference)
-
-	// Send payment receipt notification asynchronously
-	go uc.sendPaymentReceiptNotification(booking, payment)
-
-	// Send ticket emails asynchronously
-	go uc.sendTicketEmails(ctx, booking.ID)
+	if err := uc.paymentRepo.CompleteWithOutbox(ctx, payment, booking, events); err != nil {
+		log.Printf("[Payment] Error completing payment: %v", err)
+		return fmt.Errorf("failed to complete payment: %w", err)
+	}
 
 	log.Printf("[Payment] Payment %s processed successfully for booking %s", payment.ID, booking.BookingReference)
+	uc.funnel.TrackBooking(ctx, entities.FunnelPaymentCompleted, booking, "")
 
 	return nil
 }
@@ -403,64 +525,107 @@ func (uc *PaymentUsecase) handlePaymentExpiry(ctx context.Context, payment *enti
 	return uc.paymentRepo.Update(ctx, payment)
 }
 
-// sendPaymentReceiptNotification sends payment receipt via notification queue
-func (uc *PaymentUsecase) sendPaymentReceiptNotification(booking *entities.Booking, payment *entities.Payment) {
-	ctx := context.Background()
-
-	// Render notification template
-	subject, body, err := uc.templateEngine.RenderPaymentReceipt(services.PaymentReceiptData{
-		RecipientName:    booking.ContactName,
-		BookingReference: booking.BookingReference,
-		Amount:           payment.Amount,
-		TransactionID:    payment.ExternalPaymentID,
-		PaymentMethod:    string(payment.Method),
-		PaymentDate:      payment.CompletedAt.Format("January 2, 2006 at 3:04 PM"),
-	})
-
+// SendPaymentReceipt sends the payment receipt via notification queue and creates the in-app notification
+// Safe to call more than once: receipts already created for the booking are not duplicated
+func (uc *PaymentUsecase) SendPaymentReceipt(ctx context.Context, paymentID uuid.UUID) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, paymentID)
 	if err != nil {
-		log.Printf("Failed to render payment receipt template: %v", err)
-		return
+		return fmt.Errorf("failed to get payment: %w", err)
 	}
-
-	// Create notification
-	notification := &entities.Notification{
-		UserID:         booking.UserID,
-		BookingID:      &booking.ID,
-		Type:           entities.NotificationTypePaymentReceipt,
-		Channel:        entities.NotificationChannelEmail,
-		Status:         entities.NotificationStatusPending,
-		RecipientEmail: &booking.ContactEmail,
-		RecipientName:  booking.ContactName,
-		Subject:        subject,
-		Body:           body,
-		HTMLBody:       &body,
+	booking, err := uc.bookingRepo.GetByID(ctx, payment.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
 	}
 
-	if err := uc.notificationRepo.Create(ctx, notification); err != nil {
-		log.Printf("Failed to create payment receipt notification: %v", err)
-		return
+	existing, err := uc.notificationRepo.GetByBookingID(ctx, booking.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking notifications: %w", err)
 	}
+	var hasEmail, hasInApp bool
+	for _, n := range existing {
+		if n.Type != entities.NotificationTypePaymentReceipt {
+			continue
+		}
+		switch n.Channel {
+		case entities.NotificationChannelEmail:
+			hasEmail = true
+		case entities.NotificationChannelInApp:
+			hasInApp = true
+		}
+	}
+
+	if !hasEmail {
+		completedAt := time.Now()
+		if payment.CompletedAt != nil {
+			completedAt = *payment.CompletedAt
+		}
 
-	// Enqueue for sending
-	if err := uc.notificationQueue.Enqueue(notification); err != nil {
-		log.Printf("Failed to enqueue payment receipt notification: %v", err)
+		// Render notification template
+		rendered, err := uc.templateEngine.RenderPaymentReceipt(booking.Locale, services.PaymentReceiptData{
+			RecipientName:    booking.ContactName,
+			BookingReference: booking.BookingReference,
+			Amount:           payment.Amount,
+			TransactionID:    payment.ExternalPaymentID,
+			PaymentMethod:    string(payment.Method),
+			PaymentDate:      completedAt.Format("January 2, 2006 at 3:04 PM"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render payment receipt template: %w", err)
+		}
+
+		// The text body goes in Body as the plain-text alternative; the compiled-in template has none
+		body := rendered.TextBody
+		if body == "" {
+			body = rendered.HTMLBody
+		}
+
+		// Create notification
+		notification := &entities.Notification{
+			UserID:         booking.UserID,
+			BookingID:      &booking.ID,
+			Type:           entities.NotificationTypePaymentReceipt,
+			Channel:        entities.NotificationChannelEmail,
+			Status:         entities.NotificationStatusPending,
+			RecipientEmail: &booking.ContactEmail,
+			RecipientName:  booking.ContactName,
+			Subject:        rendered.Subject,
+			Body:           body,
+			HTMLBody:       &rendered.HTMLBody,
+		}
+
+		if err := uc.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("failed to create payment receipt notification: %w", err)
+		}
+
+		// Enqueue for sending; a pending notification left behind is picked up by the scheduler
+		if err := uc.notificationQueue.Enqueue(notification); err != nil {
+			log.Printf("Failed to enqueue payment receipt notification: %v", err)
+		}
 	}
 
-	// Create in-app notification as well
-	inAppNotification := &entities.Notification{
-		UserID:    booking.UserID,
-		BookingID: &booking.ID,
-		Type:      entities.NotificationTypePaymentReceipt,
-		Channel:   entities.NotificationChannelInApp,
-		Status:    entities.NotificationStatusSent,
-		Subject:   "Payment Successful",
-		Body:      fmt.Sprintf("Your payment of %s đ has been processed successfully", fmt.Sprintf("%.0f", payment.Amount)),
+	if !hasInApp {
+		// Create in-app notification as well
+		inAppNotification := &entities.Notification{
+			UserID:    booking.UserID,
+			BookingID: &booking.ID,
+			Type:      entities.NotificationTypePaymentReceipt,
+			Channel:   entities.NotificationChannelInApp,
+			Status:    entities.NotificationStatusSent,
+			Subject:   "Payment Successful",
+			Body:      fmt.Sprintf("Your payment of %s đ has been processed successfully", fmt.Sprintf("%.0f", payment.Amount)),
+		}
+		if err := uc.notificationRepo.Create(ctx, inAppNotification); err != nil {
+			return fmt.Errorf("failed to create in-app payment notification: %w", err)
+		}
 	}
-	uc.notificationRepo.Create(ctx, inAppNotification)
+
+	return nil
 }
 
-// sendTicketEmails sends e-ticket emails to passengers after payment success
-func (uc *PaymentUsecase) sendTicketEmails(ctx context.Context, bookingID uuid.UUID) error {
+// SendTicketEmails sends e-ticket emails to passengers after payment success
+// Returns an error if any ticket could not be sent so the caller can retry; tickets already
+// delivered are marked and skipped, so a retry only re-sends the ones that failed
+func (uc *PaymentUsecase) SendTicketEmails(ctx context.Context, bookingID uuid.UUID) error {
 	log.Printf("[TicketEmail] Starting ticket email sending for booking: %s", bookingID)
 
 	// Get booking with all details
@@ -494,37 +659,69 @@ func (uc *PaymentUsecase) sendTicketEmails(ctx context.Context, bookingID uuid.U
 	log.Printf("[TicketEmail] Found %d tickets to send", len(tickets))
 
 	// Send email for each ticket
+	failed := 0
 	for i, ticket := range tickets {
 		if i >= len(passengers) {
 			break
 		}
 		passenger := passengers[i]
+		if ticket.EmailSentAt != nil {
+			continue
+		}
 
 		// Generate PDF for this ticket
 		pdfBytes, err := uc.ticketService.GenerateTicketPDF(ticket, booking, trip, passenger)
 		if err != nil {
 			log.Printf("[TicketEmail] Failed to generate PDF for ticket %s: %v", ticket.TicketNumber, err)
+			failed++
 			continue
 		}
 
 		// Send email with PDF attachment
-		if err := uc.emailService.SendTicketEmail(
-			booking.ContactEmail,
-			booking.ContactName,
-			booking.BookingReference,
-			ticket.TicketNumber,
-			pdfBytes,
-		); err != nil {
+		if err := uc.templateEngine.SendTicketEmail(booking.Locale, booking.ContactEmail, services.TicketEmailData{
+			RecipientName:    booking.ContactName,
+			BookingReference: booking.BookingReference,
+			TicketNumber:     ticket.TicketNumber,
+		}, pdfBytes); err != nil {
 			log.Printf("[TicketEmail] Failed to send email for ticket %s: %v", ticket.TicketNumber, err)
-		} else {
-			log.Printf("[TicketEmail] Successfully sent ticket email for %s", ticket.TicketNumber)
+			failed++
+			continue
+		}
+		log.Printf("[TicketEmail] Successfully sent ticket email for %s", ticket.TicketNumber)
+
+		if err := uc.ticketRepo.MarkEmailSent(ctx, ticket.ID); err != nil {
+			// The email went out; a retry re-sends this ticket only if the mark is lost
+			log.Printf("[TicketEmail] Failed to mark ticket %s as sent: %v", ticket.TicketNumber, err)
 		}
 	}
 
 	log.Printf("[TicketEmail] Completed ticket email sending for booking: %s", bookingID)
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d ticket emails", failed, len(tickets))
+	}
 	return nil
 }
 
+// paymentSuccessOutboxEvents builds the side effects of a completed payment
+func paymentSuccessOutboxEvents(booking *entities.Booking, payment *entities.Payment) ([]*entities.OutboxEvent, error) {
+	payload := entities.OutboxPayload{BookingID: booking.ID, PaymentID: &payment.ID, TripID: &booking.TripID}
+	specs := []struct{ eventType, dedupKey string }{
+		{entities.OutboxEventPaymentReceipt, fmt.Sprintf("payment:%s:receipt", payment.ID)},
+		{entities.OutboxEventTicketEmail, fmt.Sprintf("booking:%s:confirmed:ticket_email", booking.ID)},
+		{entities.OutboxEventAnalyticsRefresh, fmt.Sprintf("booking:%s:confirmed:analytics", booking.ID)},
+	}
+
+	events := make([]*entities.OutboxEvent, 0, len(specs))
+	for _, spec := range specs {
+		event, err := entities.NewOutboxEvent(spec.eventType, "payment", payment.ID, spec.dedupKey, payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 // GetPaymentByBookingID retrieves payment(s) for a booking
 func (uc *PaymentUsecase) GetPaymentByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*entities.Payment, error) {
 	return uc.paymentRepo.GetByBookingID(ctx, bookingID)
@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+	"github.com/yourusername/bus-booking-auth/internal/repositories"
+	"github.com/yourusername/bus-booking-auth/internal/services"
+)
+
+const (
+	erasureBatchSize   = 20 // Requests handled per job run
+	erasureMaxAttempts = 5  // Failed runs before a request is left for manual follow-up
+	exportPageSize     = 100
+	exportMaxItems     = 10000 // Notifications included in an export
+)
+
+// PrivacyUsecase handles personal data export and self-service account erasure
+type PrivacyUsecase struct {
+	userRepo             repositories.UserRepository
+	identityRepo         repositories.UserIdentityRepository
+	refreshTokenRepo     repositories.RefreshTokenRepository
+	erasureRepo          repositories.AccountErasureRepository
+	bookingRepo          repositories.BookingRepository
+	paymentRepo          repositories.PaymentRepository
+	reviewRepo           repositories.ReviewRepository
+	notificationRepo     repositories.NotificationRepository
+	notificationPrefRepo repositories.NotificationPreferenceRepository
+	emailService         services.EmailProvider
+
+	frontendURL       string
+	confirmTTL        time.Duration
+	resendMinInterval time.Duration
+}
+
+// NewPrivacyUsecase creates a new privacy usecase
+func NewPrivacyUsecase(
+	userRepo repositories.UserRepository,
+	identityRepo repositories.UserIdentityRepository,
+	refreshTokenRepo repositories.RefreshTokenRepository,
+	erasureRepo repositories.AccountErasureRepository,
+	bookingRepo repositories.BookingRepository,
+	paymentRepo repositories.PaymentRepository,
+	reviewRepo repositories.ReviewRepository,
+	notificationRepo repositories.NotificationRepository,
+	notificationPrefRepo repositories.NotificationPreferenceRepository,
+	emailService services.EmailProvider,
+) *PrivacyUsecase {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:5173"
+	}
+
+	confirmTTL, err := time.ParseDuration(os.Getenv("ACCOUNT_ERASURE_CONFIRM_TTL"))
+	if err != nil || confirmTTL <= 0 {
+		confirmTTL = 24 * time.Hour
+	}
+
+	return &PrivacyUsecase{
+		userRepo:             userRepo,
+		identityRepo:         identityRepo,
+		refreshTokenRepo:     refreshTokenRepo,
+		erasureRepo:          erasureRepo,
+		bookingRepo:          bookingRepo,
+		paymentRepo:          paymentRepo,
+		reviewRepo:           reviewRepo,
+		notificationRepo:     notificationRepo,
+		notificationPrefRepo: notificationPrefRepo,
+		emailService:         emailService,
+		frontendURL:          frontendURL,
+		confirmTTL:           confirmTTL,
+		resendMinInterval:    time.Minute,
+	}
+}
+
+// UserDataExport is everything stored about a user, one field per file in the export archive
+type UserDataExport struct {
+	ExportedAt              time.Time                        `json:"exported_at"`
+	Profile                 *entities.User                   `json:"profile"`
+	LinkedAccounts          []*entities.UserIdentity         `json:"linked_accounts"`
+	NotificationPreferences *entities.NotificationPreference `json:"notification_preferences,omitempty"`
+	Bookings                []*entities.Booking              `json:"bookings"`
+	Passengers              []entities.Passenger             `json:"passengers"`
+	Payments                []*entities.Payment              `json:"payments"`
+	Reviews                 []*entities.Review               `json:"reviews"`
+	Notifications           []*entities.Notification         `json:"notifications"`
+}
+
+// ExportUserData collects the user's profile, bookings, passengers, payments, reviews and notifications
+func (uc *PrivacyUsecase) ExportUserData(ctx context.Context, userID uuid.UUID) (*UserDataExport, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	export := &UserDataExport{
+		ExportedAt:     time.Now().UTC(),
+		Profile:        user,
+		LinkedAccounts: []*entities.UserIdentity{},
+		Bookings:       []*entities.Booking{},
+		Passengers:     []entities.Passenger{},
+		Payments:       []*entities.Payment{},
+		Reviews:        []*entities.Review{},
+		Notifications:  []*entities.Notification{},
+	}
+
+	if identities, err := uc.identityRepo.ListByUser(ctx, userID); err == nil {
+		export.LinkedAccounts = identities
+	}
+	if prefs, err := uc.notificationPrefRepo.GetByUserID(ctx, userID); err == nil {
+		export.NotificationPreferences = prefs
+	}
+
+	for page := 1; ; page++ {
+		bookings, total, err := uc.bookingRepo.GetByUserID(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bookings: %w", err)
+		}
+		export.Bookings = append(export.Bookings, bookings...)
+		if len(bookings) == 0 || int64(len(export.Bookings)) >= total {
+			break
+		}
+	}
+
+	for _, booking := range export.Bookings {
+		export.Passengers = append(export.Passengers, booking.Passengers...)
+
+		payments, err := uc.paymentRepo.GetByBookingID(ctx, booking.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get payments: %w", err)
+		}
+		for _, payment := range payments {
+			payment.Booking = nil
+		}
+		export.Payments = append(export.Payments, payments...)
+	}
+
+	reviews, err := uc.reviewRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	export.Reviews = append(export.Reviews, reviews...)
+
+	notifications, err := uc.notificationRepo.GetByUserID(ctx, userID, exportMaxItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	export.Notifications = append(export.Notifications, notifications...)
+
+	return export, nil
+}
+
+// GetErasureStatus returns the user's most recent deletion request
+func (uc *PrivacyUsecase) GetErasureStatus(ctx context.Context, userID uuid.UUID) (*entities.AccountErasureRequest, error) {
+	request, err := uc.erasureRepo.GetLatestForUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("no account deletion request found")
+	}
+	return request, nil
+}
+
+// RequestErasure emails the user a link to confirm deleting their account
+// Nothing changes until the link is used, so a stolen session alone cannot delete the account
+func (uc *PrivacyUsecase) RequestErasure(ctx context.Context, userID uuid.UUID) (*entities.AccountErasureRequest, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if latest, err := uc.erasureRepo.GetLatestForUser(ctx, userID); err == nil {
+		if latest.Status == entities.AccountErasureConfirmed {
+			return nil, errors.New("account deletion is already in progress")
+		}
+		if latest.Status == entities.AccountErasurePending && time.Since(latest.CreatedAt) < uc.resendMinInterval {
+			return nil, errors.New("a confirmation email was sent recently, please wait before requesting another")
+		}
+	}
+
+	if uc.emailService == nil {
+		return nil, errors.New("email service is not configured")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	tokenString := base64.RawURLEncoding.EncodeToString(raw)
+
+	request := &entities.AccountErasureRequest{
+		UserID:    userID,
+		TokenHash: hashResetToken(tokenString),
+		Status:    entities.AccountErasurePending,
+		ExpiresAt: time.Now().Add(uc.confirmTTL),
+		RequestIP: clientInfoFrom(ctx).IPAddress,
+	}
+	if err := uc.erasureRepo.Create(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to store deletion request: %w", err)
+	}
+
+	confirmURL := fmt.Sprintf("%s/account/delete/confirm?token=%s", uc.frontendURL, url.QueryEscape(tokenString))
+	body := services.NewEmailTemplates().AccountErasureEmail(user.Name, confirmURL, uc.confirmTTL)
+	go func(userID, toEmail, toName string) {
+		if err := uc.emailService.SendHTMLEmail(toEmail, toName, "Confirm your account deletion", body); err != nil {
+			log.Printf("[Privacy] Failed to send deletion confirmation to user %s: %v", userID, err)
+			return
+		}
+		log.Printf("[Privacy] Deletion confirmation sent to user %s", userID)
+	}(user.ID.String(), user.Email, user.Name)
+
+	return request, nil
+}
+
+// ConfirmErasure accepts the emailed token, deactivates the account and queues it for the erasure job
+func (uc *PrivacyUsecase) ConfirmErasure(ctx context.Context, tokenString string) (*entities.AccountErasureRequest, error) {
+	request, err := uc.erasureRepo.GetByTokenHash(ctx, hashResetToken(tokenString))
+	if err != nil || request.Status != entities.AccountErasurePending {
+		return nil, errors.New("invalid or already used confirmation link")
+	}
+	if time.Now().After(request.ExpiresAt) {
+		return nil, errors.New("confirmation link has expired, please request account deletion again")
+	}
+
+	// Only the newest link confirms
+	if latest, err := uc.erasureRepo.GetLatestForUser(ctx, request.UserID); err == nil && latest.ID != request.ID {
+		return nil, errors.New("invalid or already used confirmation link")
+	}
+
+	now := time.Now()
+	request.Status = entities.AccountErasureConfirmed
+	request.ConfirmedAt = &now
+	if err := uc.erasureRepo.Update(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to confirm deletion request: %w", err)
+	}
+
+	// Lock the account right away; the job anonymizes the data shortly after
+	if err := uc.userRepo.SetActive(ctx, request.UserID, false); err != nil {
+		log.Printf("[Privacy] Failed to deactivate user %s: %v", request.UserID, err)
+	}
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, request.UserID); err != nil {
+		log.Printf("[Privacy] Failed to revoke sessions for user %s: %v", request.UserID, err)
+	}
+
+	log.Printf("[Privacy] Account deletion confirmed for user %s", request.UserID)
+	return request, nil
+}
+
+// ProcessConfirmedErasures anonymizes the accounts of confirmed deletion requests
+// Failed requests are retried on the next run until erasureMaxAttempts is reached
+func (uc *PrivacyUsecase) ProcessConfirmedErasures(ctx context.Context) error {
+	requests, err := uc.erasureRepo.GetConfirmed(ctx, erasureBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get confirmed deletion requests: %w", err)
+	}
+
+	for _, request := range requests {
+		if err := uc.erasureRepo.EraseUserData(ctx, request.UserID, time.Now()); err != nil {
+			request.Attempts++
+			message := err.Error()
+			request.LastError = &message
+			if request.Attempts >= erasureMaxAttempts {
+				request.Status = entities.AccountErasureFailed
+			}
+			log.Printf("[Privacy] Failed to erase user %s (attempt %d): %v", request.UserID, request.Attempts, err)
+		} else {
+			now := time.Now()
+			request.Status = entities.AccountErasureCompleted
+			request.CompletedAt = &now
+			request.LastError = nil
+			log.Printf("[Privacy] Personal data erased for user %s", request.UserID)
+		}
+
+		if err := uc.erasureRepo.Update(ctx, request); err != nil {
+			log.Printf("[Privacy] Failed to update deletion request %s: %v", request.ID, err)
+		}
+	}
+	return nil
+}
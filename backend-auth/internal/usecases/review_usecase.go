@@ -58,8 +58,11 @@ func (u *ReviewUsecase) CreateReview(ctx context.Context, userID uuid.UUID, inpu
 		return nil, errors.New("booking not found")
 	}
 
-	// Check if the booking belongs to this user
-	if booking.UserID != nil && *booking.UserID != userID {
+	// Check if the booking belongs to this user; guest bookings must be claimed into the account first
+	if booking.UserID == nil {
+		return nil, errors.New("booking does not belong to this user, add your guest booking to your account first")
+	}
+	if *booking.UserID != userID {
 		return nil, errors.New("booking does not belong to this user")
 	}
 
@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+)
+
+// ListRoles returns the assignable roles and the permissions each one grants
+func (uc *AuthUsecase) ListRoles() []entities.RoleDefinition {
+	return entities.RoleDefinitions()
+}
+
+// AssignRole changes a user's role on behalf of an admin
+// The user's sessions are signed out so the new permissions apply from their next login
+func (uc *AuthUsecase) AssignRole(ctx context.Context, userID uuid.UUID, role entities.Role, actorID *uuid.UUID) (*entities.User, error) {
+	if !role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", role)
+	}
+
+	user, err := uc.getManagedUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		user.PasswordHash = ""
+		return user, nil
+	}
+	if actorID != nil && *actorID == user.ID {
+		return nil, errors.New("cannot change your own role")
+	}
+
+	// The platform and every operator keep at least one admin
+	if user.Role == entities.RoleAdmin && user.IsActive {
+		admins, err := uc.userRepo.GetByRole(ctx, entities.RoleAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check admin accounts: %w", err)
+		}
+		active := 0
+		for _, admin := range admins {
+			if admin.IsActive && entities.SameOperator(admin.OperatorID, user.OperatorID) {
+				active++
+			}
+		}
+		if active <= 1 {
+			return nil, errors.New("cannot remove the last active admin")
+		}
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		log.Printf("[Auth] Failed to revoke sessions for user %s after role change: %v", user.ID, err)
+	}
+
+	details := fmt.Sprintf("%s -> %s", previous, role)
+	uc.recordSecurityEvent(ctx, &entities.SecurityEvent{
+		EventType: entities.SecurityEventRoleChanged,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		ActorID:   actorID,
+		Details:   &details,
+	})
+	log.Printf("[Auth] Role of user %s changed from %s to %s by %v", user.ID, previous, role, actorID)
+
+	user.PasswordHash = ""
+	return user, nil
+}
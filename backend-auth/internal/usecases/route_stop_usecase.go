@@ -98,14 +98,17 @@ func (u *RouteStopUsecase) GetRouteWithStops(ctx context.Context, routeID uuid.U
 
 // UpdateStop updates a route stop
 func (u *RouteStopUsecase) UpdateStop(ctx context.Context, stop *entities.RouteStop) error {
-	// Validate stop exists
+	// Validate stop exists on the route it is updated through; stops cannot be moved between routes
 	existingStop, err := u.routeStopRepo.GetByID(ctx, stop.ID)
 	if err != nil {
 		return fmt.Errorf("stop not found: %w", err)
 	}
+	if existingStop.RouteID != stop.RouteID {
+		return fmt.Errorf("stop not found on this route")
+	}
 
-	// Validate route exists
-	_, err = u.routeRepo.GetByID(ctx, stop.RouteID)
+	// Validate route exists and belongs to the caller's operator
+	_, err = u.routeRepo.GetByID(ctx, existingStop.RouteID)
 	if err != nil {
 		return fmt.Errorf("route not found: %w", err)
 	}
@@ -129,12 +132,20 @@ func (u *RouteStopUsecase) UpdateStop(ctx context.Context, stop *entities.RouteS
 }
 
 // DeleteStop deletes a route stop with validation
-func (u *RouteStopUsecase) DeleteStop(ctx context.Context, stopID uuid.UUID) error {
+func (u *RouteStopUsecase) DeleteStop(ctx context.Context, routeID, stopID uuid.UUID) error {
 	// Get the stop
 	stop, err := u.routeStopRepo.GetByID(ctx, stopID)
 	if err != nil {
 		return fmt.Errorf("stop not found: %w", err)
 	}
+	if stop.RouteID != routeID {
+		return fmt.Errorf("stop not found on this route")
+	}
+
+	// Validate route exists and belongs to the caller's operator
+	if _, err := u.routeRepo.GetByID(ctx, stop.RouteID); err != nil {
+		return fmt.Errorf("route not found: %w", err)
+	}
 
 	// Check if this is the last stop of its type
 	count, err := u.routeStopRepo.CountByRouteIDAndType(ctx, stop.RouteID, stop.Type)
@@ -37,6 +37,9 @@ type CreateSeatMapInput struct {
 	Rows        int     `json:"rows" binding:"required,min=1,max=20"`
 	Columns     int     `json:"columns" binding:"required,min=2,max=6"`
 	BusType     string  `json:"bus_type" binding:"required"`
+
+	// Owning operator when created by platform staff; operator staff always create for their own
+	OperatorID *uuid.UUID `json:"operator_id"`
 }
 
 // CreateSeatMap creates a new seat map with default seats
@@ -52,6 +55,7 @@ func (u *SeatMapUsecase) CreateSeatMap(ctx context.Context, input CreateSeatMapI
 		TotalSeats:  totalSeats,
 		BusType:     input.BusType,
 		IsActive:    true,
+		OperatorID:  input.OperatorID,
 	}
 
 	// Create the seat map first
@@ -325,6 +329,11 @@ func (u *SeatMapUsecase) RegenerateSeatLayout(ctx context.Context, seatMapID uui
 	return u.seatMapRepo.GetWithSeats(ctx, seatMapID)
 }
 
+// GetBus returns a bus by ID
+func (u *SeatMapUsecase) GetBus(ctx context.Context, busID uuid.UUID) (*entities.Bus, error) {
+	return u.busRepo.GetByID(ctx, busID)
+}
+
 // AssignSeatMapToBus assigns a seat map template to a bus
 func (u *SeatMapUsecase) AssignSeatMapToBus(ctx context.Context, busID, seatMapID uuid.UUID) (*entities.Bus, error) {
 	// Verify seat map exists
@@ -338,6 +347,9 @@ func (u *SeatMapUsecase) AssignSeatMapToBus(ctx context.Context, busID, seatMapI
 	if err != nil {
 		return nil, fmt.Errorf("bus not found: %w", err)
 	}
+	if !entities.SameOperator(seatMap.OperatorID, bus.OperatorID) {
+		return nil, fmt.Errorf("seat map belongs to a different operator than the bus")
+	}
 
 	// Update bus with seat map
 	bus.SeatMapID = &seatMapID
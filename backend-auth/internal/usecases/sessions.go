@@ -0,0 +1,344 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/bus-booking-auth/internal/entities"
+)
+
+// refreshReuseGrace tolerates parallel refreshes from one client: a token rotated this recently
+// gets a new access token instead of being treated as stolen. The successor refresh token is never
+// returned, so a copied token cannot take over the session.
+const refreshReuseGrace = 10 * time.Second
+
+// sessionCheckTTL bounds how long an instance trusts a session it found signed in, and so how long
+// access tokens of a session revoked elsewhere keep working there
+const sessionCheckTTL = 30 * time.Second
+
+// sessionStateCache remembers recent session lookups so authenticating a request rarely hits the database
+type sessionStateCache struct {
+	mu     sync.Mutex
+	states map[uuid.UUID]sessionState
+}
+
+type sessionState struct {
+	active    bool
+	expiresAt time.Time
+}
+
+func newSessionStateCache() *sessionStateCache {
+	return &sessionStateCache{states: make(map[uuid.UUID]sessionState)}
+}
+
+func (c *sessionStateCache) get(id uuid.UUID) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.states[id]
+	if !ok || time.Now().After(state.expiresAt) {
+		return false, false
+	}
+	return state.active, true
+}
+
+func (c *sessionStateCache) set(id uuid.UUID, active bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, state := range c.states {
+		if now.After(state.expiresAt) {
+			delete(c.states, key)
+		}
+	}
+	c.states[id] = sessionState{active: active, expiresAt: now.Add(sessionCheckTTL)}
+}
+
+// ClientInfo describes the device making an authentication request
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo attaches the requesting device to the context so new sessions can record it
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func clientInfoFrom(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+// issueTokens starts a device session for a user who has passed every login step
+func (uc *AuthUsecase) issueTokens(ctx context.Context, user *entities.User) (*AuthTokens, error) {
+	client := clientInfoFrom(ctx)
+	now := time.Now()
+	session := &entities.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceName: describeDevice(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(uc.refreshTokenExpiry),
+	}
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	refreshToken, err := uc.generateRefreshToken(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.refreshTokenRepo.Create(ctx, &entities.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Token:     refreshToken,
+		ExpiresAt: session.ExpiresAt,
+		SessionID: &session.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	accessToken, err := uc.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove password from response
+	user.PasswordHash = ""
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+// rotateRefreshToken replaces a valid refresh token with a new one in the same session
+func (uc *AuthUsecase) rotateRefreshToken(ctx context.Context, user *entities.User, current *entities.RefreshToken) (*AuthTokens, error) {
+	client := clientInfoFrom(ctx)
+	expiresAt := time.Now().Add(uc.refreshTokenExpiry)
+
+	// Tokens issued before session tracking join a new session on their first refresh
+	if current.SessionID == nil {
+		session := &entities.Session{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			DeviceName: describeDevice(client.UserAgent),
+			IPAddress:  client.IPAddress,
+			UserAgent:  client.UserAgent,
+			LastUsedAt: time.Now(),
+			ExpiresAt:  expiresAt,
+		}
+		if err := uc.sessionRepo.Create(ctx, session); err != nil {
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		current.SessionID = &session.ID
+	} else {
+		session, err := uc.sessionRepo.GetByID(ctx, *current.SessionID)
+		if err != nil || session.RevokedAt != nil {
+			return nil, errors.New("session has been signed out, please log in again")
+		}
+	}
+
+	newRefreshToken, err := uc.generateRefreshToken(user)
+	if err != nil {
+		return nil, err
+	}
+	next := &entities.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Token:     newRefreshToken,
+		ExpiresAt: expiresAt,
+		SessionID: current.SessionID,
+	}
+
+	rotated, err := uc.refreshTokenRepo.Rotate(ctx, current, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// A concurrent refresh rotated the token first
+		latest, err := uc.refreshTokenRepo.GetByID(ctx, current.ID)
+		if err != nil || latest.RotatedAt == nil {
+			return nil, errors.New("refresh token is revoked or expired")
+		}
+		return uc.handleRotatedRefreshToken(ctx, latest)
+	}
+
+	if err := uc.sessionRepo.Touch(ctx, *current.SessionID, client.IPAddress, expiresAt); err != nil {
+		log.Printf("[Auth] Failed to update session %s: %v", *current.SessionID, err)
+	}
+
+	accessToken, err := uc.generateAccessToken(user, *current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		User:         user,
+	}, nil
+}
+
+// handleRotatedRefreshToken deals with a refresh token that has already been replaced
+func (uc *AuthUsecase) handleRotatedRefreshToken(ctx context.Context, stored *entities.RefreshToken) (*AuthTokens, error) {
+	if time.Since(*stored.RotatedAt) <= refreshReuseGrace && stored.ReplacedByID != nil {
+		successor, err := uc.refreshTokenRepo.GetByID(ctx, *stored.ReplacedByID)
+		if err == nil && successor.RotatedAt == nil && successor.IsValid() {
+			user, err := uc.getSessionUser(ctx, successor.UserID)
+			if err != nil {
+				return nil, err
+			}
+			sessionID := uuid.Nil
+			if successor.SessionID != nil {
+				sessionID = *successor.SessionID
+			}
+			accessToken, err := uc.generateAccessToken(user, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			user.PasswordHash = ""
+			return &AuthTokens{
+				AccessToken: accessToken,
+				User:        user,
+			}, nil
+		}
+	}
+
+	// The old token was copied; whoever holds the session now cannot be trusted
+	if stored.SessionID != nil {
+		uc.sessionStates.set(*stored.SessionID, false)
+		if err := uc.sessionRepo.Revoke(ctx, *stored.SessionID, entities.SessionRevokedReuse); err != nil {
+			log.Printf("[Auth] Failed to revoke session %s after token reuse: %v", *stored.SessionID, err)
+		}
+		log.Printf("[Auth] Refresh token reuse detected for user %s, session %s revoked", stored.UserID, *stored.SessionID)
+	} else {
+		if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, stored.UserID); err != nil {
+			log.Printf("[Auth] Failed to revoke sessions for user %s after token reuse: %v", stored.UserID, err)
+		}
+		log.Printf("[Auth] Refresh token reuse detected for user %s, all sessions revoked", stored.UserID)
+	}
+	return nil, errors.New("refresh token has already been used, please log in again")
+}
+
+// getSessionUser loads the user behind a refresh token and checks they may still sign in
+func (uc *AuthUsecase) getSessionUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is inactive")
+	}
+
+	// Sessions from before the admin MFA policy was enabled must log in again to enroll
+	if uc.mfaRequired(user) && !user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is required, please log in again")
+	}
+	return user, nil
+}
+
+// ListSessions returns the user's signed-in devices, marking the one making the request
+func (uc *AuthUsecase) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID *uuid.UUID) ([]*entities.Session, error) {
+	sessions, err := uc.sessionRepo.ListActiveForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for _, session := range sessions {
+		session.Current = currentSessionID != nil && session.ID == *currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs out one of the user's devices
+func (uc *AuthUsecase) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.New("session not found")
+	}
+
+	if err := uc.sessionRepo.Revoke(ctx, sessionID, entities.SessionRevokedByUser); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	uc.sessionStates.set(sessionID, false)
+
+	log.Printf("[Auth] Session %s signed out by user %s", sessionID, userID)
+	return nil
+}
+
+// SessionActive reports whether the session an access token was issued for is still signed in
+// Lookups are cached for sessionCheckTTL; revocations on this instance take effect immediately
+func (uc *AuthUsecase) SessionActive(ctx context.Context, sessionID uuid.UUID) bool {
+	if active, ok := uc.sessionStates.get(sessionID); ok {
+		return active
+	}
+
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return false
+	}
+
+	active := session.RevokedAt == nil
+	uc.sessionStates.set(sessionID, active)
+	return active
+}
+
+// describeDevice turns a user agent into a short label such as "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp") || strings.Contains(ua, "dart") || strings.Contains(ua, "cfnetwork"):
+		browser = "Mobile app"
+	case strings.Contains(ua, "curl") || strings.Contains(ua, "postman"):
+		browser = "API client"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ios"):
+		platform = "iOS"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os") || strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
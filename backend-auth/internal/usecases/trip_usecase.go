@@ -19,6 +19,7 @@ type TripUsecase struct {
 	busRepo      repositories.BusRepository
 	routeRepo    repositories.RouteRepository
 	cacheService *services.CacheService
+	funnel       *FunnelTracker
 }
 
 // NewTripUsecase creates a new trip usecase
@@ -27,12 +28,14 @@ func NewTripUsecase(
 	busRepo repositories.BusRepository,
 	routeRepo repositories.RouteRepository,
 	cacheService *services.CacheService,
+	funnel *FunnelTracker,
 ) *TripUsecase {
 	return &TripUsecase{
 		tripRepo:     tripRepo,
 		busRepo:      busRepo,
 		routeRepo:    routeRepo,
 		cacheService: cacheService,
+		funnel:       funnel,
 	}
 }
 
@@ -75,6 +78,16 @@ func (u *TripUsecase) GetTripByID(ctx context.Context, tripID uuid.UUID) (*entit
 	return u.tripRepo.GetByID(ctx, tripID)
 }
 
+// ViewTrip returns a trip for the public details page and records the view in the booking funnel
+func (u *TripUsecase) ViewTrip(ctx context.Context, tripID uuid.UUID) (*entities.Trip, error) {
+	trip, err := u.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	u.funnel.TrackTripView(ctx, trip)
+	return trip, nil
+}
+
 // GetAllBuses returns all buses
 func (u *TripUsecase) GetAllBuses(ctx context.Context) ([]*entities.Bus, error) {
 	return u.busRepo.GetAll(ctx)
@@ -90,6 +103,7 @@ func (u *TripUsecase) SearchTrips(ctx context.Context, opts repositories.TripSea
 		var cachedResult repositories.PaginatedTrips
 		if err := u.cacheService.Get(ctx, cacheKey, &cachedResult); err == nil {
 			// Cache hit
+			u.funnel.TrackSearch(ctx, opts, cachedResult.Total)
 			return &cachedResult, nil
 		}
 	}
@@ -105,6 +119,7 @@ func (u *TripUsecase) SearchTrips(ctx context.Context, opts repositories.TripSea
 		_ = u.cacheService.Set(ctx, cacheKey, result, "trips")
 	}
 
+	u.funnel.TrackSearch(ctx, opts, result.Total)
 	return result, nil
 }
 
@@ -124,18 +139,24 @@ func (u *TripUsecase) GetAllRoutes(ctx context.Context) ([]*entities.Route, erro
 // CreateTrip creates a new trip
 func (u *TripUsecase) CreateTrip(ctx context.Context, trip *entities.Trip) error {
 	// Validate route exists
-	_, err := u.routeRepo.GetByID(ctx, trip.RouteID)
+	route, err := u.routeRepo.GetByID(ctx, trip.RouteID)
 	if err != nil {
 		return fmt.Errorf("route not found: %w", err)
 	}
 
+	// The trip is run by the operator that owns the route
+	trip.OperatorID = route.OperatorID
+
 	// If bus is assigned, validate it's available
 	if trip.BusID != nil {
 		// Check bus exists
-		_, err := u.busRepo.GetByID(ctx, *trip.BusID)
+		bus, err := u.busRepo.GetByID(ctx, *trip.BusID)
 		if err != nil {
 			return fmt.Errorf("bus not found: %w", err)
 		}
+		if !entities.SameOperator(bus.OperatorID, trip.OperatorID) {
+			return fmt.Errorf("bus belongs to a different operator than the route")
+		}
 
 		// Check for conflicts
 		conflictingTrips, err := u.tripRepo.GetByBusID(ctx, *trip.BusID, trip.StartTime, trip.EndTime)
@@ -203,19 +224,25 @@ func (u *TripUsecase) UpdateTrip(ctx context.Context, tripID uuid.UUID, updates
 	// Merge updates with existing data (only update non-zero values)
 	if updates.RouteID != uuid.Nil {
 		// If route is being changed, validate it exists
-		_, err := u.routeRepo.GetByID(ctx, updates.RouteID)
+		route, err := u.routeRepo.GetByID(ctx, updates.RouteID)
 		if err != nil {
 			return fmt.Errorf("route not found: %w", err)
 		}
+		if !entities.SameOperator(route.OperatorID, existing.OperatorID) {
+			return fmt.Errorf("route belongs to a different operator than the trip")
+		}
 		existing.RouteID = updates.RouteID
 	}
 
 	if updates.BusID != nil {
 		// If bus is being changed, validate and check conflicts
-		_, err := u.busRepo.GetByID(ctx, *updates.BusID)
+		bus, err := u.busRepo.GetByID(ctx, *updates.BusID)
 		if err != nil {
 			return fmt.Errorf("bus not found: %w", err)
 		}
+		if !entities.SameOperator(bus.OperatorID, existing.OperatorID) {
+			return fmt.Errorf("bus belongs to a different operator than the trip")
+		}
 
 		// Use updated times if provided, otherwise use existing
 		startTime := existing.StartTime
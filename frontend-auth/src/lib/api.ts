@@ -34,6 +34,16 @@ const api = axios.create({
   withCredentials: true, // Enable credentials to send cookies with requests
 });
 
+// Per-tab visitor session used by the backend to track the search-to-booking funnel
+const getFunnelSessionId = (): string => {
+  let sessionId = sessionStorage.getItem('funnelSessionId');
+  if (!sessionId) {
+    sessionId = `fs-${Date.now()}-${Math.random().toString(36).slice(2, 10)}`;
+    sessionStorage.setItem('funnelSessionId', sessionId);
+  }
+  return sessionId;
+};
+
 // Request interceptor to add auth token
 api.interceptors.request.use(
   config => {
@@ -41,6 +51,7 @@ api.interceptors.request.use(
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    config.headers['X-Session-ID'] = getFunnelSessionId();
     return config;
   },
   error => {
@@ -336,17 +347,25 @@ export const bookingAPI = {
   cancelBooking: (bookingId: string, reason?: string) =>
     api.post(`/bookings/${bookingId}/cancel`, { reason }),
 
-  // Get booking by reference
-  getByReference: (reference: string) =>
-    api.get(`/bookings/ref/${reference}`),
-
   // Get user's booking history (authenticated)
   getMyBookings: (page = 1, pageSize = 10) =>
     api.get('/bookings/my-bookings', { params: { page, page_size: pageSize } }),
 
-  // Get guest bookings
-  getGuestBookings: (email?: string, phone?: string) =>
-    api.get('/bookings/guest', { params: { email, phone } }),
+  // Email a one-time code to a guest booking's contact email
+  requestGuestAccess: (reference: string, email: string) =>
+    api.post('/bookings/guest/lookup', { reference, email }),
+
+  // Exchange the one-time code for a booking access token
+  verifyGuestAccess: (reference: string, code: string) =>
+    api.post('/bookings/guest/verify', { reference, code }),
+
+  // Get the booking a guest access token was issued for
+  getGuestBooking: (accessToken: string) =>
+    api.get('/bookings/guest/booking', { headers: { 'X-Booking-Token': accessToken } }),
+
+  // Cancel the booking a guest access token was issued for
+  cancelGuestBooking: (accessToken: string, reason?: string) =>
+    api.post('/bookings/guest/booking/cancel', { reason }, { headers: { 'X-Booking-Token': accessToken } }),
 
   // Get available seats for a trip (only unbooked seats)
   getAvailableSeats: (tripId: string) =>
@@ -356,23 +375,45 @@ export const bookingAPI = {
   getSeatsWithStatus: (tripId: string) =>
     api.get(`/trips/${tripId}/seats/status`),
 
-  // Download ticket PDF
-  downloadTicket: (ticketId: string) => {
-    const url = `${API_URL}/tickets/${ticketId}/download`;
-    window.open(url, '_blank');
-  },
+  // Download ticket PDF (authenticated, own bookings only)
+  downloadTicket: (ticketId: string) =>
+    openPdf(api.get(`/tickets/${ticketId}/download`, { responseType: 'blob' })),
 
-  // Download all tickets for a booking
-  downloadBookingTickets: (bookingId: string) => {
-    const url = `${API_URL}/bookings/${bookingId}/tickets/download`;
-    window.open(url, '_blank');
-  },
+  // Download all tickets for a booking (authenticated, own bookings only)
+  downloadBookingTickets: (bookingId: string) =>
+    openPdf(api.get(`/bookings/${bookingId}/tickets/download`, { responseType: 'blob' })),
 
   // Resend ticket emails
   resendTickets: (bookingId: string) =>
     api.post(`/bookings/${bookingId}/resend-tickets`),
+
+  // Download all tickets of the booking a guest access token was issued for
+  downloadGuestTickets: (accessToken: string) =>
+    openPdf(api.get('/bookings/guest/booking/tickets/download', {
+      responseType: 'blob',
+      headers: { 'X-Booking-Token': accessToken },
+    })),
+
+  // Download one ticket of the booking a guest access token was issued for
+  downloadGuestTicket: (accessToken: string, ticketId: string) =>
+    openPdf(api.get(`/bookings/guest/booking/tickets/${ticketId}/download`, {
+      responseType: 'blob',
+      headers: { 'X-Booking-Token': accessToken },
+    })),
+
+  // Resend tickets of the booking a guest access token was issued for
+  resendGuestTickets: (accessToken: string) =>
+    api.post('/bookings/guest/booking/resend-tickets', null, { headers: { 'X-Booking-Token': accessToken } }),
 };
 
+// Ticket downloads need the auth or booking token header, so they are fetched and opened as a blob
+async function openPdf(request: Promise<{ data: Blob }>) {
+  const response = await request;
+  const url = URL.createObjectURL(response.data);
+  window.open(url, '_blank');
+  setTimeout(() => URL.revokeObjectURL(url), 60_000);
+}
+
 // Payment API
 export const paymentAPI = {
   // Create a payment for a booking
@@ -431,6 +472,31 @@ export const analyticsAPI = {
         previous_end: previousEnd
       }
     }),
+
+  // Search-to-booking funnel per route
+  getRouteFunnels: (startDate: string, endDate: string) =>
+    api.get('/admin/analytics/funnel', { params: { start_date: startDate, end_date: endDate } }),
+
+  // Searches that returned no trips
+  getUnmetDemand: (startDate: string, endDate: string, limit?: number) =>
+    api.get('/admin/analytics/unmet-demand', { params: { start_date: startDate, end_date: endDate, limit } }),
+
+  // Recompute stored analytics for a past date range
+  startBackfill: (startDate: string, endDate: string) =>
+    api.post('/admin/analytics/backfills', { start_date: startDate, end_date: endDate }),
+
+  getBackfills: (page = 1, pageSize = 20) =>
+    api.get('/admin/analytics/backfills', { params: { page, page_size: pageSize } }),
+
+  getBackfill: (id: string) =>
+    api.get(`/admin/analytics/backfills/${id}`),
+
+  cancelBackfill: (id: string) =>
+    api.post(`/admin/analytics/backfills/${id}/cancel`),
+
+  // Days whose aggregates were never computed
+  getMissingAnalyticsDates: (startDate?: string, endDate?: string) =>
+    api.get('/admin/analytics/missing-dates', { params: { start_date: startDate, end_date: endDate } }),
 };
 
 // Notification API
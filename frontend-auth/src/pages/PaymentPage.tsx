@@ -72,12 +72,12 @@ export default function PaymentPage() {
             }
 
             try {
-                // Get booking reference from URL
-                const reference = searchParams.get('ref') || localStorage.getItem('lastBookingRef');
+                // Booking access token issued when the booking was created
+                const accessToken = localStorage.getItem('lastBookingToken');
                 
-                if (reference) {
-                    // Fetch booking by reference
-                    const res = await bookingAPI.getByReference(reference);
+                if (accessToken) {
+                    // Fetch the booking the token was issued for
+                    const res = await bookingAPI.getGuestBooking(accessToken);
                     console.log('Full API response:', res.data);
                     
                     // API returns {booking: {...}, passengers: [...], tickets: [...]}
@@ -88,7 +88,7 @@ export default function PaymentPage() {
                     console.log('Total amount:', bookingData?.total_amount);
                     setBooking(bookingData);
                 } else {
-                    setError('No booking reference found');
+                    setError('Booking access has expired, please look up your booking again');
                 }
 
                 // Try to get existing payments for this booking
@@ -33,16 +33,11 @@ export default function PaymentSuccessPage() {
     useEffect(() => {
         const fetchBooking = async () => {
             try {
-                if (bookingRef) {
-                    const res = await bookingAPI.getByReference(bookingRef);
-                    setBooking(res.data?.booking);
-                } else if (bookingId) {
-                    // Try to get from localStorage cache
-                    const cached = localStorage.getItem('lastBookingRef');
-                    if (cached) {
-                        const res = await bookingAPI.getByReference(cached);
-                        setBooking(res.data?.booking);
-                    }
+                // Booking access token issued when the booking was created
+                const accessToken = localStorage.getItem('lastBookingToken');
+                if ((bookingRef || bookingId) && accessToken) {
+                    const res = await bookingAPI.getGuestBooking(accessToken);
+                    setBooking(res.data?.data?.booking);
                 }
             } catch (err) {
                 console.error('Failed to fetch booking:', err);
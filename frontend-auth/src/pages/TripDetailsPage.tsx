@@ -188,8 +188,11 @@ export default function TripDetailsPage() {
       const createdBookingId = bookingData.booking.id;
       const reference = bookingData.booking.booking_reference;
       
-      // Store booking reference in localStorage for payment page
+      // Store booking reference and access token in localStorage for payment page
       localStorage.setItem('lastBookingRef', reference);
+      if (bookingData.access?.token) {
+        localStorage.setItem('lastBookingToken', bookingData.access.token);
+      }
       
       // Redirect to payment page instead of showing confirmation
       navigate(`/payment/${createdBookingId}?ref=${reference}`);